@@ -0,0 +1,121 @@
+// Command flow-replay replays a traffic recording (Extension::startRecording, see
+// internal/recorder) against a fresh handler and diffs the outcome with what was
+// recorded. The features are answered from the recording itself, so the replay
+// needs no database or network and reproduces the scheduling of the recorded
+// session deterministically (see internal/replay). Exits 1 when the replay diverges.
+//
+//	go run ./cmd/flow-replay -file /tmp/worker.rec
+//	go run ./cmd/flow-replay -file /tmp/worker.rec -dump
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/recorder"
+	"sconcur/internal/replay"
+)
+
+func main() {
+	path := flag.String("file", "", "recording to replay")
+	waitMs := flag.Int("wait", int(replay.DefaultResultWait.Milliseconds()), "how long to wait for each recorded result, in ms")
+	dump := flag.Bool("dump", false, "print the recorded entries instead of replaying them")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	entries, err := recorder.ReadFile(*path)
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		fmt.Printf("warning: the recording ends with a truncated entry, replaying %d entries\n", len(entries))
+	} else if err != nil {
+		fmt.Println("read recording:", err)
+		os.Exit(2)
+	}
+
+	if *dump {
+		for index, entry := range entries {
+			printEntry(index, entry)
+		}
+
+		return
+	}
+
+	report := replay.Run(entries, time.Duration(*waitMs)*time.Millisecond)
+
+	fmt.Printf(
+		"entries: %d, pushes: %d, results: %d, recorded in %s, replayed in %s\n",
+		report.Entries,
+		report.Pushes,
+		report.Results,
+		report.Recorded.Round(time.Millisecond),
+		report.Replayed.Round(time.Millisecond),
+	)
+
+	for _, mismatch := range report.Mismatches {
+		fmt.Printf("#%d: %s\n", mismatch.Index, mismatch.Reason)
+
+		if mismatch.Expected != nil {
+			fmt.Println("  recorded:", describe(mismatch.Expected))
+		}
+
+		if mismatch.Got != nil {
+			fmt.Println("  replayed:", describe(mismatch.Got))
+		}
+	}
+
+	for _, result := range report.Unexpected {
+		fmt.Println("not in the recording:", describe(result))
+	}
+
+	if !report.Ok() {
+		fmt.Printf("diverged: %d mismatches, %d unexpected results\n", len(report.Mismatches), len(report.Unexpected))
+		os.Exit(1)
+	}
+
+	fmt.Println("replay matches the recording")
+}
+
+func printEntry(index int, entry *recorder.Entry) {
+	at := time.Duration(entry.AtNs).Round(time.Microsecond)
+
+	switch entry.Kind {
+	case recorder.KindPush:
+		line := fmt.Sprintf("#%d %s push %s/%s method=%s next=%t payload=%dB", index, at, entry.FlowKey, entry.TaskKey, entry.Method, entry.IsNext, len(entry.Payload))
+
+		if entry.TimeoutMs > 0 {
+			line += fmt.Sprintf(" timeout=%dms", entry.TimeoutMs)
+		}
+
+		if entry.Error != "" {
+			line += " rejected: " + entry.Error
+		}
+
+		fmt.Println(line)
+	case recorder.KindResult:
+		fmt.Printf("#%d %s result %s\n", index, at, describe(entry.DtoResult()))
+	default:
+		fmt.Printf("#%d %s %s %s/%s\n", index, at, entry.Kind, entry.FlowKey, entry.TaskKey)
+	}
+}
+
+func describe(result *dto.Result) string {
+	return fmt.Sprintf(
+		"%s/%s method=%s error=%t cancelled=%t next=%t payload=%dB",
+		result.FlowKey,
+		result.TaskKey,
+		result.Method,
+		result.IsError,
+		result.IsCancelled,
+		result.HasNext,
+		len(result.Payload),
+	)
+}
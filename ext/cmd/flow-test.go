@@ -132,7 +132,7 @@ func testStopFlow() {
 
 	// Stop flow after short delay
 	time.Sleep(100 * time.Millisecond)
-	handler.StopFlow(flowKey)
+	handler.StopFlow(flowKey, nil)
 	fmt.Printf("Stopped flow: %s\n", flowKey)
 
 	// Try to wait (should fail)
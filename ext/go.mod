@@ -7,6 +7,7 @@ require (
 	github.com/go-sql-driver/mysql v1.8.1
 	github.com/jackc/pgx/v5 v5.7.2
 	go.mongodb.org/mongo-driver/v2 v2.6.0
+	golang.org/x/net v0.35.0
 )
 
 require (
@@ -18,13 +19,13 @@ require (
 )
 
 require (
-	github.com/klauspost/compress v1.17.6 // indirect
+	github.com/klauspost/compress v1.17.6
 	github.com/vmihailenco/msgpack/v5 v5.4.1
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.2.0 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
-	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sync v0.11.0
 	golang.org/x/text v0.22.0 // indirect
 )
@@ -0,0 +1,170 @@
+// Package arena hands out spans of one long-lived buffer as a ring: the result
+// frames going to PHP are written into it in place instead of each getting its
+// own C allocation (C.CBytes) that PHP then frees. The buffer is C memory,
+// allocated once per runtime (package framemem); this package only does the
+// accounting, so it works on any []byte.
+//
+// Every span is preceded in the buffer by a record header: the record's size and
+// a release word. The consumer releases a span by storing 1 into its word (the C
+// side does it with an atomic store once the frame is copied out) and never calls
+// back into the arena. Reserve takes the released records back from the tail
+// before placing a new one, so acknowledgements are collected in batches and may
+// come out of order: space is reclaimed up to the oldest span still held.
+package arena
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	// HeaderSize is the record header in front of every span:
+	//
+	//	[0:4]  size     uint32, the whole record, header and padding included
+	//	[4:8]  release  uint32, set to 1 by the consumer once the span is copied out
+	HeaderSize = 8
+
+	// align keeps every header (and so every release word) 8-byte aligned; the
+	// buffer itself must start aligned (C.malloc does).
+	align = 8
+)
+
+type Arena struct {
+	mutex sync.Mutex
+	buf   []byte
+	// head is where the next record starts, tail the oldest one not reclaimed.
+	head int
+	tail int
+	// used counts the bytes of the records between tail and head, the skipped
+	// end of the buffer included.
+	used int
+}
+
+// New returns an arena over buf; its length is rounded down to the alignment.
+func New(buf []byte) *Arena {
+	return &Arena{buf: buf[:len(buf)/align*align]}
+}
+
+// Bytes returns the whole buffer; a reserved span is Bytes()[offset:offset+n].
+func (a *Arena) Bytes() []byte {
+	return a.buf
+}
+
+// Reserve takes n contiguous bytes and returns their offset, or false when they
+// do not fit in the free space right now (the caller falls back to its own
+// allocation).
+func (a *Arena) Reserve(n int) (int, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if n <= 0 {
+		return 0, false
+	}
+
+	size := HeaderSize + (n+align-1)/align*align
+
+	if size > len(a.buf) {
+		return 0, false
+	}
+
+	a.reclaim()
+
+	if !a.makeRoom(size) {
+		return 0, false
+	}
+
+	a.writeHeader(a.head, size)
+
+	offset := a.head + HeaderSize
+
+	a.head += size
+	a.used += size
+
+	return offset, true
+}
+
+// makeRoom moves the head where size bytes fit: after it, else — skipping the
+// end of the buffer — at the start, never reaching into the tail record.
+func (a *Arena) makeRoom(size int) bool {
+	if a.used == 0 {
+		a.head, a.tail = 0, 0
+
+		return true
+	}
+
+	if a.head > a.tail {
+		if len(a.buf)-a.head >= size {
+			return true
+		}
+
+		if a.tail < size {
+			return false
+		}
+
+		// The skipped end becomes a record released from the start, so the tail
+		// steps over it like over any other.
+		if gap := len(a.buf) - a.head; gap > 0 {
+			a.writeHeader(a.head, gap)
+			atomic.StoreUint32(a.releaseWord(a.head), 1)
+
+			a.used += gap
+		}
+
+		a.head = 0
+
+		return true
+	}
+
+	return a.tail-a.head >= size
+}
+
+// reclaim drops the released records at the tail.
+func (a *Arena) reclaim() {
+	for a.used > 0 {
+		if a.tail == len(a.buf) {
+			a.tail = 0
+		}
+
+		if atomic.LoadUint32(a.releaseWord(a.tail)) == 0 {
+			return
+		}
+
+		size := int(binary.LittleEndian.Uint32(a.buf[a.tail:]))
+
+		a.tail += size
+		a.used -= size
+	}
+}
+
+func (a *Arena) writeHeader(at int, size int) {
+	binary.LittleEndian.PutUint32(a.buf[at:], uint32(size))
+	atomic.StoreUint32(a.releaseWord(at), 0)
+}
+
+func (a *Arena) releaseWord(at int) *uint32 {
+	return (*uint32)(unsafe.Pointer(&a.buf[at+4]))
+}
+
+// ReleaseWord returns the word the consumer of the span reserved at offset sets
+// to 1 to release it.
+func (a *Arena) ReleaseWord(offset int) *uint32 {
+	return a.releaseWord(offset - HeaderSize)
+}
+
+// Release releases the span reserved at offset from Go, the way the C side does.
+func (a *Arena) Release(offset int) {
+	atomic.StoreUint32(a.ReleaseWord(offset), 1)
+}
+
+// Used returns the bytes held by the spans not released yet, headers and the
+// skipped end of the buffer included.
+func (a *Arena) Used() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.reclaim()
+
+	return a.used
+}
@@ -0,0 +1,141 @@
+package arena
+
+import (
+	"testing"
+)
+
+func reserve(t *testing.T, a *Arena, n int) int {
+	t.Helper()
+
+	offset, ok := a.Reserve(n)
+
+	if !ok {
+		t.Fatalf("reserve %d bytes: no room (used %d)", n, a.Used())
+	}
+
+	return offset
+}
+
+func TestReserveWrapsOnceTheTailIsReleased(t *testing.T) {
+	a := New(make([]byte, 104))
+
+	first := reserve(t, a, 40)
+	second := reserve(t, a, 40)
+
+	if first != HeaderSize || second != 2*HeaderSize+40 {
+		t.Fatalf("spans at %d and %d, want %d and %d", first, second, HeaderSize, 2*HeaderSize+40)
+	}
+
+	if _, ok := a.Reserve(8); ok {
+		t.Fatal("8 bytes must not fit while both spans are held")
+	}
+
+	a.Release(first)
+
+	wrapped := reserve(t, a, 32)
+
+	if wrapped != HeaderSize {
+		t.Fatalf("expected the span to wrap to the start, got %d", wrapped)
+	}
+
+	// The 8 bytes skipped at the end stay held until the tail steps over them.
+	if used := a.Used(); used != 96 {
+		t.Fatalf("used %d, want 96", used)
+	}
+
+	a.Release(second)
+
+	if used := a.Used(); used != 40 {
+		t.Fatalf("used %d, want 40", used)
+	}
+}
+
+func TestOutOfOrderReleaseReclaimsFromTheTail(t *testing.T) {
+	a := New(make([]byte, 96))
+
+	first := reserve(t, a, 24)
+	second := reserve(t, a, 24)
+	third := reserve(t, a, 24)
+
+	a.Release(second)
+
+	if used := a.Used(); used != 96 {
+		t.Fatalf("a released span behind the tail must stay held, used %d", used)
+	}
+
+	a.Release(first)
+
+	if used := a.Used(); used != 32 {
+		t.Fatalf("used %d, want 32", used)
+	}
+
+	a.Release(third)
+
+	if offset := reserve(t, a, 88); offset != HeaderSize {
+		t.Fatalf("an empty arena starts over at the start, got %d", offset)
+	}
+}
+
+func TestReleaseWordIsInTheBuffer(t *testing.T) {
+	a := New(make([]byte, 64))
+
+	offset := reserve(t, a, 5)
+
+	// A consumer outside Go stores into the word directly.
+	*a.ReleaseWord(offset) = 1
+
+	if used := a.Used(); used != 0 {
+		t.Fatalf("used %d after the word was set", used)
+	}
+
+	if next := reserve(t, a, 5); next != offset {
+		t.Fatalf("the released record must be reused, got %d", next)
+	}
+}
+
+func TestReserveRejectsOversizedSpans(t *testing.T) {
+	a := New(make([]byte, 24))
+
+	if _, ok := a.Reserve(17); ok {
+		t.Fatal("a span larger than the arena must not fit")
+	}
+
+	if _, ok := a.Reserve(0); ok {
+		t.Fatal("an empty span must not be reserved")
+	}
+
+	reserve(t, a, 16)
+}
+
+// BenchmarkReserveRelease is the per-frame cost of the arena, to compare with
+// BenchmarkAllocate, the allocation it replaces.
+func BenchmarkReserveRelease(b *testing.B) {
+	a := New(make([]byte, 1<<20))
+	frame := make([]byte, 4096)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		offset, ok := a.Reserve(len(frame))
+
+		if !ok {
+			b.Fatal("no room")
+		}
+
+		copy(a.Bytes()[offset:], frame)
+
+		a.Release(offset)
+	}
+}
+
+func BenchmarkAllocate(b *testing.B) {
+	frame := make([]byte, 4096)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		copied := make([]byte, len(frame))
+
+		copy(copied, frame)
+	}
+}
@@ -0,0 +1,101 @@
+// Package compression zstd-compresses the large payloads crossing the cgo
+// boundary: a MongoDB or SQL batch, an HTTP body can be megabytes, and each one
+// is copied across (into the result arena on the way to PHP, C.GoStringN on the
+// way back). Past a threshold, a fast zstd pass and a decompress on the other
+// side are cheaper than copying the bytes as they are.
+//
+// It is disabled by default: the PHP side needs the zstd extension to read a
+// compressed payload, so it enables compression itself (setCompression) only
+// when that extension is loaded. The threshold is kept per runtime (see package
+// runtimes), as every PHP thread has its own Extension with its own setting; the
+// coders are shared.
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxDecodedSize bounds one decompressed payload, so a corrupted or hostile frame
+// does not make the decoder allocate without limit.
+const maxDecodedSize = 1 << 30
+
+// thresholds holds the threshold of every runtime that enabled compression, by
+// runtime id; a runtime missing from it does not compress.
+var thresholds sync.Map
+
+var coders = sync.OnceValues(func() (*zstd.Encoder, *zstd.Decoder) {
+	// Both are safe for concurrent EncodeAll/DecodeAll calls, and never closed:
+	// they live as long as the process.
+	encoder, encoderErr := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	decoder, decoderErr := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedSize))
+
+	if err := errors.Join(encoderErr, decoderErr); err != nil {
+		// Only invalid options fail here, and the options are constants.
+		panic(fmt.Sprintf("compression: %v", err))
+	}
+
+	return encoder, decoder
+})
+
+// SetThreshold compresses the result payloads of a runtime of at least bytes
+// bytes from now on; 0 (or less) disables compression for it.
+func SetThreshold(runtime int, bytes int) {
+	if bytes <= 0 {
+		thresholds.Delete(runtime)
+
+		return
+	}
+
+	thresholds.Store(runtime, bytes)
+}
+
+func Threshold(runtime int) int {
+	if found, ok := thresholds.Load(runtime); ok {
+		return found.(int)
+	}
+
+	return 0
+}
+
+// Drop forgets the threshold of a destroyed runtime.
+func Drop(runtime int) {
+	thresholds.Delete(runtime)
+}
+
+// Compress returns the zstd frame of payload and true, or payload itself and
+// false when compression is disabled for the runtime, the payload is below its
+// threshold, or it did not shrink (already compressed data, e.g. an image body).
+func Compress(runtime int, payload []byte) ([]byte, bool) {
+	limit := Threshold(runtime)
+
+	if limit <= 0 || len(payload) < limit {
+		return payload, false
+	}
+
+	encoder, _ := coders()
+
+	compressed := encoder.EncodeAll(payload, make([]byte, 0, len(payload)/2))
+
+	if len(compressed) >= len(payload) {
+		return payload, false
+	}
+
+	return compressed, true
+}
+
+// Decompress decodes a payload compressed by the PHP side.
+func Decompress(payload []byte) ([]byte, error) {
+	_, decoder := coders()
+
+	decoded, err := decoder.DecodeAll(payload, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("decompress payload: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,88 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestCompressRespectsTheThreshold(t *testing.T) {
+	t.Cleanup(func() { SetThreshold(0, 0) })
+
+	payload := bytes.Repeat([]byte("sconcur "), 1024)
+
+	if _, compressed := Compress(0, payload); compressed {
+		t.Fatal("compression must be disabled by default")
+	}
+
+	SetThreshold(0, len(payload)+1)
+
+	if _, compressed := Compress(0, payload); compressed {
+		t.Fatal("a payload below the threshold must pass as is")
+	}
+
+	SetThreshold(0, len(payload))
+
+	encoded, compressed := Compress(0, payload)
+
+	if !compressed || len(encoded) >= len(payload) {
+		t.Fatalf("expected a smaller compressed payload, got %d bytes (compressed %t)", len(encoded), compressed)
+	}
+
+	decoded, err := Decompress(encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decoded, payload) {
+		t.Fatal("the payload did not survive the round trip")
+	}
+}
+
+func TestCompressKeepsIncompressiblePayloads(t *testing.T) {
+	t.Cleanup(func() { SetThreshold(0, 0) })
+
+	SetThreshold(0, 1)
+
+	payload := make([]byte, 4096)
+
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded, compressed := Compress(0, payload); compressed || !bytes.Equal(encoded, payload) {
+		t.Fatal("a payload that does not shrink must pass as is")
+	}
+}
+
+func TestThresholdIsPerRuntime(t *testing.T) {
+	t.Cleanup(func() {
+		SetThreshold(0, 0)
+		Drop(1)
+	})
+
+	payload := bytes.Repeat([]byte("sconcur "), 1024)
+
+	SetThreshold(1, len(payload))
+
+	if _, compressed := Compress(0, payload); compressed {
+		t.Fatal("another runtime's threshold must not compress the default runtime's payloads")
+	}
+
+	if _, compressed := Compress(1, payload); !compressed {
+		t.Fatal("expected the runtime's payload to be compressed")
+	}
+
+	Drop(1)
+
+	if threshold := Threshold(1); threshold != 0 {
+		t.Fatalf("a dropped runtime keeps threshold %d", threshold)
+	}
+}
+
+func TestDecompressRejectsGarbage(t *testing.T) {
+	if _, err := Decompress([]byte("not zstd")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
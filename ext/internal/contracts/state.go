@@ -8,3 +8,12 @@ type StateContract interface {
 	Next() *dto.Result
 	Close()
 }
+
+// HeldStateContract marks a state that stands for a resource PHP holds on purpose
+// (an open transaction, an acquired permit) rather than a stream it may abandon.
+// Nothing calls its Next until PHP lets go, so the idle reaper leaves it alone:
+// only PHP or the stop of its flow closes it.
+type HeldStateContract interface {
+	StateContract
+	Held()
+}
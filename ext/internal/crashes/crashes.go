@@ -0,0 +1,184 @@
+// Package crashes keeps the panics recovered on the Go side: in a feature
+// goroutine (flows.runTaskProtected) or in a server goroutine (an HTTP or
+// WebSocket ServeHTTP, a socket connection). An unrecovered panic in a c-shared
+// library aborts the whole PHP process, so every such goroutine recovers; this
+// package makes those recoveries visible instead of leaving them as a stack
+// string in one error result.
+//
+// The log is bounded: it keeps the last Capacity reports, while the counters
+// cover the whole process lifetime. It is read by the inspect export and counted
+// in the statistics snapshot (stats.Snapshot.Crashes), so a supervisor can alert
+// on a worker that keeps panicking.
+package crashes
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sconcur/internal/dto"
+	"sconcur/internal/types"
+	"sync"
+	"time"
+)
+
+// Capacity is the number of reports the log keeps; older ones are dropped.
+const Capacity = 64
+
+const (
+	// previewSize bounds the payload preview of a report.
+	previewSize = 256
+	// stackSize bounds the stack of a report: the frames that matter are on top.
+	stackSize = 8 << 10
+)
+
+type Kind string
+
+const (
+	// KindTask is a panic in a feature goroutine, answered with an error result.
+	KindTask Kind = "task"
+	// KindServer is a panic in a server goroutine serving one request or
+	// connection, which is aborted.
+	KindServer Kind = "server"
+)
+
+// Report is one recovered panic. For a server panic FlowKey and Method are those
+// of the serve task, and TaskKey is the request or connection id.
+type Report struct {
+	AtMs           int64        `json:"atMs"`
+	Kind           Kind         `json:"kind"`
+	Method         types.Method `json:"method"`
+	Command        string       `json:"command,omitempty"`
+	FlowKey        string       `json:"flowKey"`
+	TaskKey        string       `json:"taskKey"`
+	PayloadPreview string       `json:"payloadPreview,omitempty"`
+	Message        string       `json:"message"`
+	Stack          string       `json:"stack"`
+}
+
+// NewReport describes a panic recovered while serving msg: taskKey is the key
+// of the task, or the request or connection id of a server panic. The stack is
+// taken here, so call it from the deferred function that recovered.
+func NewReport(kind Kind, msg *dto.Message, taskKey string, recovered any) Report {
+	report := Report{
+		AtMs:           time.Now().UnixMilli(),
+		Kind:           kind,
+		Method:         msg.Method,
+		FlowKey:        msg.FlowKey,
+		TaskKey:        taskKey,
+		PayloadPreview: preview(msg.Payload),
+		Message:        fmt.Sprintf("panic: %v", recovered),
+		Stack:          string(debug.Stack()),
+	}
+
+	if !msg.IsNext {
+		report.Command = dto.PayloadCommand(msg.Payload)
+	}
+
+	if len(report.Stack) > stackSize {
+		report.Stack = report.Stack[:stackSize] + "\n... (truncated)"
+	}
+
+	return report
+}
+
+// preview renders the head of a payload readable: MessagePack strings stay
+// legible, other bytes become dots.
+func preview(payload []byte) string {
+	size := min(len(payload), previewSize)
+	text := make([]byte, size)
+
+	for i, b := range payload[:size] {
+		if b >= 0x20 && b < 0x7f {
+			text[i] = b
+		} else {
+			text[i] = '.'
+		}
+	}
+
+	if len(payload) > size {
+		return fmt.Sprintf("%s... (%d bytes)", text, len(payload))
+	}
+
+	return string(text)
+}
+
+// Counts are the lifetime counters of the log.
+type Counts struct {
+	Total  int64 `json:"total"`
+	Task   int64 `json:"task"`
+	Server int64 `json:"server"`
+	// LastAtMs is the time of the latest panic, 0 when none happened.
+	LastAtMs int64 `json:"lastAtMs"`
+}
+
+// Log is a bounded ring of reports plus lifetime counters. Safe for concurrent
+// use.
+type Log struct {
+	mutex   sync.Mutex
+	reports []Report
+	next    int
+	counts  Counts
+}
+
+func NewLog(capacity int) *Log {
+	return &Log{reports: make([]Report, 0, max(capacity, 1))}
+}
+
+var once sync.Once
+var instance *Log
+
+// Get returns the process-wide log: panics are counted per process, whatever the
+// runtime that ran the task.
+func Get() *Log {
+	once.Do(func() {
+		instance = NewLog(Capacity)
+	})
+
+	return instance
+}
+
+// Record adds a report, dropping the oldest one when the log is full.
+func (l *Log) Record(report Report) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.reports) < cap(l.reports) {
+		l.reports = append(l.reports, report)
+	} else {
+		l.reports[l.next] = report
+	}
+
+	l.next = (l.next + 1) % cap(l.reports)
+
+	l.counts.Total++
+	l.counts.LastAtMs = report.AtMs
+
+	switch report.Kind {
+	case KindTask:
+		l.counts.Task++
+	case KindServer:
+		l.counts.Server++
+	}
+}
+
+// Reports returns the kept reports, oldest first.
+func (l *Log) Reports() []Report {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	reports := make([]Report, 0, len(l.reports))
+
+	if len(l.reports) < cap(l.reports) {
+		return append(reports, l.reports...)
+	}
+
+	reports = append(reports, l.reports[l.next:]...)
+
+	return append(reports, l.reports[:l.next]...)
+}
+
+func (l *Log) Counts() Counts {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.counts
+}
@@ -0,0 +1,87 @@
+package crashes_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sconcur/internal/crashes"
+	"sconcur/internal/dto"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func report(taskKey string, kind crashes.Kind) crashes.Report {
+	return crashes.Report{AtMs: int64(len(taskKey)), Kind: kind, TaskKey: taskKey}
+}
+
+func TestLogKeepsTheLastReportsOldestFirst(t *testing.T) {
+	log := crashes.NewLog(3)
+
+	for _, taskKey := range []string{"a", "b", "c", "d", "e"} {
+		log.Record(report(taskKey, crashes.KindTask))
+	}
+
+	var taskKeys []string
+
+	for _, kept := range log.Reports() {
+		taskKeys = append(taskKeys, kept.TaskKey)
+	}
+
+	if strings.Join(taskKeys, ",") != "c,d,e" {
+		t.Fatalf("unexpected reports %v", taskKeys)
+	}
+}
+
+func TestCountsCoverDroppedReports(t *testing.T) {
+	log := crashes.NewLog(1)
+
+	log.Record(report("a", crashes.KindTask))
+	log.Record(report("bb", crashes.KindServer))
+	log.Record(report("ccc", crashes.KindTask))
+
+	counts := log.Counts()
+
+	if counts.Total != 3 || counts.Task != 2 || counts.Server != 1 || counts.LastAtMs != 3 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+
+	if len(log.Reports()) != 1 {
+		t.Fatalf("expected one kept report, got %d", len(log.Reports()))
+	}
+}
+
+func TestNewReportDescribesTheMessage(t *testing.T) {
+	payload, err := msgpack.Marshal(map[string]string{"cm": "fnd", "cl": "users"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &dto.Message{FlowKey: "f", Method: types.MethodMongodb, TaskKey: "t", Payload: payload}
+
+	created := crashes.NewReport(crashes.KindTask, msg, "t", errors.New("boom"))
+
+	if created.Method != types.MethodMongodb || created.Command != "fnd" || created.FlowKey != "f" || created.TaskKey != "t" {
+		t.Fatalf("unexpected report %+v", created)
+	}
+
+	if created.Message != "panic: boom" || !strings.Contains(created.Stack, "crashes_test") {
+		t.Fatalf("unexpected message or stack %+v", created)
+	}
+
+	if !strings.Contains(created.PayloadPreview, "users") {
+		t.Fatalf("unexpected preview %q", created.PayloadPreview)
+	}
+}
+
+func TestPayloadPreviewIsTrimmed(t *testing.T) {
+	msg := &dto.Message{Method: types.MethodSleep, Payload: []byte(strings.Repeat("x", 1000) + "\x00")}
+
+	preview := crashes.NewReport(crashes.KindTask, msg, "t", "boom").PayloadPreview
+
+	if !strings.HasSuffix(preview, "... (1001 bytes)") || len(preview) > 300 {
+		t.Fatalf("unexpected preview %q", preview)
+	}
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "sconcur/internal/types"
+import (
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
 
 type Message struct {
 	FlowKey string       `json:"fk" msgpack:"fk"`
@@ -8,4 +12,23 @@ type Message struct {
 	TaskKey string       `json:"tk" msgpack:"tk"`
 	Payload []byte       `json:"pl" msgpack:"pl"`
 	IsNext  bool         `json:"nx" msgpack:"nx"`
+	// TimeoutMs is the optional deadline of the task, relative to its push; 0 means
+	// none. Applied to the task context before the feature runs (see
+	// tasks.NewTaskWithDeadline); a streaming state opened by the task hands the
+	// same deadline down to every next() on it.
+	TimeoutMs int `json:"to" msgpack:"to"`
+}
+
+// PayloadCommand returns the `cm` of a command-envelope payload ("fnd" of a
+// MongoDB find, "qry" of a SQL query), "" for other payloads.
+func PayloadCommand(payload []byte) string {
+	var envelope struct {
+		Command string `msgpack:"cm"`
+	}
+
+	if err := msgpack.Unmarshal(payload, &envelope); err != nil {
+		return ""
+	}
+
+	return envelope.Command
 }
@@ -1,6 +1,9 @@
 package dto
 
-import "sconcur/internal/types"
+import (
+	"sconcur/internal/errs"
+	"sconcur/internal/types"
+)
 
 type Result struct {
 	FlowKey     string       `json:"fk" msgpack:"fk"`
@@ -10,6 +13,9 @@ type Result struct {
 	Payload     string       `json:"pl" msgpack:"pl"`
 	HasNext     bool         `json:"hn" msgpack:"hn"`
 	ExecutionMs int          `json:"ems" msgpack:"ems"`
+	// IsCancelled marks the error result of a task aborted on its own (cancelTask),
+	// so PHP can tell an abandoned task from a failed one.
+	IsCancelled bool `json:"cn" msgpack:"cn"`
 }
 
 func NewSuccessResult(message *Message, payload string, executionMs int) *Result {
@@ -44,3 +50,39 @@ func NewErrorResult(message *Message, payload string) *Result {
 		Payload: payload,
 	}
 }
+
+// NewCancelledResult answers a task cancelled on its own: an error result flagged
+// IsCancelled, carrying a cancelled-class error.
+func NewCancelledResult(message *Message, reason string) *Result {
+	return &Result{
+		FlowKey:     message.FlowKey,
+		Method:      message.Method,
+		TaskKey:     message.TaskKey,
+		IsError:     true,
+		Payload:     errs.Cancelled(reason),
+		IsCancelled: true,
+	}
+}
+
+// NewStoppedResult answers a task whose flow was stopped: a cancelled result
+// carrying the code and message of the stop cause.
+func NewStoppedResult(message *Message, cause *errs.StopCause) *Result {
+	return &Result{
+		FlowKey:     message.FlowKey,
+		Method:      message.Method,
+		TaskKey:     message.TaskKey,
+		IsError:     true,
+		Payload:     errs.Stopped(cause),
+		IsCancelled: true,
+	}
+}
+
+// timeoutMessage is the message of every result answering an expired task,
+// whatever the feature: one timeout model across features.
+const timeoutMessage = "task deadline exceeded"
+
+// NewTimeoutResult answers a task whose deadline (Message.TimeoutMs) expired
+// before the feature produced its result.
+func NewTimeoutResult(message *Message) *Result {
+	return NewErrorResult(message, errs.Timeout(timeoutMessage))
+}
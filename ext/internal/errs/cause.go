@@ -0,0 +1,55 @@
+package errs
+
+import (
+	"context"
+	"errors"
+)
+
+// StopCause is why a flow was stopped: the cause its context is cancelled with
+// (context.WithCancelCause), seen by every task of the flow through
+// context.Cause. Code is free-form (the PHP caller picks it, e.g. "user_abort");
+// CodeFlowStopped and CodeShutdown are the ones the extension sets on its own.
+type StopCause struct {
+	Code    string
+	Message string
+}
+
+// NewStopCause builds a stop cause, CodeFlowStopped and a generic message filling
+// in for an empty code or message.
+func NewStopCause(code string, message string) *StopCause {
+	if code == "" {
+		code = CodeFlowStopped
+	}
+
+	if message == "" {
+		message = "flow stopped"
+	}
+
+	return &StopCause{Code: code, Message: message}
+}
+
+func (c *StopCause) Error() string {
+	return c.Code + ": " + c.Message
+}
+
+// CauseOf returns the stop cause ctx was cancelled with, or nil when ctx is not
+// cancelled, expired on its deadline, or was cancelled without one.
+func CauseOf(ctx context.Context) *StopCause {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+
+	var cause *StopCause
+
+	if errors.As(context.Cause(ctx), &cause) {
+		return cause
+	}
+
+	return nil
+}
+
+// Stopped is the payload of a task cancelled by the stop of its flow: a
+// cancelled-class error carrying the code and message of the cause.
+func Stopped(cause *StopCause) string {
+	return Make(CategoryCancelled, cause.Code, cause.Message)
+}
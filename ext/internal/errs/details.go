@@ -0,0 +1,207 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Category is the coarse class of a failure PHP retry and alerting logic branches
+// on. The values cross the PHP↔Go boundary.
+// PHP: SConcur\Dto\TaskErrorDto::$category.
+type Category string
+
+const (
+	// CategoryNetwork — connect/DNS/TLS/reset: the peer was not (or no longer)
+	// reachable.
+	CategoryNetwork Category = "network"
+	// CategoryTimeout — a deadline expired (task, feature or socket level).
+	CategoryTimeout Category = "timeout"
+	// CategoryValidation — the command itself is invalid (malformed payload,
+	// unknown command or id, bad option); retrying it unchanged cannot help.
+	CategoryValidation Category = "validation"
+	// CategoryDriver — the database/server answered with an error (carries the
+	// driver-native code).
+	CategoryDriver Category = "driver"
+	// CategoryCancelled — the task or its flow was stopped.
+	CategoryCancelled Category = "cancelled"
+	// CategoryPanic — a feature panicked; the panic was turned into a result.
+	CategoryPanic Category = "panic"
+	// CategoryInternal — anything not classified above (local I/O, encoding).
+	CategoryInternal Category = "internal"
+)
+
+// Stable error codes. Unlike messages (driver wording changes between versions)
+// they are part of the protocol, so PHP may match on them.
+const (
+	CodeInternal          = "internal"
+	CodeInvalid           = "invalid"
+	CodeDeadlineExceeded  = "deadline_exceeded"
+	CodeCancelled         = "cancelled"
+	CodeFlowStopped       = "flow_stopped"
+	CodeShutdown          = "shutdown"
+	CodePanic             = "panic"
+	CodeNetwork           = "network"
+	CodeNetworkTimeout    = "network_timeout"
+	CodeConnectionRefused = "connection_refused"
+	CodeConnectionReset   = "connection_reset"
+	CodeDnsFailure        = "dns_failure"
+	CodeProxy             = "proxy_failure"
+	CodeDriver            = "driver"
+	CodeDuplicateKey      = "duplicate_key"
+	CodeDeadlock          = "deadlock"
+	CodeLockTimeout       = "lock_timeout"
+	CodeSerialization     = "serialization_failure"
+	CodeBodyTooLarge      = "body_too_large"
+	CodeChannelClosed     = "channel_closed"
+)
+
+// Details is the structured error envelope carried as the payload of every error
+// result, MessagePack-encoded with the short keys below.
+// PHP: decoded into SConcur\Dto\TaskErrorDto by Extension::parseWaitResponse.
+type Details struct {
+	Code      string   `json:"cd" msgpack:"cd"`
+	Category  Category `json:"ct" msgpack:"ct"`
+	Retryable bool     `json:"rt" msgpack:"rt"`
+	// DriverCode is the driver-native code as a string: the MySQL errno, the
+	// Postgres SQLSTATE, the MongoDB server error code. Empty when not a driver
+	// error.
+	DriverCode string `json:"dc" msgpack:"dc"`
+	// Labels are the MongoDB error labels (e.g. RetryableWriteError).
+	Labels  []string `json:"lb" msgpack:"lb"`
+	Message string   `json:"ms" msgpack:"ms"`
+}
+
+// Encode serializes the envelope into the result payload. Marshalling a flat
+// struct of strings cannot fail; should it ever, the plain message still reaches
+// PHP rather than an empty payload.
+func (d *Details) Encode() string {
+	encoded, err := msgpack.Marshal(d)
+
+	if err != nil {
+		return d.Message
+	}
+
+	return string(encoded)
+}
+
+// Decode parses an error result payload back into its envelope.
+func Decode(payload string) (*Details, error) {
+	var details Details
+
+	if err := msgpack.Unmarshal([]byte(payload), &details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// Classifier recognizes the errors of one driver and fills in the driver-native
+// details (code, labels, retryable). It returns false for an error it does not
+// own, leaving it to the generic classification.
+type Classifier func(err error, details *Details) bool
+
+var (
+	classifiersMutex sync.RWMutex
+	classifiers      []Classifier
+)
+
+// RegisterClassifier adds a driver classifier. Called from the driver-owning
+// feature package (SQL drivers, MongoDB) at init, so errs itself stays free of
+// driver imports.
+func RegisterClassifier(classifier Classifier) {
+	classifiersMutex.Lock()
+	defer classifiersMutex.Unlock()
+
+	classifiers = append(classifiers, classifier)
+}
+
+// Classify derives the structured details of err. A stop cause, deadlines and
+// cancellation win over everything (a driver wraps them too), then a registered driver classifier,
+// then the network error kinds; the rest is internal. A stop cause sets the code
+// and category; message stays the feature's, with the cause's message added.
+func Classify(err error, message string) *Details {
+	details := &Details{
+		Code:     CodeInternal,
+		Category: CategoryInternal,
+		Message:  message,
+	}
+
+	var cause *StopCause
+
+	switch {
+	case err == nil:
+		return details
+	case errors.As(err, &cause):
+		details.Code, details.Category = cause.Code, CategoryCancelled
+		details.Message = withCause(message, cause)
+
+		return details
+	case errors.Is(err, context.DeadlineExceeded):
+		details.Code, details.Category, details.Retryable = CodeDeadlineExceeded, CategoryTimeout, true
+
+		return details
+	case errors.Is(err, context.Canceled):
+		details.Code, details.Category = CodeCancelled, CategoryCancelled
+
+		return details
+	}
+
+	classifiersMutex.RLock()
+	registered := classifiers
+	classifiersMutex.RUnlock()
+
+	for _, classifier := range registered {
+		if classifier(err, details) {
+			return details
+		}
+	}
+
+	classifyNetwork(err, details)
+
+	return details
+}
+
+// withCause keeps what the task was doing in its message and adds why it was
+// stopped, unless the message already tells it (the wrapped error's text
+// usually carries the cause).
+func withCause(message string, cause *StopCause) string {
+	switch {
+	case message == "":
+		return cause.Message
+	case strings.Contains(message, cause.Message):
+		return message
+	default:
+		return message + ": " + cause.Message
+	}
+}
+
+// classifyNetwork marks err as network-class when it is one; every network
+// failure is worth a retry.
+func classifyNetwork(err error, details *Details) {
+	var netError net.Error
+	var dnsError *net.DNSError
+
+	switch {
+	case errors.As(err, &netError) && netError.Timeout():
+		details.Code, details.Category = CodeNetworkTimeout, CategoryTimeout
+	case errors.As(err, &dnsError):
+		details.Code, details.Category = CodeDnsFailure, CategoryNetwork
+	case errors.Is(err, syscall.ECONNREFUSED):
+		details.Code, details.Category = CodeConnectionRefused, CategoryNetwork
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.Is(err, io.ErrUnexpectedEOF):
+		details.Code, details.Category = CodeConnectionReset, CategoryNetwork
+	case errors.As(err, &netError):
+		details.Code, details.Category = CodeNetwork, CategoryNetwork
+	default:
+		return
+	}
+
+	details.Retryable = true
+}
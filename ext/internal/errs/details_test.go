@@ -0,0 +1,122 @@
+package errs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyGenericErrors(t *testing.T) {
+	cases := map[string]struct {
+		err       error
+		category  Category
+		code      string
+		retryable bool
+	}{
+		"deadline":  {fmt.Errorf("query: %w", context.DeadlineExceeded), CategoryTimeout, CodeDeadlineExceeded, true},
+		"cancelled": {fmt.Errorf("query: %w", context.Canceled), CategoryCancelled, CodeCancelled, false},
+		"stopped":   {fmt.Errorf("write: %w", NewStopCause(CodeShutdown, "server stopped")), CategoryCancelled, CodeShutdown, false},
+		"refused":   {&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, CategoryNetwork, CodeConnectionRefused, true},
+		"reset":     {&net.OpError{Op: "read", Err: syscall.ECONNRESET}, CategoryNetwork, CodeConnectionReset, true},
+		"dns":       {&net.DNSError{Err: "no such host", Name: "x.invalid"}, CategoryNetwork, CodeDnsFailure, true},
+		"other":     {fmt.Errorf("boom"), CategoryInternal, CodeInternal, false},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			details := Classify(testCase.err, "m")
+
+			if details.Category != testCase.category || details.Code != testCase.code || details.Retryable != testCase.retryable {
+				t.Fatalf("got %+v, want %s/%s retryable=%v", details, testCase.category, testCase.code, testCase.retryable)
+			}
+		})
+	}
+}
+
+func TestFactoryPayloadRoundTrips(t *testing.T) {
+	factory := NewErrorsFactory("feature")
+
+	details, err := Decode(factory.ByNetwork("send", fmt.Errorf("protocol error")))
+
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	// Unclassified errors reported through ByNetwork stay network-class.
+	if details.Category != CategoryNetwork || !details.Retryable {
+		t.Fatalf("got %+v, want a retryable network error", details)
+	}
+
+	if details.Message != "feature: send: protocol error" {
+		t.Fatalf("message = %q", details.Message)
+	}
+
+	details, _ = Decode(factory.ByText("unknown command"))
+
+	if details.Category != CategoryValidation || details.Code != CodeInvalid {
+		t.Fatalf("got %+v, want a validation error", details)
+	}
+}
+
+func TestStopCauseKeepsTheFeatureMessage(t *testing.T) {
+	cause := NewStopCause(CodeShutdown, "server stopped")
+
+	details, err := Decode(NewErrorsFactory("http").ByErr("request to example.test", fmt.Errorf("do: %w", cause)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if details.Code != CodeShutdown || details.Category != CategoryCancelled {
+		t.Fatalf("got %+v, want the cause in code and category", details)
+	}
+
+	if !strings.Contains(details.Message, "request to example.test") || !strings.Contains(details.Message, "server stopped") {
+		t.Fatalf("message %q lost what the task was doing or why it stopped", details.Message)
+	}
+
+	if message := withCause("http: request to example.test", cause); message != "http: request to example.test: server stopped" {
+		t.Fatalf("expected the cause appended, got %q", message)
+	}
+}
+
+func TestCauseOfFollowsDerivedContexts(t *testing.T) {
+	parent, cancel := context.WithCancelCause(context.Background())
+	child, childCancel := context.WithDeadline(parent, time.Now().Add(time.Hour))
+	defer childCancel()
+
+	if CauseOf(child) != nil {
+		t.Fatal("a live context has no stop cause")
+	}
+
+	cancel(NewStopCause("", ""))
+
+	cause := CauseOf(child)
+
+	if cause == nil || cause.Code != CodeFlowStopped || cause.Message != "flow stopped" {
+		t.Fatalf("expected the default cause, got %+v", cause)
+	}
+
+	details, err := Decode(Stopped(cause))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if details.Category != CategoryCancelled || details.Code != CodeFlowStopped {
+		t.Fatalf("unexpected envelope %+v", details)
+	}
+
+	expired, expiredCancel := context.WithDeadline(context.Background(), time.Now())
+	defer expiredCancel()
+
+	<-expired.Done()
+
+	if CauseOf(expired) != nil {
+		t.Fatal("an expired deadline is not a stop")
+	}
+}
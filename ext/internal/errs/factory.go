@@ -1,7 +1,14 @@
 package errs
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
 
+// Factory builds the error payloads of one feature: every message is prefixed
+// with the feature label and wrapped into the structured Details envelope, so
+// each feature reports failures through the same API.
 type Factory struct {
 	prefix string
 }
@@ -12,16 +19,75 @@ func NewErrorsFactory(prefix string) *Factory {
 	}
 }
 
+// ByErr reports a failure caused by err, classified from the error itself
+// (deadline, cancellation, driver, network — see Classify).
 func (f *Factory) ByErr(text string, err error) string {
-	return f.make(text, err).Error()
+	return Classify(err, f.make(text, err).Error()).Encode()
 }
 
+// ByText reports an invalid command that has no underlying error (unknown
+// command or id, bad option value).
 func (f *Factory) ByText(text string) string {
-	return fmt.Sprintf(
-		"%s: %s",
-		f.prefix,
-		text,
-	)
+	return f.ByKind(CategoryValidation, CodeInvalid, text)
+}
+
+// ByInvalid reports a command rejected because of err (a payload that does not
+// decode, a request that cannot be built).
+func (f *Factory) ByInvalid(text string, err error) string {
+	details := &Details{
+		Code:     CodeInvalid,
+		Category: CategoryValidation,
+		Message:  f.make(text, err).Error(),
+	}
+
+	return details.Encode()
+}
+
+// ByNetwork reports a failure talking to the peer. err is classified as usual
+// (a timeout stays a timeout); what is left unclassified is still reported as
+// network-class, e.g. a redirect loop or a protocol error mid-response.
+func (f *Factory) ByNetwork(text string, err error) string {
+	details := Classify(err, f.make(text, err).Error())
+
+	if details.Category == CategoryInternal {
+		details.Code = CodeNetwork
+		details.Category = CategoryNetwork
+		details.Retryable = true
+	}
+
+	return details.Encode()
+}
+
+// ByProxy reports a failure of the proxy between the client and the peer (the
+// proxy is unreachable, refused the credentials or could not reach the peer).
+// The code tells it apart from a failure of the peer itself; the category stays
+// network, or timeout when err is one. It is retryable only when err is a
+// network failure or timeout: refused credentials would be refused again.
+func (f *Factory) ByProxy(text string, err error) string {
+	// net/http wraps its failures into a *url.Error, which passes for a network
+	// error itself: classify what it wraps.
+	cause := err
+
+	var urlError *url.Error
+
+	if errors.As(err, &urlError) {
+		cause = urlError.Err
+	}
+
+	details := Classify(cause, f.make(text, err).Error())
+
+	if details.Category != CategoryTimeout {
+		details.Category = CategoryNetwork
+	}
+
+	details.Code = CodeProxy
+
+	return details.Encode()
+}
+
+// ByKind reports a failure of an explicit category and code.
+func (f *Factory) ByKind(category Category, code string, text string) string {
+	return Make(category, code, fmt.Sprintf("%s: %s", f.prefix, text))
 }
 
 func (f *Factory) make(text string, err error) error {
@@ -32,3 +98,43 @@ func (f *Factory) make(text string, err error) error {
 		err,
 	)
 }
+
+// Make is the payload of a failure of an explicit category and code whose
+// message is kept verbatim (no feature prefix).
+func Make(category Category, code string, message string) string {
+	details := &Details{
+		Code:     code,
+		Category: category,
+		Message:  message,
+	}
+
+	return details.Encode()
+}
+
+// Timeout is the payload of a task whose own deadline expired.
+func Timeout(message string) string {
+	details := &Details{
+		Code:      CodeDeadlineExceeded,
+		Category:  CategoryTimeout,
+		Retryable: true,
+		Message:   message,
+	}
+
+	return details.Encode()
+}
+
+// Cancelled is the payload of a task stopped before it produced its result.
+func Cancelled(message string) string {
+	return Make(CategoryCancelled, CodeCancelled, message)
+}
+
+// Panic is the payload of a task whose feature panicked.
+func Panic(message string) string {
+	return Make(CategoryPanic, CodePanic, message)
+}
+
+// Invalid is the payload of a command rejected outside any feature (e.g. a next()
+// for a state that was never opened).
+func Invalid(message string) string {
+	return Make(CategoryValidation, CodeInvalid, message)
+}
@@ -0,0 +1,306 @@
+// Package faults makes the Go side misbehave on demand, for testing the PHP retry
+// and timeout handling: per method (e.g. "hc") or per method and command (e.g.
+// "mng:fnd", "my:qry") it delays tasks, answers them with a synthetic error of a
+// chosen class, drops their result, or panics in the feature goroutine.
+//
+// It is disabled by default and costs one atomic load per message while disabled.
+// Rules are set from a JSON array, through the setFaults export or the
+// SCONCUR_FAULTS environment variable read when the extension loads (see
+// docs/fault-injection.md).
+package faults
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/logger"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EnvVariable holds the rules applied when the extension loads.
+const EnvVariable = "SCONCUR_FAULTS"
+
+type Action string
+
+const (
+	// ActionLatency delays the task by LatencyMs before the feature runs; the task
+	// deadline and cancellation still apply while it waits.
+	ActionLatency Action = "latency"
+	// ActionError answers the task with an error of Category (and Code) instead of
+	// running the feature.
+	ActionError Action = "error"
+	// ActionDrop runs the feature but discards its result, as if lost on the way:
+	// the task is answered only by its deadline, a cancel or the flow stop.
+	ActionDrop Action = "drop"
+	// ActionPanic panics in the task goroutine instead of running the feature.
+	ActionPanic Action = "panic"
+)
+
+// Rule is one fault. It fires on every matching call, on every Every-th one, or
+// with Probability — at most one of the two may be set.
+type Rule struct {
+	// Target is a method ("hc") or a method and the command of its payload
+	// envelope ("mng:fnd").
+	Target      string        `json:"target"`
+	Action      Action        `json:"action"`
+	LatencyMs   int           `json:"latencyMs"`
+	Category    errs.Category `json:"category"`
+	Code        string        `json:"code"`
+	Message     string        `json:"message"`
+	Probability float64       `json:"probability"`
+	Every       int64         `json:"every"`
+}
+
+// rule is a validated Rule with its call counter.
+type rule struct {
+	Rule
+
+	method  types.Method
+	command string
+	calls   atomic.Int64
+}
+
+// fires counts the call and reports whether the rule applies to it.
+func (r *rule) fires() bool {
+	call := r.calls.Add(1)
+
+	switch {
+	case r.Every > 0:
+		return call%r.Every == 0
+	case r.Probability > 0:
+		return rand.Float64() < r.Probability
+	default:
+		return true
+	}
+}
+
+// registry is an immutable set of rules, swapped as a whole on reconfiguration.
+type registry struct {
+	rules []*rule
+	// byCommand lists the methods with command-level rules: only their payloads
+	// are decoded to find the command.
+	byCommand map[types.Method]bool
+}
+
+var current atomic.Pointer[registry]
+
+func init() {
+	if config := os.Getenv(EnvVariable); config != "" {
+		if err := Load(config); err != nil {
+			logger.Write(fmt.Sprintf("sconcur: %s ignored: %v\n", EnvVariable, err))
+		}
+	}
+}
+
+// Load replaces the rules with the JSON array config; an empty config (or "[]")
+// disables fault injection. Invalid rules leave the current ones in place.
+func Load(config string) error {
+	var rules []Rule
+
+	if strings.TrimSpace(config) != "" {
+		if err := json.Unmarshal([]byte(config), &rules); err != nil {
+			return fmt.Errorf("parse rules: %w", err)
+		}
+	}
+
+	return Configure(rules)
+}
+
+// Configure replaces the rules; none disables fault injection.
+func Configure(rules []Rule) error {
+	if len(rules) == 0 {
+		current.Store(nil)
+
+		return nil
+	}
+
+	built := &registry{byCommand: make(map[types.Method]bool)}
+
+	for index, definition := range rules {
+		compiled, err := compile(definition)
+
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", index, err)
+		}
+
+		if compiled.command != "" {
+			built.byCommand[compiled.method] = true
+		}
+
+		built.rules = append(built.rules, compiled)
+	}
+
+	current.Store(built)
+
+	return nil
+}
+
+func compile(definition Rule) (*rule, error) {
+	method, command, _ := strings.Cut(definition.Target, ":")
+
+	if method == "" {
+		return nil, errors.New("target must name a method")
+	}
+
+	switch definition.Action {
+	case ActionLatency:
+		if definition.LatencyMs <= 0 {
+			return nil, errors.New("latency needs a positive latencyMs")
+		}
+	case ActionError:
+		if _, ok := defaultCodes[definition.Category]; !ok {
+			return nil, fmt.Errorf("unknown error category %q", definition.Category)
+		}
+	case ActionDrop, ActionPanic:
+	default:
+		return nil, fmt.Errorf("unknown action %q", definition.Action)
+	}
+
+	if definition.Probability < 0 || definition.Probability > 1 {
+		return nil, errors.New("probability must be between 0 and 1")
+	}
+
+	if definition.Every < 0 {
+		return nil, errors.New("every must not be negative")
+	}
+
+	if definition.Every > 0 && definition.Probability > 0 {
+		return nil, errors.New("set either every or probability, not both")
+	}
+
+	return &rule{
+		Rule:    definition,
+		method:  types.Method(method),
+		command: command,
+	}, nil
+}
+
+// Enabled reports whether any rule is set.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Wrap returns handle with the faults firing for msg applied around it, or handle
+// itself when none does. Called once per message when its task is created, so the
+// every-N counters count messages. A next() carries no method and is never faulted.
+func Wrap(msg *dto.Message, handle func(task *tasks.Task)) func(task *tasks.Task) {
+	active := current.Load()
+
+	if active == nil || msg.IsNext {
+		return handle
+	}
+
+	command := ""
+
+	if active.byCommand[msg.Method] {
+		command = dto.PayloadCommand(msg.Payload)
+	}
+
+	var latency time.Duration
+	var terminal *rule
+
+	for _, candidate := range active.rules {
+		if candidate.method != msg.Method || (candidate.command != "" && candidate.command != command) {
+			continue
+		}
+
+		if candidate.Action != ActionLatency && terminal != nil {
+			continue
+		}
+
+		if !candidate.fires() {
+			continue
+		}
+
+		if candidate.Action == ActionLatency {
+			latency += time.Duration(candidate.LatencyMs) * time.Millisecond
+		} else {
+			terminal = candidate
+		}
+	}
+
+	if latency == 0 && terminal == nil {
+		return handle
+	}
+
+	return func(task *tasks.Task) {
+		if latency > 0 && !sleep(task, latency) {
+			return
+		}
+
+		if terminal == nil {
+			handle(task)
+
+			return
+		}
+
+		switch terminal.Action {
+		case ActionError:
+			task.AddResult(dto.NewErrorResult(task.GetMessage(), terminal.payload()))
+		case ActionDrop:
+			task.DropResult()
+			handle(task)
+		case ActionPanic:
+			panic(fmt.Sprintf("fault injected into %s", terminal.Target))
+		}
+	}
+}
+
+// sleep waits d, or less when the task ends first (deadline, cancel, flow stop —
+// each answers the task on its own). Reports whether the task should go on.
+func sleep(task *tasks.Task, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-task.GetContext().Done():
+		return false
+	}
+}
+
+// defaultCodes are the codes of the injected errors of each category when the
+// rule sets none: the codes the real failures of that class carry.
+var defaultCodes = map[errs.Category]string{
+	errs.CategoryNetwork:    errs.CodeNetwork,
+	errs.CategoryTimeout:    errs.CodeDeadlineExceeded,
+	errs.CategoryValidation: errs.CodeInvalid,
+	errs.CategoryDriver:     errs.CodeDriver,
+	errs.CategoryCancelled:  errs.CodeCancelled,
+	errs.CategoryPanic:      errs.CodePanic,
+	errs.CategoryInternal:   errs.CodeInternal,
+}
+
+// payload builds the error envelope of an error rule. Network and timeout errors
+// are retryable, as the real ones are.
+func (r *rule) payload() string {
+	code := r.Code
+
+	if code == "" {
+		code = defaultCodes[r.Category]
+	}
+
+	message := r.Message
+
+	if message == "" {
+		message = fmt.Sprintf("injected %s error", r.Category)
+	}
+
+	details := &errs.Details{
+		Code:      code,
+		Category:  r.Category,
+		Retryable: r.Category == errs.CategoryNetwork || r.Category == errs.CategoryTimeout,
+		Message:   "fault: " + message,
+	}
+
+	return details.Encode()
+}
@@ -0,0 +1,206 @@
+package faults_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sconcur/internal/crashes"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/faults"
+	"sconcur/internal/flows"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func load(t *testing.T, config string) {
+	t.Helper()
+
+	if err := faults.Load(config); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = faults.Configure(nil)
+	})
+}
+
+func sleepMessage(t *testing.T, taskKey string, timeoutMs int) *dto.Message {
+	t.Helper()
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &dto.Message{FlowKey: "f", Method: types.MethodSleep, TaskKey: taskKey, Payload: payload, TimeoutMs: timeoutMs}
+}
+
+// push runs msg on a fresh flow and returns its result.
+func push(t *testing.T, flow *flows.Flow, results chan *dto.Result, msg *dto.Message) *dto.Result {
+	t.Helper()
+
+	if err := flow.HandleMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case result := <-results:
+		flow.OnDelivered(result)
+
+		return result
+	case <-time.After(2 * time.Second):
+		t.Fatal("no result")
+
+		return nil
+	}
+}
+
+func newFlow(t *testing.T) (*flows.Flow, chan *dto.Result) {
+	t.Helper()
+
+	results := make(chan *dto.Result, 8)
+	flow := flows.NewFlow(context.Background(), "f", results)
+
+	t.Cleanup(flow.Cancel)
+
+	return flow, results
+}
+
+func decode(t *testing.T, result *dto.Result) *errs.Details {
+	t.Helper()
+
+	if !result.IsError {
+		t.Fatalf("expected an error result, got %+v", result)
+	}
+
+	details, err := errs.Decode(result.Payload)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return details
+}
+
+func TestLoadRejectsInvalidRules(t *testing.T) {
+	for name, config := range map[string]string{
+		"not json":        `{`,
+		"no method":       `[{"target":":fnd","action":"drop"}]`,
+		"unknown action":  `[{"target":"sl","action":"explode"}]`,
+		"no latency":      `[{"target":"sl","action":"latency"}]`,
+		"bad category":    `[{"target":"sl","action":"error","category":"cosmic"}]`,
+		"bad probability": `[{"target":"sl","action":"drop","probability":1.5}]`,
+		"both triggers":   `[{"target":"sl","action":"drop","probability":0.5,"every":2}]`,
+	} {
+		if err := faults.Load(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+
+		if faults.Enabled() {
+			t.Fatalf("%s: a rejected config must not enable faults", name)
+		}
+	}
+}
+
+func TestErrorRuleFiresOnEveryNthCall(t *testing.T) {
+	load(t, `[{"target":"sl","action":"error","category":"network","every":2}]`)
+
+	flow, results := newFlow(t)
+
+	for index, key := range []string{"a", "b", "c", "d"} {
+		result := push(t, flow, results, sleepMessage(t, key, 0))
+
+		if index%2 == 0 {
+			if result.IsError {
+				t.Fatalf("call %d must pass through, got %q", index+1, result.Payload)
+			}
+
+			continue
+		}
+
+		details := decode(t, result)
+
+		if details.Category != errs.CategoryNetwork || details.Code != errs.CodeNetwork || !details.Retryable {
+			t.Fatalf("call %d: unexpected error %+v", index+1, details)
+		}
+	}
+}
+
+func TestCommandTargetMatchesOnlyThatCommand(t *testing.T) {
+	load(t, `[{"target":"mng:fnd","action":"error","category":"driver","code":"duplicate_key"}]`)
+
+	for command, faulted := range map[types.MongodbCommand]bool{types.MongodbFind: true, types.MongodbInsertOne: false} {
+		payload, err := msgpack.Marshal(map[string]any{"cm": command})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := &dto.Message{FlowKey: "f", Method: types.MethodMongodb, TaskKey: string(command), Payload: payload}
+		results := make(chan *dto.Result, 1)
+		ran := false
+
+		faults.Wrap(msg, func(task *tasks.Task) { ran = true })(tasks.NewTask(context.Background(), results, msg))
+
+		if ran == faulted {
+			t.Fatalf("%s: feature ran %t, want %t", command, ran, !faulted)
+		}
+
+		if faulted {
+			if details := decode(t, <-results); details.Code != errs.CodeDuplicateKey {
+				t.Fatalf("unexpected error %+v", details)
+			}
+		}
+	}
+}
+
+func TestPanicRuleIsAnsweredAsAPanic(t *testing.T) {
+	load(t, `[{"target":"sl","action":"panic"}]`)
+
+	flow, results := newFlow(t)
+
+	if details := decode(t, push(t, flow, results, sleepMessage(t, "a", 0))); details.Category != errs.CategoryPanic {
+		t.Fatalf("unexpected error %+v", details)
+	}
+
+	reports := crashes.Get().Reports()
+
+	if last := reports[len(reports)-1]; last.Kind != crashes.KindTask || last.Method != types.MethodSleep || last.TaskKey != "a" {
+		t.Fatalf("panic not recorded: %+v", last)
+	}
+}
+
+func TestDroppedResultIsAnsweredByTheDeadline(t *testing.T) {
+	load(t, `[{"target":"sl","action":"drop"}]`)
+
+	flow, results := newFlow(t)
+
+	if details := decode(t, push(t, flow, results, sleepMessage(t, "a", 50))); details.Category != errs.CategoryTimeout {
+		t.Fatalf("unexpected error %+v", details)
+	}
+}
+
+func TestLatencyRuleDelaysTheTask(t *testing.T) {
+	load(t, `[{"target":"sl","action":"latency","latencyMs":60}]`)
+
+	flow, results := newFlow(t)
+	startedAt := time.Now()
+
+	if result := push(t, flow, results, sleepMessage(t, "a", 0)); result.IsError {
+		t.Fatalf("unexpected error %q", result.Payload)
+	}
+
+	if elapsed := time.Since(startedAt); elapsed < 60*time.Millisecond {
+		t.Fatalf("the task took %s, expected at least the injected 60ms", elapsed)
+	}
+
+	// Past its deadline the delayed task is answered with the timeout result.
+	if details := decode(t, push(t, flow, results, sleepMessage(t, "b", 20))); details.Category != errs.CategoryTimeout {
+		t.Fatalf("unexpected error %+v", details)
+	}
+}
@@ -0,0 +1,167 @@
+package channel_feature
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errChannelClosed is returned by a send on a closed channel.
+var errChannelClosed = errors.New("send on closed channel")
+
+// channel is one named channel: a Go channel of opaque values plus a done signal.
+// The value channel itself is never closed (a send racing with close would panic);
+// closing only closes done, and receivers drain what is still buffered.
+//
+// Blocked senders and receivers are queued by the Go runtime in arrival order, so
+// receivers on different flows are served first come, first served.
+//
+// A value taken by a receiver whose task was answered meanwhile (deadline, cancel,
+// flow stop) is handed back (requeue) and served before the buffered ones: every
+// blocked receiver is woken to look for it, and a receiver that takes a buffered
+// value while one is requeued swaps it for the requeued one.
+type channel struct {
+	// capacity bounds the buffer only: requeued values are held apart from it, so
+	// a full channel briefly holds one more value per receiver that hands one back.
+	capacity  int
+	values    chan string
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mutex    sync.Mutex
+	requeued []string
+	// wake is closed (and replaced) when a value is requeued, waking every
+	// blocked receiver at once.
+	wake chan struct{}
+}
+
+func newChannel(capacity int) *channel {
+	return &channel{
+		capacity: capacity,
+		values:   make(chan string, capacity),
+		done:     make(chan struct{}),
+		wake:     make(chan struct{}),
+	}
+}
+
+// send puts value into the channel, waiting while it is full (backpressure) until
+// a receiver makes room, the channel is closed or ctx is done.
+func (c *channel) send(ctx context.Context, value string) error {
+	if c.isClosed() {
+		return errChannelClosed
+	}
+
+	select {
+	case c.values <- value:
+		return nil
+	case <-c.done:
+		return errChannelClosed
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// receive takes the next value, waiting until one arrives. ok is false once the
+// channel is closed and nothing is left in its buffer.
+func (c *channel) receive(ctx context.Context) (value string, ok bool, err error) {
+	for {
+		value, ok, wake := c.popRequeued()
+
+		if ok {
+			return value, true, nil
+		}
+
+		select {
+		case value = <-c.values:
+			return c.preferRequeued(value), true, nil
+		case <-wake:
+		case <-c.done:
+			// Closed: hand out what is still buffered before reporting the end.
+			if value, ok, _ = c.popRequeued(); ok {
+				return value, true, nil
+			}
+
+			select {
+			case value = <-c.values:
+				return value, true, nil
+			default:
+				return "", false, nil
+			}
+		case <-ctx.Done():
+			return "", false, context.Cause(ctx)
+		}
+	}
+}
+
+// requeue hands back a received value nobody got, ahead of the buffered ones: it
+// was the oldest when taken.
+func (c *channel) requeue(value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.requeued = append([]string{value}, c.requeued...)
+
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// popRequeued takes the oldest requeued value; without one, it returns the wake
+// channel to wait on, read under the same lock so no requeue is missed.
+func (c *channel) popRequeued() (string, bool, chan struct{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.requeued) == 0 {
+		return "", false, c.wake
+	}
+
+	value := c.requeued[0]
+	c.requeued = c.requeued[1:]
+
+	return value, true, nil
+}
+
+// preferRequeued keeps the order when a buffered value was taken while one was
+// requeued: the requeued value is older, so it is returned and the buffered one
+// waits at the back of the requeued ones, still ahead of the rest of the buffer.
+func (c *channel) preferRequeued(value string) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.requeued) == 0 {
+		return value
+	}
+
+	oldest := c.requeued[0]
+	c.requeued = append(c.requeued[1:], value)
+
+	return oldest
+}
+
+// drained reports whether the channel is closed with nothing left to receive.
+func (c *channel) drained() bool {
+	if !c.isClosed() || len(c.values) > 0 {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.requeued) == 0
+}
+
+// close wakes every blocked sender (with errChannelClosed) and receiver. Idempotent.
+func (c *channel) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *channel) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
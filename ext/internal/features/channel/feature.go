@@ -0,0 +1,261 @@
+package channel_feature
+
+import (
+	"errors"
+	"fmt"
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/channel/payloads"
+	"sconcur/internal/helpers"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var _ contracts.FeatureContract = (*ChannelFeature)(nil)
+
+var once sync.Once
+var instance *ChannelFeature
+
+var errFactory = errs.NewErrorsFactory("channel")
+
+// maxCapacity bounds a channel buffer: the values are held in Go memory.
+const maxCapacity = 1 << 20
+
+// ChannelFeature keeps the named channels coroutines and flows of one worker talk
+// through: a send waits while the channel is full, a receive is a task completing
+// when a value arrives. Both are bounded by the task context.
+type ChannelFeature struct {
+	mutex    sync.Mutex
+	channels map[string]*channel
+}
+
+func Get() *ChannelFeature {
+	once.Do(func() {
+		instance = &ChannelFeature{
+			channels: make(map[string]*channel),
+		}
+	})
+
+	return instance
+}
+
+func (f *ChannelFeature) Handle(task *tasks.Task) {
+	message := task.GetMessage()
+
+	var envelope payloads.Envelope
+
+	if err := msgpack.Unmarshal(message.Payload, &envelope); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse envelope", err)))
+
+		return
+	}
+
+	switch envelope.Command {
+	case types.ChannelCreate:
+		f.handleCreate(task, envelope.Params)
+	case types.ChannelSend:
+		f.handleSend(task, envelope.Params)
+	case types.ChannelReceive:
+		f.handleReceive(task, envelope.Params)
+	case types.ChannelClose:
+		f.handleClose(task, envelope.Params)
+	default:
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("unknown command")))
+	}
+}
+
+// handleCreate creates the channel, or joins it when a channel of that name and
+// capacity is already open, so producer and consumer may both create it. A closed
+// channel of that name is replaced.
+func (f *ChannelFeature) handleCreate(task *tasks.Task, raw msgpack.RawMessage) {
+	startTime := time.Now()
+	message := task.GetMessage()
+
+	var params payloads.CreateParams
+
+	if err := msgpack.Unmarshal(raw, &params); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse create params", err)))
+
+		return
+	}
+
+	if params.Name == "" {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("channel name must not be empty")))
+
+		return
+	}
+
+	if params.Capacity < 0 || params.Capacity > maxCapacity {
+		task.AddResult(dto.NewErrorResult(
+			message,
+			errFactory.ByText(fmt.Sprintf("capacity must be between 0 and %d", maxCapacity)),
+		))
+
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if existing, ok := f.channels[params.Name]; ok && !existing.isClosed() {
+		if existing.capacity != params.Capacity {
+			task.AddResult(dto.NewErrorResult(
+				message,
+				errFactory.ByText(fmt.Sprintf("channel %s exists with capacity %d", params.Name, existing.capacity)),
+			))
+
+			return
+		}
+	} else {
+		f.channels[params.Name] = newChannel(params.Capacity)
+	}
+
+	task.AddResult(dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)))
+}
+
+func (f *ChannelFeature) handleSend(task *tasks.Task, raw msgpack.RawMessage) {
+	startTime := time.Now()
+	message := task.GetMessage()
+
+	var params payloads.SendParams
+
+	if err := msgpack.Unmarshal(raw, &params); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse send params", err)))
+
+		return
+	}
+
+	target := f.lookup(params.Name)
+
+	if target == nil {
+		task.AddResult(dto.NewErrorResult(message, sendErrorPayload(errChannelClosed)))
+
+		return
+	}
+
+	if err := target.send(task.GetContext(), params.Value); err != nil {
+		task.AddResult(dto.NewErrorResult(message, sendErrorPayload(err)))
+
+		return
+	}
+
+	task.AddResult(dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)))
+}
+
+func (f *ChannelFeature) handleReceive(task *tasks.Task, raw msgpack.RawMessage) {
+	startTime := time.Now()
+	message := task.GetMessage()
+
+	var params payloads.NameParams
+
+	if err := msgpack.Unmarshal(raw, &params); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse receive params", err)))
+
+		return
+	}
+
+	source := f.lookup(params.Name)
+
+	if source == nil {
+		f.answerReceived(task, "", false, startTime)
+
+		return
+	}
+
+	value, ok, err := source.receive(task.GetContext())
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("receive", err)))
+
+		return
+	}
+
+	// The task may have been answered while the value was taken (deadline,
+	// cancelTask, flow stop): the value goes back for the next receiver.
+	if !f.answerReceived(task, value, ok, startTime) && ok {
+		source.requeue(value)
+	}
+
+	f.forgetDrained(params.Name, source)
+}
+
+// answerReceived answers a receive and reports whether the answer reached the
+// task.
+func (f *ChannelFeature) answerReceived(task *tasks.Task, value string, ok bool, startTime time.Time) bool {
+	message := task.GetMessage()
+
+	serialized, err := msgpack.Marshal(payloads.Received{Value: value, Ok: ok})
+
+	if err != nil {
+		return task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("marshal received value", err)))
+	}
+
+	return task.AddResult(dto.NewSuccessResult(message, string(serialized), helpers.CalcExecutionMs(startTime)))
+}
+
+// handleClose closes the channel; closing an unknown or closed channel is a no-op.
+// The closed channel stays registered while late receivers drain its buffer, and
+// is dropped once it is empty.
+func (f *ChannelFeature) handleClose(task *tasks.Task, raw msgpack.RawMessage) {
+	startTime := time.Now()
+	message := task.GetMessage()
+
+	var params payloads.NameParams
+
+	if err := msgpack.Unmarshal(raw, &params); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse close params", err)))
+
+		return
+	}
+
+	f.mutex.Lock()
+	target := f.channels[params.Name]
+	f.mutex.Unlock()
+
+	if target != nil {
+		target.close()
+
+		f.forgetDrained(params.Name, target)
+	}
+
+	task.AddResult(dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)))
+}
+
+// lookup returns the named channel, nil when there is none. A name is only known
+// to PHP through Create, so a missing channel is one closed and drained (see
+// forgetDrained): it is answered as closed, like a channel still registered.
+func (f *ChannelFeature) lookup(name string) *channel {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.channels[name]
+}
+
+// forgetDrained drops a closed channel once nothing is left in its buffer, so the
+// names of closed channels do not pile up for the worker's lifetime. Receivers
+// already holding it still see its end.
+func (f *ChannelFeature) forgetDrained(name string, target *channel) {
+	if !target.drained() {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.channels[name] == target {
+		delete(f.channels, name)
+	}
+}
+
+func sendErrorPayload(err error) string {
+	if errors.Is(err, errChannelClosed) {
+		return errFactory.ByKind(errs.CategoryValidation, errs.CodeChannelClosed, err.Error())
+	}
+
+	return errFactory.ByErr("send", err)
+}
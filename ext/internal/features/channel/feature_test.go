@@ -0,0 +1,353 @@
+package channel_feature
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/channel/payloads"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// run handles one channel command in the background and returns the channel its
+// single result arrives on.
+func run(t *testing.T, ctx context.Context, command types.ChannelCommand, params any) chan *dto.Result {
+	t.Helper()
+
+	task, results := newTask(t, ctx, command, params)
+
+	go Get().Handle(task)
+
+	return results
+}
+
+func newTask(t *testing.T, ctx context.Context, command types.ChannelCommand, params any) (*tasks.Task, chan *dto.Result) {
+	t.Helper()
+
+	raw, err := msgpack.Marshal(params)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := msgpack.Marshal(payloads.Envelope{Command: command, Params: raw})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make(chan *dto.Result, 1)
+	message := &dto.Message{Method: types.MethodChannel, FlowKey: "f", TaskKey: string(command), Payload: data}
+
+	return tasks.NewTask(ctx, results, message), results
+}
+
+// create opens a channel closed when the test ends, so a rerun recreates it empty.
+func create(t *testing.T, name string, capacity int) {
+	t.Helper()
+
+	mustSucceed(t, run(t, context.Background(), types.ChannelCreate, payloads.CreateParams{Name: name, Capacity: capacity}))
+
+	t.Cleanup(func() {
+		await(t, run(t, context.Background(), types.ChannelClose, payloads.NameParams{Name: name}))
+	})
+}
+
+func await(t *testing.T, results chan *dto.Result) *dto.Result {
+	t.Helper()
+
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("no result")
+
+		return nil
+	}
+}
+
+func mustSucceed(t *testing.T, results chan *dto.Result) *dto.Result {
+	t.Helper()
+
+	result := await(t, results)
+
+	if result.IsError {
+		t.Fatalf("unexpected error: %q", result.Payload)
+	}
+
+	return result
+}
+
+func assertPending(t *testing.T, results chan *dto.Result) {
+	t.Helper()
+
+	select {
+	case result := <-results:
+		t.Fatalf("expected the command to wait, got %+v", result)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func decodeReceived(t *testing.T, result *dto.Result) payloads.Received {
+	t.Helper()
+
+	var received payloads.Received
+
+	if err := msgpack.Unmarshal([]byte(result.Payload), &received); err != nil {
+		t.Fatal(err)
+	}
+
+	return received
+}
+
+func TestSendWaitsWhileTheChannelIsFull(t *testing.T) {
+	ctx := context.Background()
+	name := "backpressure"
+
+	create(t, name, 1)
+	mustSucceed(t, run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: "first"}))
+
+	blocked := run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: "second"})
+
+	assertPending(t, blocked)
+
+	first := decodeReceived(t, mustSucceed(t, run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name})))
+
+	if !first.Ok || first.Value != "first" {
+		t.Fatalf("received %+v", first)
+	}
+
+	mustSucceed(t, blocked)
+}
+
+func TestBlockedReceiversAreServedInArrivalOrder(t *testing.T) {
+	ctx := context.Background()
+	name := "fairness"
+
+	create(t, name, 0)
+
+	receivers := make([]chan *dto.Result, 3)
+
+	for index := range receivers {
+		receivers[index] = run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name})
+
+		assertPending(t, receivers[index])
+	}
+
+	for _, value := range []string{"a", "b", "c"} {
+		mustSucceed(t, run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: value}))
+	}
+
+	for index, expected := range []string{"a", "b", "c"} {
+		if received := decodeReceived(t, mustSucceed(t, receivers[index])); received.Value != expected {
+			t.Fatalf("receiver %d got %q, want %q", index, received.Value, expected)
+		}
+	}
+}
+
+func TestCloseDrainsBufferThenReportsTheEnd(t *testing.T) {
+	ctx := context.Background()
+	name := "close"
+
+	create(t, name, 2)
+	mustSucceed(t, run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: "left"}))
+	mustSucceed(t, run(t, ctx, types.ChannelClose, payloads.NameParams{Name: name}))
+
+	if received := decodeReceived(t, mustSucceed(t, run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name}))); !received.Ok || received.Value != "left" {
+		t.Fatalf("expected the buffered value, got %+v", received)
+	}
+
+	if received := decodeReceived(t, mustSucceed(t, run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name}))); received.Ok {
+		t.Fatalf("expected the end of the channel, got %+v", received)
+	}
+
+	result := await(t, run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: "late"}))
+
+	if !result.IsError {
+		t.Fatal("a send on a closed channel must fail")
+	}
+
+	details, err := errs.Decode(result.Payload)
+
+	if err != nil || details.Code != errs.CodeChannelClosed {
+		t.Fatalf("expected the channel_closed code, got %+v (%v)", details, err)
+	}
+}
+
+// TestClosedChannelIsForgottenOnceDrained checks a closed channel leaves the
+// registry when its buffer is empty, right away or after the last value is taken.
+func TestClosedChannelIsForgottenOnceDrained(t *testing.T) {
+	ctx := context.Background()
+
+	registered := func(name string) bool {
+		Get().mutex.Lock()
+		defer Get().mutex.Unlock()
+
+		_, ok := Get().channels[name]
+
+		return ok
+	}
+
+	create(t, "forget-empty", 1)
+	mustSucceed(t, run(t, ctx, types.ChannelClose, payloads.NameParams{Name: "forget-empty"}))
+
+	if registered("forget-empty") {
+		t.Fatal("an empty closed channel must be dropped on close")
+	}
+
+	create(t, "forget-buffered", 1)
+	mustSucceed(t, run(t, ctx, types.ChannelSend, payloads.SendParams{Name: "forget-buffered", Value: "left"}))
+	mustSucceed(t, run(t, ctx, types.ChannelClose, payloads.NameParams{Name: "forget-buffered"}))
+
+	if !registered("forget-buffered") {
+		t.Fatal("a closed channel must stay while values are buffered")
+	}
+
+	mustSucceed(t, run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: "forget-buffered"}))
+
+	if registered("forget-buffered") {
+		t.Fatal("a closed channel must be dropped once drained")
+	}
+}
+
+func TestCreateRejectsADifferentCapacityForAnOpenChannel(t *testing.T) {
+	ctx := context.Background()
+	name := "capacity"
+
+	create(t, name, 4)
+	create(t, name, 4)
+
+	if result := await(t, run(t, ctx, types.ChannelCreate, payloads.CreateParams{Name: name, Capacity: 8})); !result.IsError {
+		t.Fatal("expected a capacity mismatch error")
+	}
+}
+
+func TestCancelledTaskStopsWaitingReceive(t *testing.T) {
+	name := "cancel"
+
+	create(t, name, 0)
+
+	task, results := newTask(t, context.Background(), types.ChannelReceive, payloads.NameParams{Name: name})
+
+	go Get().Handle(task)
+
+	assertPending(t, results)
+
+	task.Cancel()
+
+	if result := await(t, results); !result.IsError {
+		t.Fatal("a cancelled receive must answer with an error")
+	}
+}
+
+// TestReceiveCancelledWhileTakingAValueKeepsIt sends while the receiver is being
+// cancelled, so its receive finds both the value and the cancelled context ready
+// and takes the value about half the time after its task was answered: the value
+// must then stay in the channel for the next receive.
+func TestReceiveCancelledWhileTakingAValueKeepsIt(t *testing.T) {
+	ctx := context.Background()
+	name := "cancel-race"
+
+	create(t, name, 1)
+
+	for i := range 200 {
+		value := fmt.Sprintf("value-%d", i)
+
+		task, results := newTask(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name})
+
+		sent := run(t, ctx, types.ChannelSend, payloads.SendParams{Name: name, Value: value})
+
+		task.CancelWithResult(dto.NewCancelledResult(task.GetMessage(), "task cancelled"))
+
+		mustSucceed(t, sent)
+
+		Get().Handle(task)
+
+		if result := await(t, results); !result.IsError {
+			t.Fatalf("expected the cancelled answer, got %+v", result)
+		}
+
+		received := decodeReceived(t, mustSucceed(t, run(t, ctx, types.ChannelReceive, payloads.NameParams{Name: name})))
+
+		if received.Value != value {
+			t.Fatalf("the cancelled receiver lost %q, next receive got %q", value, received.Value)
+		}
+	}
+}
+
+// TestRequeuedValuesWakeEveryBlockedReceiver requeues two values while two
+// receivers wait: both must be woken and get them, ahead of a value sent after.
+func TestRequeuedValuesWakeEveryBlockedReceiver(t *testing.T) {
+	for range 50 {
+		c := newChannel(4)
+		received := make(chan string, 2)
+
+		for range 2 {
+			go func() {
+				value, _, _ := c.receive(context.Background())
+
+				received <- value
+			}()
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		c.requeue("b")
+		c.requeue("a")
+
+		if err := c.send(context.Background(), "c"); err != nil {
+			t.Fatal(err)
+		}
+
+		got := map[string]bool{}
+
+		for range 2 {
+			select {
+			case value := <-received:
+				got[value] = true
+			case <-time.After(time.Second):
+				t.Fatalf("a blocked receiver was not woken, got %v", got)
+			}
+		}
+
+		if !got["a"] || !got["b"] {
+			t.Fatalf("the requeued values must go first, got %v", got)
+		}
+
+		if value, _, _ := c.receive(context.Background()); value != "c" {
+			t.Fatalf("the buffered value must come last, got %q", value)
+		}
+	}
+}
+
+// TestRequeueOnAFullChannelKeepsTheValueAheadOfTheBuffer hands a value back to a
+// full channel: it is held apart, past capacity, and received first, while a new
+// send still waits for room in the buffer.
+func TestRequeueOnAFullChannelKeepsTheValueAheadOfTheBuffer(t *testing.T) {
+	c := newChannel(1)
+
+	if err := c.send(context.Background(), "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.requeue("a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	if err := c.send(ctx, "c"); err == nil {
+		t.Fatal("a send to a full buffer must wait, whatever was requeued")
+	}
+
+	for _, want := range []string{"a", "b"} {
+		if value, _, _ := c.receive(context.Background()); value != want {
+			t.Fatalf("expected %q, got %q", want, value)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package payloads holds the Go counterparts of the PHP channel payload objects
+// (SConcur\Features\Channel\Payloads\*). Struct tags are the short keys exchanged
+// via MessagePack.
+//
+// Every message is a command envelope (cm/p) under MethodChannel: cm selects the
+// sub-operation, p carries that command's parameters.
+package payloads
+
+import (
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Envelope is the command envelope decoded from the msgpack message.
+// PHP: SConcur\Features\Channel\Payloads\Base\BaseChannelPayload.
+type Envelope struct {
+	Command types.ChannelCommand `json:"cm" msgpack:"cm"`
+	Params  msgpack.RawMessage   `json:"p" msgpack:"p"`
+}
+
+// CreateParams is the `p` content of a Create command: the channel name and its
+// buffer size (0 = unbuffered: a send waits for a receiver).
+// PHP: SConcur\Features\Channel\Payloads\CreatePayload.
+type CreateParams struct {
+	Name     string `json:"n"  msgpack:"n"`
+	Capacity int    `json:"cp" msgpack:"cp"`
+}
+
+// SendParams is the `p` content of a Send command: the channel and the value
+// (opaque bytes, serialized by PHP).
+// PHP: SConcur\Features\Channel\Payloads\SendPayload.
+type SendParams struct {
+	Name  string `json:"n" msgpack:"n"`
+	Value string `json:"v" msgpack:"v"`
+}
+
+// NameParams is the `p` content of the Receive and Close commands.
+// PHP: SConcur\Features\Channel\Payloads\ReceivePayload, ClosePayload.
+type NameParams struct {
+	Name string `json:"n" msgpack:"n"`
+}
+
+// Received is the result of a Receive: the value, or Ok=false once the channel is
+// closed and drained.
+// PHP: decoded in SConcur\Features\Channel\Channel::receive.
+type Received struct {
+	Value string `json:"v"  msgpack:"v"`
+	Ok    bool   `json:"ok" msgpack:"ok"`
+}
@@ -0,0 +1,78 @@
+package cron_feature
+
+import (
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/cron/payloads"
+	"sconcur/internal/states"
+	"sconcur/internal/tasks"
+	"sync"
+	"time"
+
+	// Time zones resolve even on hosts without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var _ contracts.FeatureContract = (*CronFeature)(nil)
+
+var once sync.Once
+var instance *CronFeature
+
+var errFactory = errs.NewErrorsFactory("cron")
+
+// CronFeature runs cron schedules: each fire time is a streamed result pulled by
+// next(), so a long-lived worker runs its maintenance jobs in its own event loop
+// instead of a system cron. The schedule lives until the task/flow is stopped.
+type CronFeature struct {
+}
+
+func Get() *CronFeature {
+	once.Do(func() {
+		instance = &CronFeature{}
+	})
+
+	return instance
+}
+
+func (f *CronFeature) Handle(task *tasks.Task) {
+	message := task.GetMessage()
+
+	var payload payloads.CronPayload
+
+	err := msgpack.Unmarshal(message.Payload, &payload)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse error", err)))
+
+		return
+	}
+
+	schedule, err := ParseSchedule(payload.Expression, payload.Timezone)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse expression", err)))
+
+		return
+	}
+
+	if schedule.Next(time.Now()).IsZero() {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("expression never fires: "+payload.Expression)))
+
+		return
+	}
+
+	ctx := task.GetContext()
+
+	result, err := states.Get().Start(ctx, message.TaskKey, newFireState(ctx, message, schedule))
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("start schedule", err)))
+
+		return
+	}
+
+	task.AddResult(result)
+}
@@ -0,0 +1,91 @@
+package cron_feature
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/features/cron/payloads"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func decodeFire(t *testing.T, result *dto.Result) payloads.Fire {
+	t.Helper()
+
+	if result.IsError {
+		t.Fatalf("unexpected error result: %q", result.Payload)
+	}
+
+	var fire payloads.Fire
+
+	if err := msgpack.Unmarshal([]byte(result.Payload), &fire); err != nil {
+		t.Fatalf("decode fire: %v", err)
+	}
+
+	return fire
+}
+
+func TestHandleRejectsInvalidExpression(t *testing.T) {
+	data, err := msgpack.Marshal(payloads.CronPayload{Expression: "* * *"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := &dto.Message{Method: types.MethodCron, FlowKey: "f", TaskKey: "cron-invalid", Payload: data}
+	results := make(chan *dto.Result, 1)
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	if result := <-results; !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+}
+
+func TestFireWaitsForTheNextFireTime(t *testing.T) {
+	state := newFireState(context.Background(), &dto.Message{}, mustParse(t, "* * * * * *", "UTC"))
+
+	fire := decodeFire(t, state.Next())
+
+	if fire.Number != 1 || fire.Missed != 0 {
+		t.Fatalf("got number %d, missed %d", fire.Number, fire.Missed)
+	}
+
+	if fire.ScheduledAtMs%1000 != 0 || fire.FiredAtMs < fire.ScheduledAtMs {
+		t.Fatalf("fired at %d for %d", fire.FiredAtMs, fire.ScheduledAtMs)
+	}
+}
+
+// TestLatePullReportsMissedFireTimes checks fire times that passed while nobody
+// pulled are skipped and counted instead of being delivered in a burst.
+func TestLatePullReportsMissedFireTimes(t *testing.T) {
+	state := newFireState(context.Background(), &dto.Message{}, mustParse(t, "* * * * * *", "UTC"))
+
+	state.scheduledAt = time.Now().Add(-3 * time.Second)
+
+	fire := decodeFire(t, state.Next())
+
+	if fire.Missed != 2 || fire.Number != 3 {
+		t.Fatalf("got number %d, missed %d", fire.Number, fire.Missed)
+	}
+}
+
+func TestCloseWakesBlockedFire(t *testing.T) {
+	state := newFireState(context.Background(), &dto.Message{}, mustParse(t, "0 0 0 1 1 *", "UTC"))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		state.Close()
+	}()
+
+	result := state.Next()
+
+	if !result.IsError || !result.IsCancelled {
+		t.Fatal("Close must answer the blocked next as cancelled")
+	}
+}
@@ -0,0 +1,110 @@
+package cron_feature
+
+import (
+	"context"
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/features/cron/payloads"
+	"sconcur/internal/helpers"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var _ contracts.StateContract = (*fireState)(nil)
+
+// fireState streams the fire times of one cron schedule; each Next blocks until
+// the next one. The schedule is walked from the previous fire time (not from the
+// pull time), so a late pull reports the fire times it skipped. Implements
+// contracts.StateContract.
+type fireState struct {
+	// mutex serializes Next calls; Close does not take it, so it never waits for a
+	// Next blocked on the timer.
+	mutex     sync.Mutex
+	ctx       context.Context
+	message   *dto.Message
+	schedule  *Schedule
+	startTime time.Time
+	// scheduledAt is the latest delivered fire time (the start time before the first).
+	scheduledAt time.Time
+	number      int64
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newFireState(ctx context.Context, message *dto.Message, schedule *Schedule) *fireState {
+	startTime := time.Now()
+
+	return &fireState{
+		ctx:         ctx,
+		message:     message,
+		schedule:    schedule,
+		startTime:   startTime,
+		scheduledAt: startTime,
+		done:        make(chan struct{}),
+	}
+}
+
+func (s *fireState) Next() *dto.Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	scheduledAt := s.schedule.Next(s.scheduledAt)
+
+	if scheduledAt.IsZero() {
+		return dto.NewSuccessResult(s.message, "", helpers.CalcExecutionMs(s.startTime))
+	}
+
+	if wait := time.Until(scheduledAt); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-s.ctx.Done():
+			return dto.NewErrorResult(s.message, errFactory.ByErr("wait", context.Cause(s.ctx)))
+		case <-s.done:
+			return dto.NewCancelledResult(s.message, "schedule closed")
+		case <-timer.C:
+		}
+	}
+
+	now := time.Now()
+
+	// Skip to the latest fire time already due; the ones in between were missed.
+	var missed int64
+
+	for {
+		following := s.schedule.Next(scheduledAt)
+
+		if following.IsZero() || following.After(now) {
+			break
+		}
+
+		scheduledAt = following
+		missed++
+	}
+
+	s.scheduledAt = scheduledAt
+	s.number += missed + 1
+
+	serialized, err := msgpack.Marshal(payloads.Fire{
+		Number:        s.number,
+		Missed:        missed,
+		ScheduledAtMs: scheduledAt.UnixMilli(),
+		FiredAtMs:     now.UnixMilli(),
+	})
+
+	if err != nil {
+		return dto.NewErrorResult(s.message, errFactory.ByErr("marshal fire", err))
+	}
+
+	return dto.NewSuccessResultWithNext(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
+}
+
+// Close stops the schedule, waking a Next blocked on the timer. Nothing else is held.
+func (s *fireState) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
@@ -0,0 +1,23 @@
+// Package payloads holds the Go counterparts of the PHP Cron payload objects
+// (SConcur\Features\Cron\Payloads\*). The struct tags are the short keys emitted by
+// the PHP getData() methods.
+package payloads
+
+// CronPayload is the payload of a cron schedule: a six-field expression (seconds
+// first) evaluated in Timezone (an IANA name; empty = the process local zone).
+// PHP: SConcur\Features\Cron\Payloads\CronPayload.
+type CronPayload struct {
+	Expression string `json:"ex" msgpack:"ex"`
+	Timezone   string `json:"tz" msgpack:"tz"`
+}
+
+// Fire is the payload of one fire result. Number counts the schedule's fire times
+// from 1; Missed counts those skipped since the previous fire because PHP pulled
+// too late (they are never delivered in a burst).
+// PHP: SConcur\Features\Cron\Dto\CronFireDto.
+type Fire struct {
+	Number        int64 `json:"n"  msgpack:"n"`
+	Missed        int64 `json:"ms" msgpack:"ms"`
+	ScheduledAtMs int64 `json:"sa" msgpack:"sa"`
+	FiredAtMs     int64 `json:"fa" msgpack:"fa"`
+}
@@ -0,0 +1,257 @@
+package cron_feature
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleSearchYears bounds the search for the next fire time: an expression that
+// never matches (e.g. 30 February) yields no time instead of looping forever.
+const scheduleSearchYears = 5
+
+// Schedule is a parsed cron expression: six fields (seconds, minutes, hours, day of
+// month, month, day of week) evaluated in a time zone. Each field is a bitset of
+// the values it allows.
+type Schedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// domAny/dowAny mark an unrestricted day field (one starting with * or ?, so
+	// */2 too, as in Vixie cron): with both days restricted, a day matches when
+	// either field does (classic cron semantics).
+	domAny, dowAny bool
+	location       *time.Location
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondBounds = fieldBounds{name: "second", min: 0, max: 59}
+	minuteBounds = fieldBounds{name: "minute", min: 0, max: 59}
+	hourBounds   = fieldBounds{name: "hour", min: 0, max: 23}
+	domBounds    = fieldBounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = fieldBounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week accepts 0-7, both 0 and 7 being Sunday.
+	dowBounds = fieldBounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// ParseSchedule parses a six-field expression ("sec min hour dom month dow"); a
+// five-field one is accepted too and fires at second 0. An empty timezone means the
+// process local zone.
+func ParseSchedule(expression string, timezone string) (*Schedule, error) {
+	location := time.Local
+
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", timezone)
+		}
+
+		location = loaded
+	}
+
+	fields := strings.Fields(expression)
+
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("expected 6 fields (sec min hour dom month dow), got %d", len(fields))
+	}
+
+	schedule := &Schedule{
+		location: location,
+		domAny:   isUnrestricted(fields[3]),
+		dowAny:   isUnrestricted(fields[5]),
+	}
+
+	targets := []*uint64{
+		&schedule.second, &schedule.minute, &schedule.hour,
+		&schedule.dom, &schedule.month, &schedule.dow,
+	}
+
+	bounds := []fieldBounds{secondBounds, minuteBounds, hourBounds, domBounds, monthBounds, dowBounds}
+
+	for index, field := range fields {
+		bits, err := parseField(field, bounds[index])
+
+		if err != nil {
+			return nil, err
+		}
+
+		*targets[index] = bits
+	}
+
+	// Sunday may be written as 7: fold it onto 0.
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	return schedule, nil
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// isUnrestricted reports whether a day field leaves the other day field alone:
+// like Vixie cron, any field starting with a wildcard, stepped or not.
+func isUnrestricted(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// parseField parses a comma-separated list of "*", "?", "a", "a-b", each optionally
+// followed by "/step" (a bare "a/step" runs from a to the field maximum).
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, bounds.name)
+			}
+
+			step = parsed
+		}
+
+		var low, high int
+
+		switch {
+		case isWildcard(rangePart):
+			low, high = bounds.min, bounds.max
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+
+			var err error
+
+			if low, err = parseValue(lowPart, bounds); err != nil {
+				return 0, err
+			}
+
+			if high, err = parseValue(highPart, bounds); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := parseValue(rangePart, bounds)
+
+			if err != nil {
+				return 0, err
+			}
+
+			low, high = value, value
+
+			if hasStep {
+				high = bounds.max
+			}
+		}
+
+		if low > high {
+			return 0, fmt.Errorf("invalid range %q in %s field", rangePart, bounds.name)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(text string, bounds fieldBounds) (int, error) {
+	if value, ok := bounds.names[strings.ToLower(text)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(text)
+
+	if err != nil || value < bounds.min || value > bounds.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (%d-%d)", text, bounds.name, bounds.min, bounds.max)
+	}
+
+	return value, nil
+}
+
+// Next returns the first fire time strictly after the given time, or the zero time
+// when the expression matches nothing within scheduleSearchYears. Fields are
+// advanced from the largest down, resetting the smaller ones, in the schedule's
+// zone: a wall-clock time skipped by a DST change is normalized by time.Date.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.In(s.location).Truncate(time.Second).Add(time.Second)
+
+	yearLimit := t.Year() + scheduleSearchYears
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		t = t.Add(time.Second)
+
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
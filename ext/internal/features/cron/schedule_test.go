@@ -0,0 +1,119 @@
+package cron_feature
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, expression string, timezone string) *Schedule {
+	t.Helper()
+
+	schedule, err := ParseSchedule(expression, timezone)
+
+	if err != nil {
+		t.Fatalf("parse %q: %v", expression, err)
+	}
+
+	return schedule
+}
+
+func TestNextWalksTheSchedule(t *testing.T) {
+	from := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * * *", time.Date(2026, time.March, 14, 10, 7, 31, 0, time.UTC)},
+		{"*/15 * * * * *", time.Date(2026, time.March, 14, 10, 7, 45, 0, time.UTC)},
+		{"0 */10 * * * *", time.Date(2026, time.March, 14, 10, 10, 0, 0, time.UTC)},
+		{"0 0 3 * * *", time.Date(2026, time.March, 15, 3, 0, 0, 0, time.UTC)},
+		{"30 5 8-9 1 jan-mar *", time.Date(2027, time.January, 1, 8, 5, 30, 0, time.UTC)},
+		{"0 0 0 * * mon", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 * * 7", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 12 29 2 ?", time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC)},
+		// Five fields: fires at second 0.
+		{"15 10 * * *", time.Date(2026, time.March, 14, 10, 15, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range cases {
+		next := mustParse(t, testCase.expression, "UTC").Next(from)
+
+		if !next.Equal(testCase.expected) {
+			t.Errorf("%q: next = %v, want %v", testCase.expression, next, testCase.expected)
+		}
+	}
+}
+
+// TestRestrictedDaysMatchEither checks classic cron semantics: with both day
+// fields restricted, a day matching either one fires.
+func TestRestrictedDaysMatchEither(t *testing.T) {
+	schedule := mustParse(t, "0 0 0 15 * fri", "UTC")
+
+	// From Saturday 2026-03-14: Sunday the 15th (day of month), then Friday the 20th
+	// (day of week).
+	first := schedule.Next(time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC))
+	second := schedule.Next(first)
+
+	if !first.Equal(time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("first = %v", first)
+	}
+
+	if !second.Equal(time.Date(2026, time.March, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("second = %v", second)
+	}
+}
+
+// TestSteppedWildcardDayIsUnrestricted checks a day field like */2 counts as
+// unrestricted (Vixie cron): the other day field must match too, instead of either.
+func TestSteppedWildcardDayIsUnrestricted(t *testing.T) {
+	schedule := mustParse(t, "0 0 0 */2 * fri", "UTC")
+
+	// From Saturday 2026-03-14: Sunday the 15th is an odd day but not a Friday,
+	// Friday the 20th an even day; Friday the 27th is the first odd Friday.
+	next := schedule.Next(time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC))
+
+	if !next.Equal(time.Date(2026, time.March, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next = %v, want Friday the 27th", next)
+	}
+}
+
+func TestNextHonoursTheTimeZone(t *testing.T) {
+	schedule := mustParse(t, "0 0 9 * * *", "Asia/Tokyo")
+
+	next := schedule.Next(time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC))
+
+	// 09:00 in Tokyo (UTC+9) is midnight UTC.
+	if !next.Equal(time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next = %v", next.UTC())
+	}
+}
+
+func TestNeverMatchingExpressionYieldsZeroTime(t *testing.T) {
+	if next := mustParse(t, "0 0 0 30 2 *", "UTC").Next(time.Now()); !next.IsZero() {
+		t.Fatalf("expected no fire time, got %v", next)
+	}
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * * *",
+		"* * 24 * * *",
+		"* * * 0 * *",
+		"* * * * 13 *",
+		"* * * * * 8",
+		"*/0 * * * * *",
+		"5-1 * * * * *",
+		"* * * * foo *",
+	} {
+		if _, err := ParseSchedule(expression, "UTC"); err == nil {
+			t.Errorf("%q: expected a parse error", expression)
+		}
+	}
+
+	if _, err := ParseSchedule("* * * * * *", "Mars/Olympus"); err == nil {
+		t.Error("expected an unknown time zone error")
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"sconcur/internal/contracts"
+	"sconcur/internal/features/channel"
+	"sconcur/internal/features/cron"
 	"sconcur/internal/features/httpclient"
 	"sconcur/internal/features/httpserver"
 	"sconcur/internal/features/mongodb/connection"
 	"sconcur/internal/features/mongodb/features/collection"
+	"sconcur/internal/features/semaphore"
 	"sconcur/internal/features/sleeper"
 	"sconcur/internal/features/socketclient"
 	"sconcur/internal/features/socketserver"
 	"sconcur/internal/features/sql"
+	"sconcur/internal/features/ticker"
 	"sconcur/internal/features/wsclient"
 	"sconcur/internal/features/wsserver"
 	"sconcur/internal/types"
@@ -39,15 +43,31 @@ func DetectMessageHandler(method types.Method) (contracts.FeatureContract, error
 		return wsserver_feature.Get(), nil
 	case types.MethodWsClient:
 		return wsclient_feature.Get(), nil
+	case types.MethodTicker:
+		return ticker_feature.Get(), nil
+	case types.MethodCron:
+		return cron_feature.Get(), nil
+	case types.MethodChannel:
+		return channel_feature.Get(), nil
+	case types.MethodSemaphore:
+		return semaphore_feature.Get(), nil
 	default:
 		return nil, errors.New("unknown method: " + fmt.Sprint(method))
 	}
 }
 
 // Shutdown releases resources held by features (MongoDB clients and their
-// connection pools, the HTTP-client idle connections, the SQL connection pools).
+// connection pools, the HTTP-client idle connections and cookie jars, the SQL
+// connection pools).
 func Shutdown() {
 	connection.GetClients().DisconnectAll()
 	httpclient_feature.CloseIdleConnections()
+	httpclient_feature.CloseAllJars()
 	sql_feature.CloseAllPools()
 }
+
+// ReleaseRuntime drops what features keep for one runtime (its cookie jars) once
+// it is destroyed; the shared pools stay up for the other runtimes.
+func ReleaseRuntime(runtime int) {
+	httpclient_feature.CloseRuntimeJars(runtime)
+}
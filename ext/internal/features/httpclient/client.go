@@ -1,8 +1,9 @@
 package httpclient_feature
 
 import (
-	"crypto/tls"
+	"container/list"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"sync"
@@ -18,6 +19,18 @@ const (
 	defaultTLSHandshakeTimeout = 10 * time.Second
 )
 
+// HTTP versions a request can ask for (RequestParams.HttpVersion).
+const (
+	// httpVersion1 is HTTP/1.1 only, the default (an empty version too).
+	httpVersion1 = "1.1"
+	// httpVersionAuto negotiates HTTP/2 by ALPN over TLS and falls back to
+	// HTTP/1.1; cleartext requests stay on HTTP/1.1.
+	httpVersionAuto = "auto"
+	// httpVersionH2c speaks cleartext HTTP/2 with prior knowledge to http:// URLs
+	// (no Upgrade round-trip), and HTTP/2 over TLS to https:// ones.
+	httpVersionH2c = "h2c"
+)
+
 // errTooManyRedirects is returned by the redirect policy once the configured
 // maximum is exceeded. Surfaces to PHP as a network-class error.
 var errTooManyRedirects = errors.New("too many redirects")
@@ -34,22 +47,52 @@ type transportKey struct {
 	maxIdleConnsPerHost     int
 	idleConnTimeoutMs       int
 	tlsHandshakeTimeoutMs   int
+	httpVersion             string
+	tls                     tlsSettings
+	proxy                   proxySettings
+}
+
+// maxTransports bounds the cached transports. Inline CA PEM, pins and proxy
+// credentials are part of the key, so per-request settings would otherwise grow
+// the cache, its idle connections and the credentials it holds without end.
+const maxTransports = 64
+
+// cachedTransport is an entry of the transport LRU (transportsOrder).
+type cachedTransport struct {
+	key       transportKey
+	transport *http.Transport
 }
 
 var (
 	transportsMutex sync.Mutex
-	transportsCache = map[transportKey]*http.Transport{}
+	transportsCache = map[transportKey]*list.Element{}
+	// transportsOrder holds the cached transports, most recently used first.
+	transportsOrder = list.New()
 )
 
 // getTransport returns the shared transport for the given key, building it once.
 // Keeping transports per distinct config preserves keep-alive/pooling between
-// requests while still honoring per-request connect/header timeouts and TLS mode.
-func getTransport(key transportKey) *http.Transport {
+// requests while still honoring per-request connect/header timeouts, TLS settings,
+// proxy and HTTP version; an HTTP/2 transport multiplexes the requests to a host over one
+// connection. It fails when the TLS files of the key cannot be loaded; such a key
+// is not cached, so a fixed file is picked up by the next request.
+//
+// Past maxTransports the least recently used transport is dropped and its idle
+// connections closed; requests still running on it finish normally.
+func getTransport(key transportKey) (*http.Transport, error) {
 	transportsMutex.Lock()
 	defer transportsMutex.Unlock()
 
-	if transport, ok := transportsCache[key]; ok {
-		return transport
+	if element, ok := transportsCache[key]; ok {
+		transportsOrder.MoveToFront(element)
+
+		return element.Value.(*cachedTransport).transport, nil
+	}
+
+	tlsConfig, err := key.tls.config(key.verifyTls)
+
+	if err != nil {
+		return nil, err
 	}
 
 	dialer := &net.Dialer{
@@ -61,38 +104,81 @@ func getTransport(key transportKey) *http.Transport {
 	}
 
 	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
 		DialContext:           dialer.DialContext,
-		ForceAttemptHTTP2:     false, // v1 is HTTP/1.1; h2 is out of scope.
+		Protocols:             httpProtocols(key.httpVersion),
 		MaxIdleConns:          intOrDefault(key.maxIdleConns, defaultMaxIdleConns),
 		MaxIdleConnsPerHost:   intOrDefault(key.maxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
 		IdleConnTimeout:       msOrDefault(key.idleConnTimeoutMs, defaultIdleConnTimeout),
 		TLSHandshakeTimeout:   msOrDefault(key.tlsHandshakeTimeoutMs, defaultTLSHandshakeTimeout),
+		TLSClientConfig:       tlsConfig,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	key.proxy.apply(transport, dialer)
+
 	if key.responseHeaderTimeoutMs > 0 {
 		transport.ResponseHeaderTimeout = time.Duration(key.responseHeaderTimeoutMs) * time.Millisecond
 	}
 
-	if !key.verifyTls {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	transportsCache[key] = transportsOrder.PushFront(&cachedTransport{key: key, transport: transport})
+
+	if transportsOrder.Len() > maxTransports {
+		evicted := transportsOrder.Remove(transportsOrder.Back()).(*cachedTransport)
+
+		delete(transportsCache, evicted.key)
+		evicted.transport.CloseIdleConnections()
 	}
 
-	transportsCache[key] = transport
+	return transport, nil
+}
+
+// httpProtocols maps a validated HTTP version (see parseHttpVersion) to the
+// protocols a transport may speak.
+func httpProtocols(version string) *http.Protocols {
+	protocols := &http.Protocols{}
+
+	switch version {
+	case httpVersionAuto:
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	case httpVersionH2c:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		protocols.SetHTTP1(true)
+	}
 
-	return transport
+	return protocols
+}
+
+// parseHttpVersion validates the HTTP version of a request, an empty one being
+// HTTP/1.1, so both share a transport.
+func parseHttpVersion(version string) (string, error) {
+	switch version {
+	case "":
+		return httpVersion1, nil
+	case httpVersion1, httpVersionAuto, httpVersionH2c:
+		return version, nil
+	default:
+		return "", fmt.Errorf("unknown HTTP version %q", version)
+	}
 }
 
 // buildClient assembles the *http.Client for one request. The transport (and its
 // pool) is shared per transportKey; the redirect policy is per request. The
 // overall deadline is enforced via the request context (see feature.go), not
 // Client.Timeout, so it also covers reading the streamed body.
-func buildClient(payloadKey transportKey, followRedirects bool, maxRedirects int) *http.Client {
+func buildClient(payloadKey transportKey, followRedirects bool, maxRedirects int) (*http.Client, error) {
+	transport, err := getTransport(payloadKey)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return &http.Client{
-		Transport:     getTransport(payloadKey),
+		Transport:     proxyAuthTransport{transport},
 		CheckRedirect: redirectPolicy(followRedirects, maxRedirects),
-	}
+	}, nil
 }
 
 // redirectPolicy builds the http.Client CheckRedirect callback: stop following
@@ -136,7 +222,7 @@ func CloseIdleConnections() {
 	transportsMutex.Lock()
 	defer transportsMutex.Unlock()
 
-	for _, transport := range transportsCache {
-		transport.CloseIdleConnections()
+	for element := transportsOrder.Front(); element != nil; element = element.Next() {
+		element.Value.(*cachedTransport).transport.CloseIdleConnections()
 	}
 }
@@ -0,0 +1,510 @@
+package httpclient_feature
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sconcur/internal/dto"
+	"sconcur/internal/features/httpclient/payloads"
+	"sconcur/internal/helpers"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/net/publicsuffix"
+)
+
+// snapshotVersion is the format of an exported jar; an import of any other
+// version is rejected.
+const snapshotVersion = 1
+
+// minSweepAt is the record count below which the expired records are left to
+// the next export.
+const minSweepAt = 64
+
+// maxJarsPerRuntime bounds the open jars of one runtime, so a worker that opens
+// a jar per request and never closes it fails loudly instead of growing forever.
+const maxJarsPerRuntime = 1 << 12
+
+// jarKey names an open jar. The ids are per runtime (see package runtimes): ZTS
+// threads never share a jar, and a destroyed runtime takes its jars with it.
+type jarKey struct {
+	runtime int
+	id      string
+}
+
+// jars holds the open jars. A jar lives until JarClose or the end of its runtime,
+// so one session spans many requests (and flows) of the runtime.
+var (
+	jarsMutex sync.Mutex
+	jars      = map[jarKey]*sessionJar{}
+	jarCounts = map[int]int{}
+)
+
+// sessionJar is a named cookie jar with RFC 6265 semantics (net/http/cookiejar,
+// public-suffix aware). cookiejar.Jar cannot list what it holds, so every cookie
+// it keeps is also recorded with the URL that set it; an export replays the
+// records against the jar to keep only the live ones. A record is replaced by a
+// later cookie with the same key, and the expired ones are swept as the map
+// grows, so a long session holds no more records than live cookies (plus the
+// ones expired since the last sweep).
+type sessionJar struct {
+	jar *cookiejar.Jar
+
+	mutex   sync.Mutex
+	records map[cookieKey]cookieRecord
+	// sweepAt is the record count at which SetCookies next sweeps the expired
+	// records: twice the count left by the last sweep.
+	sweepAt int
+}
+
+// cookieKey identifies a cookie the way the jar does: a later cookie with the
+// same domain, path and name replaces it.
+type cookieKey struct {
+	domain string
+	path   string
+	name   string
+}
+
+// cookieRecord is a stored cookie plus the URL that set it. Expires is absolute
+// (a Max-Age is resolved when recorded), so the record survives a snapshot.
+type cookieRecord struct {
+	url    *url.URL
+	cookie http.Cookie
+}
+
+// jarSnapshot is the serialized content of a jar, handed to PHP as JSON.
+type jarSnapshot struct {
+	Version int              `json:"version"`
+	Cookies []snapshotCookie `json:"cookies"`
+}
+
+// snapshotCookie is one exported cookie. Expires is a Unix time in seconds, 0
+// for a session cookie.
+type snapshotCookie struct {
+	Url      string `json:"url"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Expires  int64  `json:"expires,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+var (
+	errUnknownJar  = errors.New("unknown cookie jar")
+	errTooManyJars = errors.New("too many open cookie jars")
+)
+
+func newSessionJar() *sessionJar {
+	// cookiejar.New only fails on options it never rejects.
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+
+	return &sessionJar{
+		jar:     jar,
+		records: map[cookieKey]cookieRecord{},
+		sweepAt: minSweepAt,
+	}
+}
+
+// findJar returns the open jar with the given id in the runtime.
+func findJar(runtime int, id string) (*sessionJar, error) {
+	jarsMutex.Lock()
+	defer jarsMutex.Unlock()
+
+	jar, ok := jars[jarKey{runtime: runtime, id: id}]
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownJar, id)
+	}
+
+	return jar, nil
+}
+
+// openJar returns the jar with the given id in the runtime, creating it unless
+// the runtime already holds maxJarsPerRuntime of them.
+func openJar(runtime int, id string) (*sessionJar, error) {
+	jarsMutex.Lock()
+	defer jarsMutex.Unlock()
+
+	key := jarKey{runtime: runtime, id: id}
+
+	if jar, ok := jars[key]; ok {
+		return jar, nil
+	}
+
+	if jarCounts[runtime] >= maxJarsPerRuntime {
+		return nil, fmt.Errorf("%w (%d)", errTooManyJars, maxJarsPerRuntime)
+	}
+
+	jar := newSessionJar()
+
+	jars[key] = jar
+	jarCounts[runtime]++
+
+	return jar, nil
+}
+
+func closeJar(runtime int, id string) {
+	jarsMutex.Lock()
+	defer jarsMutex.Unlock()
+
+	key := jarKey{runtime: runtime, id: id}
+
+	if _, ok := jars[key]; ok {
+		delete(jars, key)
+		jarCounts[runtime]--
+	}
+}
+
+// CloseRuntimeJars forgets the jars of a destroyed runtime.
+func CloseRuntimeJars(runtime int) {
+	jarsMutex.Lock()
+	defer jarsMutex.Unlock()
+
+	for key := range jars {
+		if key.runtime == runtime {
+			delete(jars, key)
+		}
+	}
+
+	delete(jarCounts, runtime)
+}
+
+// CloseAllJars forgets every jar: the process-wide destroy.
+func CloseAllJars() {
+	jarsMutex.Lock()
+	defer jarsMutex.Unlock()
+
+	clear(jars)
+	clear(jarCounts)
+}
+
+// Cookies implements http.CookieJar.
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar: it stores cookies in the jar and records
+// the ones it kept for an export. A deleting cookie (Max-Age < 0 or an Expires in
+// the past) drops its record, and a cookie the jar rejected (e.g. for a public
+// suffix) is not recorded.
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	origin := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	now := time.Now()
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	for _, cookie := range cookies {
+		key := recordKey(u, cookie)
+
+		if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && !cookie.Expires.After(now)) {
+			delete(j.records, key)
+
+			continue
+		}
+
+		stored := http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  cookie.Expires,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: cookie.SameSite,
+		}
+
+		if cookie.MaxAge > 0 {
+			stored.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+
+		record := cookieRecord{url: origin, cookie: stored}
+
+		if !j.holds(key, record) {
+			delete(j.records, key)
+
+			continue
+		}
+
+		j.records[key] = record
+	}
+
+	if len(j.records) >= j.sweepAt {
+		j.sweep(now)
+	}
+}
+
+// sweep drops the records of expired cookies. It runs when the records doubled
+// since the last sweep, so its cost spreads over the cookies set meanwhile.
+func (j *sessionJar) sweep(now time.Time) {
+	for key, record := range j.records {
+		if record.expired(now) {
+			delete(j.records, key)
+		}
+	}
+
+	j.sweepAt = max(2*len(j.records), minSweepAt)
+}
+
+func (r cookieRecord) expired(now time.Time) bool {
+	return !r.cookie.Expires.IsZero() && !r.cookie.Expires.After(now)
+}
+
+// export serializes the live cookies of the jar. A record counts as live when it
+// has not expired and the jar still returns it for its own URL; the others are
+// dropped.
+func (j *sessionJar) export() ([]byte, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	snapshot := jarSnapshot{Version: snapshotVersion, Cookies: []snapshotCookie{}}
+	now := time.Now()
+
+	for key, record := range j.records {
+		if record.expired(now) || !j.holds(key, record) {
+			delete(j.records, key)
+
+			continue
+		}
+
+		cookie := record.cookie
+
+		exported := snapshotCookie{
+			Url:      record.url.String(),
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: sameSiteName(cookie.SameSite),
+		}
+
+		if !cookie.Expires.IsZero() {
+			exported.Expires = cookie.Expires.Unix()
+		}
+
+		snapshot.Cookies = append(snapshot.Cookies, exported)
+	}
+
+	slices.SortFunc(snapshot.Cookies, func(a snapshotCookie, b snapshotCookie) int {
+		return cmp.Or(strings.Compare(a.Url, b.Url), strings.Compare(a.Name, b.Name))
+	})
+
+	return json.Marshal(snapshot)
+}
+
+// holds reports whether the jar still sends the recorded cookie to a request
+// within its scope.
+func (j *sessionJar) holds(key cookieKey, record cookieRecord) bool {
+	probe := &url.URL{Scheme: record.url.Scheme, Host: record.url.Host, Path: key.path}
+
+	if record.cookie.Secure {
+		probe.Scheme = "https"
+	}
+
+	for _, cookie := range j.jar.Cookies(probe) {
+		if cookie.Name == record.cookie.Name && cookie.Value == record.cookie.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// load merges a snapshot into the jar: every cookie is set again from the URL
+// that originally set it, so the jar applies the same domain and path rules.
+// Cookies that expired since the export are skipped. The whole snapshot is
+// checked first, so a bad entry leaves the jar as it was.
+func (j *sessionJar) load(data []byte) error {
+	var snapshot jarSnapshot
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	type loaded struct {
+		origin *url.URL
+		cookie *http.Cookie
+	}
+
+	cookies := make([]loaded, 0, len(snapshot.Cookies))
+	now := time.Now()
+
+	for _, exported := range snapshot.Cookies {
+		origin, err := url.Parse(exported.Url)
+
+		if err != nil || origin.Host == "" {
+			return fmt.Errorf("cookie %q: invalid url %q", exported.Name, exported.Url)
+		}
+
+		cookie := &http.Cookie{
+			Name:     exported.Name,
+			Value:    exported.Value,
+			Domain:   exported.Domain,
+			Path:     exported.Path,
+			Secure:   exported.Secure,
+			HttpOnly: exported.HttpOnly,
+			SameSite: sameSiteOf(exported.SameSite),
+		}
+
+		if exported.Expires > 0 {
+			cookie.Expires = time.Unix(exported.Expires, 0)
+
+			if !cookie.Expires.After(now) {
+				continue
+			}
+		}
+
+		cookies = append(cookies, loaded{origin: origin, cookie: cookie})
+	}
+
+	for _, entry := range cookies {
+		j.SetCookies(entry.origin, []*http.Cookie{entry.cookie})
+	}
+
+	return nil
+}
+
+// recordKey mirrors the jar's own identity of a cookie: a Domain attribute makes
+// a domain cookie, otherwise the cookie is host-only; a missing Path falls back
+// to the directory of the request path (RFC 6265 §5.1.4).
+func recordKey(u *url.URL, cookie *http.Cookie) cookieKey {
+	domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+
+	if domain == "" {
+		domain = "host:" + strings.ToLower(u.Hostname())
+	}
+
+	path := cookie.Path
+
+	if !strings.HasPrefix(path, "/") {
+		path = defaultCookiePath(u.Path)
+	}
+
+	return cookieKey{domain: domain, path: path, name: cookie.Name}
+}
+
+func defaultCookiePath(path string) string {
+	index := strings.LastIndex(path, "/")
+
+	if index <= 0 {
+		return "/"
+	}
+
+	return path[:index]
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return ""
+	}
+}
+
+func sameSiteOf(name string) http.SameSite {
+	switch name {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteDefaultMode
+	}
+}
+
+// handleJar runs a jar command: open (create the jar unless it exists), export
+// (answer with the snapshot JSON), import (merge a snapshot, opening the jar if
+// needed) or close (forget the jar). The jar ids are those of the task's runtime.
+func (f *HttpClientFeature) handleJar(task *tasks.Task, raw msgpack.RawMessage, command types.HttpClientCommand) {
+	message := task.GetMessage()
+	startTime := time.Now()
+
+	var payload payloads.JarParams
+
+	if err := msgpack.Unmarshal(raw, &payload); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse jar params", err)))
+
+		return
+	}
+
+	if payload.Id == "" {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("empty cookie jar id")))
+
+		return
+	}
+
+	runtime := helpers.RuntimeOfTaskKey(message.TaskKey)
+	result := ""
+
+	switch command {
+	case types.HttpClientJarOpen:
+		if _, err := openJar(runtime, payload.Id); err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("open cookie jar", err)))
+
+			return
+		}
+	case types.HttpClientJarExport:
+		jar, err := findJar(runtime, payload.Id)
+
+		if err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("export cookie jar", err)))
+
+			return
+		}
+
+		snapshot, err := jar.export()
+
+		if err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("export cookie jar", err)))
+
+			return
+		}
+
+		result = string(snapshot)
+	case types.HttpClientJarImport:
+		jar, err := openJar(runtime, payload.Id)
+
+		if err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("import cookie jar", err)))
+
+			return
+		}
+
+		if err := jar.load([]byte(payload.Snapshot)); err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("import cookie jar", err)))
+
+			return
+		}
+	case types.HttpClientJarClose:
+		closeJar(runtime, payload.Id)
+	}
+
+	task.AddResult(dto.NewSuccessResult(message, result, helpers.CalcExecutionMs(startTime)))
+}
@@ -0,0 +1,315 @@
+package httpclient_feature
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/httpclient/payloads"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+)
+
+// sendJarCommand runs one jar command through the feature, as PHP sends it.
+func sendJarCommand(t *testing.T, command types.HttpClientCommand, params payloads.JarParams) *dto.Result {
+	t.Helper()
+
+	data := envelopePayload(t, command, params)
+
+	results := make(chan *dto.Result, 1)
+	message := &dto.Message{Method: types.MethodHttpClient, FlowKey: "f-jar", TaskKey: "t-jar", Payload: data}
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	return <-results
+}
+
+// sessionServer logs in on /login (sets a cookie, then redirects to /me) and
+// echoes the Cookie header on /me.
+func sessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/login":
+			http.SetCookie(writer, &http.Cookie{Name: "sid", Value: "s1", Path: "/", MaxAge: 3600})
+			http.Redirect(writer, request, "/me", http.StatusFound)
+		case "/logout":
+			http.SetCookie(writer, &http.Cookie{Name: "sid", Path: "/", MaxAge: -1})
+		default:
+			_, _ = writer.Write([]byte(request.Header.Get("Cookie")))
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getWithJar(t *testing.T, jar *sessionJar, url string) string {
+	t.Helper()
+
+	client := testClient(t, transportKey{verifyTls: true})
+	client.Jar = jar
+
+	resp, err := client.Get(url)
+
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return string(body)
+}
+
+// TestJarCarriesCookiesAcrossRedirectsAndRequests checks a cookie set by a
+// response reaches the redirect that follows it and the later requests of the
+// session.
+func TestJarCarriesCookiesAcrossRedirectsAndRequests(t *testing.T) {
+	server := sessionServer(t)
+
+	if result := sendJarCommand(t, types.HttpClientJarOpen, payloads.JarParams{Id: "carry"}); result.IsError {
+		t.Fatalf("open: %s", result.Payload)
+	}
+
+	t.Cleanup(func() { sendJarCommand(t, types.HttpClientJarClose, payloads.JarParams{Id: "carry"}) })
+
+	jar, err := findJar(0, "carry")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body := getWithJar(t, jar, server.URL+"/login"); body != "sid=s1" {
+		t.Fatalf("redirect hop got cookies %q", body)
+	}
+
+	if body := getWithJar(t, jar, server.URL+"/me"); body != "sid=s1" {
+		t.Fatalf("next request got cookies %q", body)
+	}
+
+	getWithJar(t, jar, server.URL+"/logout")
+
+	if body := getWithJar(t, jar, server.URL+"/me"); body != "" {
+		t.Fatalf("deleted cookie still sent: %q", body)
+	}
+}
+
+// TestJarSnapshotRoundTrips exports a session, closes its jar and imports the
+// snapshot into a new one, which then sends the same cookies.
+func TestJarSnapshotRoundTrips(t *testing.T) {
+	server := sessionServer(t)
+
+	sendJarCommand(t, types.HttpClientJarOpen, payloads.JarParams{Id: "source"})
+
+	jar, _ := findJar(0, "source")
+
+	getWithJar(t, jar, server.URL+"/login")
+
+	exported := sendJarCommand(t, types.HttpClientJarExport, payloads.JarParams{Id: "source"})
+
+	if exported.IsError || !strings.Contains(exported.Payload, `"name":"sid"`) {
+		t.Fatalf("export: %s", exported.Payload)
+	}
+
+	sendJarCommand(t, types.HttpClientJarClose, payloads.JarParams{Id: "source"})
+
+	if _, err := findJar(0, "source"); err == nil {
+		t.Fatal("closed jar is still open")
+	}
+
+	if result := sendJarCommand(t, types.HttpClientJarImport, payloads.JarParams{Id: "restored", Snapshot: exported.Payload}); result.IsError {
+		t.Fatalf("import: %s", result.Payload)
+	}
+
+	t.Cleanup(func() { sendJarCommand(t, types.HttpClientJarClose, payloads.JarParams{Id: "restored"}) })
+
+	restored, _ := findJar(0, "restored")
+
+	if body := getWithJar(t, restored, server.URL+"/me"); body != "sid=s1" {
+		t.Fatalf("restored jar sent %q", body)
+	}
+
+	// The snapshot of the restored jar is the same session.
+	if again := sendJarCommand(t, types.HttpClientJarExport, payloads.JarParams{Id: "restored"}); again.Payload != exported.Payload {
+		t.Fatalf("re-export differs:\n%s\n%s", again.Payload, exported.Payload)
+	}
+}
+
+// TestJarExportSkipsRejectedAndReplacedCookies checks only what the jar really
+// holds is exported: a cookie for a public suffix is refused by the jar, and a
+// replaced value is exported once, with the new value.
+func TestJarExportSkipsRejectedAndReplacedCookies(t *testing.T) {
+	jar := newSessionJar()
+
+	origin, _ := url.Parse("https://shop.example.co.uk/cart/add")
+
+	jar.SetCookies(origin, []*http.Cookie{
+		{Name: "tracker", Value: "x", Domain: "co.uk"},
+		{Name: "cart", Value: "1"},
+	})
+	jar.SetCookies(origin, []*http.Cookie{{Name: "cart", Value: "2"}})
+
+	snapshot, err := jar.export()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"version":1,"cookies":[{"url":"https://shop.example.co.uk/cart/add","name":"cart","value":"2"}]}`
+
+	if string(snapshot) != want {
+		t.Fatalf("snapshot:\n%s\nwant:\n%s", snapshot, want)
+	}
+}
+
+// TestJarRecordsStayBounded checks a long session does not keep a record per
+// Set-Cookie: a rejected cookie is never recorded, a replaced one overwrites its
+// record, and the expired ones are swept as new cookies come in.
+func TestJarRecordsStayBounded(t *testing.T) {
+	jar := newSessionJar()
+
+	origin, _ := url.Parse("https://shop.example.co.uk/cart/add")
+
+	jar.SetCookies(origin, []*http.Cookie{{Name: "tracker", Value: "x", Domain: "co.uk"}})
+
+	for index := range 100 {
+		jar.SetCookies(origin, []*http.Cookie{
+			{Name: "cart", Value: strconv.Itoa(index)},
+			{Name: "ad-" + strconv.Itoa(index), Value: "1", Expires: time.Now().Add(50 * time.Millisecond)},
+		})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	for index := range 100 {
+		jar.SetCookies(origin, []*http.Cookie{{Name: "visit-" + strconv.Itoa(index), Value: "1"}})
+	}
+
+	jar.mutex.Lock()
+	records := len(jar.records)
+	jar.mutex.Unlock()
+
+	// The cart and the 100 visits are live; the expired ads went with a sweep.
+	if records != 101 {
+		t.Fatalf("%d records left, want the 101 live cookies", records)
+	}
+}
+
+func TestJarImportRejectsBadSnapshots(t *testing.T) {
+	for _, snapshot := range []string{
+		"not json",
+		`{"version":2,"cookies":[]}`,
+		`{"version":1,"cookies":[{"url":"/relative","name":"a","value":"b"}]}`,
+	} {
+		result := sendJarCommand(t, types.HttpClientJarImport, payloads.JarParams{Id: "bad", Snapshot: snapshot})
+
+		if !result.IsError {
+			t.Fatalf("snapshot %q was accepted", snapshot)
+		}
+
+		assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+	}
+
+	sendJarCommand(t, types.HttpClientJarClose, payloads.JarParams{Id: "bad"})
+}
+
+// TestJarsBelongToTheirRuntime checks the same id names another jar in another
+// runtime, and a destroyed runtime takes its jars with it.
+func TestJarsBelongToTheirRuntime(t *testing.T) {
+	t.Cleanup(func() {
+		CloseRuntimeJars(7)
+		CloseRuntimeJars(8)
+	})
+
+	first, _ := openJar(7, "session")
+	second, _ := openJar(8, "session")
+
+	if first == second {
+		t.Fatal("two runtimes share a jar")
+	}
+
+	CloseRuntimeJars(7)
+
+	if _, err := findJar(7, "session"); err == nil {
+		t.Fatal("the destroyed runtime's jar is still open")
+	}
+
+	if found, err := findJar(8, "session"); err != nil || found != second {
+		t.Fatal("the other runtime lost its jar")
+	}
+}
+
+func TestOpenJarIsBoundedPerRuntime(t *testing.T) {
+	t.Cleanup(func() { CloseRuntimeJars(9) })
+
+	for index := range maxJarsPerRuntime {
+		if _, err := openJar(9, strconv.Itoa(index)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := openJar(9, "one-too-many"); err == nil {
+		t.Fatal("expected the jar limit to be enforced")
+	}
+
+	if _, err := openJar(9, "0"); err != nil {
+		t.Fatalf("an open jar must still be reachable: %v", err)
+	}
+
+	closeJar(9, "0")
+
+	if _, err := openJar(9, "one-too-many"); err != nil {
+		t.Fatalf("a closed jar must free its slot: %v", err)
+	}
+}
+
+// TestJarImportIsAllOrNothing checks a snapshot with a bad entry after a good one
+// leaves the jar as it was.
+func TestJarImportIsAllOrNothing(t *testing.T) {
+	jar := newSessionJar()
+
+	snapshot := `{"version":1,"cookies":[` +
+		`{"url":"https://example.test/","name":"good","value":"1"},` +
+		`{"url":"no host","name":"bad","value":"2"}]}`
+
+	if err := jar.load([]byte(snapshot)); err == nil {
+		t.Fatal("expected the bad entry to be rejected")
+	}
+
+	origin, _ := url.Parse("https://example.test/")
+
+	if cookies := jar.Cookies(origin); len(cookies) != 0 {
+		t.Fatalf("a rejected snapshot was half-imported: %v", cookies)
+	}
+}
+
+// TestRequestWithUnknownJarIsRejected checks a request naming a jar that was never
+// opened (or was closed) fails as a request error instead of running without it.
+func TestRequestWithUnknownJarIsRejected(t *testing.T) {
+	data := envelopePayload(t, types.HttpClientRequest, payloads.RequestParams{Method: "GET", Url: "http://127.0.0.1", JarId: "missing"})
+
+	results := make(chan *dto.Result, 1)
+	message := &dto.Message{Method: types.MethodHttpClient, FlowKey: "f", TaskKey: "t", Payload: data}
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	result := <-results
+
+	if !result.IsError {
+		t.Fatal("expected a request error")
+	}
+
+	assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+}
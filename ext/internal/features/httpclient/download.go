@@ -27,12 +27,13 @@ const (
 // downloadMeta is the single result of a download: the response status, the raw
 // response headers (as the server returned them) and the number of bytes written to
 // the file (the authoritative size — io.Copy ground truth, independent of any
-// Content-Length header).
+// Content-Length header), plus how many times the request was sent.
 // PHP: decoded in SConcur\Features\HttpClient\HttpClient::download.
 type downloadMeta struct {
-	Status  int                 `msgpack:"st"`
-	Headers map[string][]string `msgpack:"hd"`
-	Written int64               `msgpack:"n"`
+	Status   int                 `msgpack:"st"`
+	Headers  map[string][]string `msgpack:"hd"`
+	Written  int64               `msgpack:"n"`
+	Attempts int                 `msgpack:"at"`
 }
 
 // downloadModeToFlags maps a DownloadFileMode to os.OpenFile flags — the single
@@ -68,15 +69,15 @@ func (f *HttpClientFeature) handleDownload(
 	flags, ok := downloadModeToFlags(payload.SinkMode)
 
 	if !ok {
-		task.AddResult(dto.NewErrorResult(message, requestErrorPayload(errFactory.ByText("invalid sink mode"))))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("invalid sink mode")))
 
 		return
 	}
 
-	resp, err := client.Do(request)
+	resp, attempts, err := newRetryPolicy(payload).do(client, request)
 
 	if err != nil {
-		task.AddResult(dto.NewErrorResult(message, networkErrorPayload(err.Error())))
+		task.AddResult(dto.NewErrorResult(message, sendError("download", err)))
 
 		return
 	}
@@ -86,7 +87,7 @@ func (f *HttpClientFeature) handleDownload(
 	// Non-2xx: leave the file untouched (don't create/truncate). PHP raises a
 	// DownloadException carrying the status.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		task.AddResult(downloadResult(message, resp, 0, startTime))
+		task.AddResult(downloadResult(message, resp, 0, attempts, startTime))
 
 		return
 	}
@@ -122,7 +123,7 @@ func (f *HttpClientFeature) handleDownload(
 			_ = os.Remove(payload.SinkPath)
 		}
 
-		task.AddResult(dto.NewErrorResult(message, networkErrorPayload(copyErr.Error())))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByNetwork("read response body", copyErr)))
 
 		return
 	}
@@ -133,16 +134,17 @@ func (f *HttpClientFeature) handleDownload(
 		return
 	}
 
-	task.AddResult(downloadResult(message, resp, written, startTime))
+	task.AddResult(downloadResult(message, resp, written, attempts, startTime))
 }
 
 // downloadResult builds the status+headers+size result emitted once a download
 // finishes (or a non-2xx response is seen, with written = 0).
-func downloadResult(message *dto.Message, resp *http.Response, written int64, startTime time.Time) *dto.Result {
+func downloadResult(message *dto.Message, resp *http.Response, written int64, attempts int, startTime time.Time) *dto.Result {
 	serialized, err := msgpack.Marshal(downloadMeta{
-		Status:  resp.StatusCode,
-		Headers: resp.Header,
-		Written: written,
+		Status:   resp.StatusCode,
+		Headers:  resp.Header,
+		Written:  written,
+		Attempts: attempts,
 	})
 
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"sconcur/internal/dto"
 	"sconcur/internal/errs"
 	"sconcur/internal/features/httpclient/payloads"
+	"sconcur/internal/helpers"
 	"sconcur/internal/states"
 	"sconcur/internal/tasks"
 	"sconcur/internal/types"
@@ -25,15 +26,6 @@ var instance *HttpClientFeature
 
 var errFactory = errs.NewErrorsFactory("httpClient")
 
-// Error-class markers prefixed onto an error payload so the PHP side can map it to
-// the right PSR-18 exception. A request that never left (bad URL/method) is a
-// request error; everything network-level (connect/DNS/timeout/redirect/read) is a
-// network error. The default (no marker) is a generic client error.
-const (
-	networkErrorMarker = "net"
-	requestErrorMarker = "req"
-)
-
 // HttpClientFeature handles httpRequest commands: it builds the *http.Request,
 // applies the hard execution deadline and registers a streaming responseState
 // that performs the request and streams the response body to PHP. Singleton.
@@ -53,7 +45,7 @@ func (f *HttpClientFeature) Handle(task *tasks.Task) {
 	var envelope payloads.Envelope
 
 	if err := msgpack.Unmarshal(message.Payload, &envelope); err != nil {
-		task.AddResult(dto.NewErrorResult(message, requestErrorPayload(errFactory.ByErr("parse envelope", err))))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse envelope", err)))
 
 		return
 	}
@@ -65,6 +57,8 @@ func (f *HttpClientFeature) Handle(task *tasks.Task) {
 		f.handleUpload(task, envelope.Params, false)
 	case types.HttpClientUploadEnd:
 		f.handleUpload(task, envelope.Params, true)
+	case types.HttpClientJarOpen, types.HttpClientJarExport, types.HttpClientJarImport, types.HttpClientJarClose:
+		f.handleJar(task, envelope.Params, envelope.Command)
 	default:
 		task.AddResult(dto.NewErrorResult(message, errFactory.ByText("unknown command")))
 	}
@@ -79,11 +73,91 @@ func (f *HttpClientFeature) handleRequest(task *tasks.Task, raw msgpack.RawMessa
 	var payload payloads.RequestParams
 
 	if err := msgpack.Unmarshal(raw, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, requestErrorPayload(errFactory.ByErr("parse request params", err))))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+		return
+	}
+
+	// Checked for the buffered and the streamed body (upload.go) alike.
+	if err := states.CheckPrefetchDepth(payload.PrefetchDepth); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
 
 		return
 	}
 
+	if err := checkRetryPolicy(&payload); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+		return
+	}
+
+	httpVersion, err := parseHttpVersion(payload.HttpVersion)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+		return
+	}
+
+	tlsOptions, err := newTlsSettings(&payload)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+		return
+	}
+
+	proxyOptions, err := newProxySettings(&payload)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+		return
+	}
+
+	var jar *sessionJar
+
+	if payload.JarId != "" {
+		if jar, err = findJar(helpers.RuntimeOfTaskKey(message.TaskKey), payload.JarId); err != nil {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse request params", err)))
+
+			return
+		}
+	}
+
+	// A streamed body is an io.Pipe with no GetBody, so net/http cannot replay it on
+	// a redirect ("cannot retry request with body"). Disable redirect following for
+	// streamed uploads so a 3xx is returned as-is instead of failing opaquely.
+	followRedirects := payload.FollowRedirects && !payload.StreamBody
+
+	client, err := buildClient(
+		transportKey{
+			connectTimeoutMs:        payload.ConnectTimeoutMs,
+			responseHeaderTimeoutMs: payload.ResponseHeaderTimeoutMs,
+			verifyTls:               payload.VerifyTls,
+			maxIdleConns:            payload.MaxIdleConns,
+			maxIdleConnsPerHost:     payload.MaxIdleConnsPerHost,
+			idleConnTimeoutMs:       payload.IdleConnTimeoutMs,
+			tlsHandshakeTimeoutMs:   payload.TLSHandshakeTimeoutMs,
+			httpVersion:             httpVersion,
+			tls:                     tlsOptions,
+			proxy:                   proxyOptions,
+		},
+		followRedirects,
+		payload.MaxRedirects,
+	)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("configure transport", err)))
+
+		return
+	}
+
+	// The jar is consulted on every hop, so redirects carry the session cookies.
+	if jar != nil {
+		client.Jar = jar
+	}
+
 	// A hard limit on the whole operation (connect + send + reading the entire
 	// body), as required of every feature. Derived from the task context so a flow
 	// stop still cancels it. 0 disables the extra deadline (task context only).
@@ -120,7 +194,7 @@ func (f *HttpClientFeature) handleRequest(task *tasks.Task, raw msgpack.RawMessa
 			_ = pipeWriter.Close()
 		}
 
-		task.AddResult(dto.NewErrorResult(message, requestErrorPayload(errFactory.ByErr("build request", err))))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("build request", err)))
 
 		return
 	}
@@ -132,25 +206,6 @@ func (f *HttpClientFeature) handleRequest(task *tasks.Task, raw msgpack.RawMessa
 		request.ContentLength = -1
 	}
 
-	// A streamed body is an io.Pipe with no GetBody, so net/http cannot replay it on
-	// a redirect ("cannot retry request with body"). Disable redirect following for
-	// streamed uploads so a 3xx is returned as-is instead of failing opaquely.
-	followRedirects := payload.FollowRedirects && !payload.StreamBody
-
-	client := buildClient(
-		transportKey{
-			connectTimeoutMs:        payload.ConnectTimeoutMs,
-			responseHeaderTimeoutMs: payload.ResponseHeaderTimeoutMs,
-			verifyTls:               payload.VerifyTls,
-			maxIdleConns:            payload.MaxIdleConns,
-			maxIdleConnsPerHost:     payload.MaxIdleConnsPerHost,
-			idleConnTimeoutMs:       payload.IdleConnTimeoutMs,
-			tlsHandshakeTimeoutMs:   payload.TLSHandshakeTimeoutMs,
-		},
-		followRedirects,
-		payload.MaxRedirects,
-	)
-
 	chunkSize := chunkSizeOrDefault(payload.ChunkSize)
 
 	// Download to file: the response body is copied straight into a file on the Go
@@ -160,7 +215,7 @@ func (f *HttpClientFeature) handleRequest(task *tasks.Task, raw msgpack.RawMessa
 		if payload.StreamBody {
 			task.AddResult(dto.NewErrorResult(
 				message,
-				requestErrorPayload(errFactory.ByText("sink download is not supported with a streamed request body")),
+				errFactory.ByText("sink download is not supported with a streamed request body"),
 			))
 
 			return
@@ -178,8 +233,9 @@ func (f *HttpClientFeature) handleRequest(task *tasks.Task, raw msgpack.RawMessa
 	}
 
 	state := newResponseState(message, client, request, chunkSize, payload.MaxResponseBody)
+	state.retry = newRetryPolicy(&payload)
 
-	result, err := states.Get().Start(ctx, message.TaskKey, state)
+	result, err := states.Get().Start(ctx, message.TaskKey, states.Prefetch(state, message, payload.PrefetchDepth))
 
 	if err != nil {
 		state.Close()
@@ -221,11 +277,3 @@ func chunkSizeOrDefault(chunkSize int) int {
 
 	return chunkSize
 }
-
-func networkErrorPayload(text string) string {
-	return networkErrorMarker + ": " + text
-}
-
-func requestErrorPayload(text string) string {
-	return requestErrorMarker + ": " + text
-}
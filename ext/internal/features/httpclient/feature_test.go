@@ -3,12 +3,14 @@ package httpclient_feature
 import (
 	"context"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features/httpclient/payloads"
 	"sconcur/internal/states"
 	"sconcur/internal/tasks"
@@ -37,10 +39,55 @@ func envelopePayload(t *testing.T, command types.HttpClientCommand, params any)
 	return data
 }
 
-// TestHandleRejectsInvalidRequestWithReqMarker checks a request that cannot even
+// testTransport returns the shared transport of key, failing the test when it
+// cannot be built.
+func testTransport(t *testing.T, key transportKey) *http.Transport {
+	t.Helper()
+
+	transport, err := getTransport(key)
+
+	if err != nil {
+		t.Fatalf("build transport: %v", err)
+	}
+
+	return transport
+}
+
+// testClient builds the per-request client of key with redirects followed.
+func testClient(t *testing.T, key transportKey) *http.Client {
+	t.Helper()
+
+	client, err := buildClient(key, true, 10)
+
+	if err != nil {
+		t.Fatalf("build client: %v", err)
+	}
+
+	return client
+}
+
+// handleRequestPayload runs one Request command through the feature and returns
+// its first result.
+func handleRequestPayload(t *testing.T, params payloads.RequestParams) *dto.Result {
+	t.Helper()
+
+	message := &dto.Message{
+		Method:  types.MethodHttpClient,
+		FlowKey: "f",
+		TaskKey: "t",
+		Payload: envelopePayload(t, types.HttpClientRequest, params),
+	}
+	results := make(chan *dto.Result, 1)
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	return <-results
+}
+
+// TestHandleRejectsInvalidRequestAsValidationError checks a request that cannot even
 // be built (invalid HTTP method) surfaces as a request-class error, so PHP raises
 // a PSR-18 RequestException.
-func TestHandleRejectsInvalidRequestWithReqMarker(t *testing.T) {
+func TestHandleRejectsInvalidRequestAsValidationError(t *testing.T) {
 	data := envelopePayload(t, types.HttpClientRequest, payloads.RequestParams{Method: "BAD METHOD", Url: "http://127.0.0.1"})
 
 	message := &dto.Message{Method: types.MethodHttpClient, FlowKey: "f", TaskKey: "t", Payload: data}
@@ -55,8 +102,26 @@ func TestHandleRejectsInvalidRequestWithReqMarker(t *testing.T) {
 		t.Fatal("expected a request error")
 	}
 
-	if !strings.HasPrefix(result.Payload, requestErrorMarker+":") {
-		t.Fatalf("payload = %q, want a %q-marked error", result.Payload, requestErrorMarker)
+	assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+}
+
+// TestHandleRejectsOversizedPrefetchDepth checks a read-ahead depth past the
+// bound is refused before anything is allocated for it.
+func TestHandleRejectsOversizedPrefetchDepth(t *testing.T) {
+	for _, streamBody := range []bool{false, true} {
+		result := handleRequestPayload(t, payloads.RequestParams{
+			Method:        http.MethodGet,
+			Url:           "http://127.0.0.1",
+			StreamBody:    streamBody,
+			RequestId:     "oversized-prefetch",
+			PrefetchDepth: math.MaxInt64,
+		})
+
+		if !result.IsError {
+			t.Fatalf("stream body %t: expected a validation error", streamBody)
+		}
+
+		assertErrorCategory(t, result.Payload, errs.CategoryValidation)
 	}
 }
 
@@ -95,7 +160,7 @@ func TestApplyHeadersIncludingHost(t *testing.T) {
 
 	applyHeaders(request, map[string][]string{"X-Echo": {"hi"}, "Host": {"example.test"}})
 
-	state := newResponseState(&dto.Message{}, buildClient(transportKey{verifyTls: true}, true, 10), request, 1024, 0)
+	state := newResponseState(&dto.Message{}, testClient(t, transportKey{verifyTls: true}), request, 1024, 0)
 	defer state.Close()
 
 	if result := state.Next(); result.IsError {
@@ -130,7 +195,7 @@ func TestResponseStateFollowsRedirects(t *testing.T) {
 		t.Fatalf("build request: %v", err)
 	}
 
-	state := newResponseState(&dto.Message{}, buildClient(transportKey{verifyTls: true}, true, 10), request, 1024, 0)
+	state := newResponseState(&dto.Message{}, testClient(t, transportKey{verifyTls: true}), request, 1024, 0)
 	defer state.Close()
 
 	result := state.Next()
@@ -280,9 +345,7 @@ func TestStreamedUploadWriteErrorIsNetworkMarked(t *testing.T) {
 		t.Fatal("expected a network-class upload error")
 	}
 
-	if !strings.HasPrefix(result.Payload, networkErrorMarker+":") {
-		t.Fatalf("payload = %q, want a %q-marked error", result.Payload, networkErrorMarker)
-	}
+	assertErrorCategory(t, result.Payload, errs.CategoryNetwork)
 
 	states.Get().DeleteState(taskKey)
 }
@@ -423,16 +486,30 @@ func TestStreamedUploadDuplicateRequestIdIsRejected(t *testing.T) {
 func TestGetTransportReusesPerKey(t *testing.T) {
 	key := transportKey{verifyTls: true, connectTimeoutMs: 1234}
 
-	first := getTransport(key)
-	second := getTransport(key)
+	first := testTransport(t, key)
+	second := testTransport(t, key)
 
 	if first != second {
 		t.Fatal("the same transportKey must reuse the cached transport")
 	}
 
-	other := getTransport(transportKey{verifyTls: false, connectTimeoutMs: 1234})
+	other := testTransport(t, transportKey{verifyTls: false, connectTimeoutMs: 1234})
 
 	if first == other {
 		t.Fatal("a different transportKey must use a different transport")
 	}
 }
+
+func assertErrorCategory(t *testing.T, payload string, want errs.Category) {
+	t.Helper()
+
+	details, err := errs.Decode(payload)
+
+	if err != nil {
+		t.Fatalf("decode error payload %q: %v", payload, err)
+	}
+
+	if details.Category != want {
+		t.Fatalf("category = %q, want %q (%s)", details.Category, want, details.Message)
+	}
+}
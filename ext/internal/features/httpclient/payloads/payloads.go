@@ -5,7 +5,7 @@
 //
 // Every message is a command envelope (cm/p) under MethodHttpClient — mirrors the
 // MongoDB feature: cm selects the sub-operation, p carries that command's
-// parameters (decoded into RequestParams / UploadParams).
+// parameters (decoded into RequestParams / UploadParams / JarParams).
 package payloads
 
 import (
@@ -51,8 +51,51 @@ type RequestParams struct {
 	// ChunkSize is the granularity of reading the response body (inline first chunk
 	// and each streamed chunk).
 	ChunkSize int `json:"cs" msgpack:"cs"`
+	// PrefetchDepth is how many body chunks are read ahead in the background while
+	// PHP consumes the current one (0 = none).
+	PrefetchDepth int `json:"pf" msgpack:"pf"`
 	// VerifyTls toggles TLS certificate verification (off for self-signed in dev).
 	VerifyTls bool `json:"vt" msgpack:"vt"`
+	// HttpVersion selects the protocol: "1.1" (HTTP/1.1 only, also when empty),
+	// "auto" (HTTP/2 negotiated by ALPN over TLS, HTTP/1.1 otherwise) or "h2c"
+	// (cleartext HTTP/2 with prior knowledge for http:// URLs).
+	// PHP: SConcur\Features\HttpClient\HttpVersion.
+	HttpVersion string `json:"hv" msgpack:"hv"`
+	// Client TLS (see tls.go), part of the transport identity: TlsCaFiles and
+	// TlsCaPem replace the system roots, TlsCertFile/TlsKeyFile present a client
+	// certificate (mTLS), TlsServerName overrides SNI and the verified host name,
+	// TlsMinVersion ("1.0".."1.3", empty = Go default) is the lowest version
+	// accepted, and TlsPins (base64 SHA-256 of a certificate's SPKI, optionally
+	// "sha256/"-prefixed) require one certificate of the peer chain to match.
+	// PHP: SConcur\Features\HttpClient\TlsOptions.
+	TlsCaFiles    []string `json:"tcf" msgpack:"tcf"`
+	TlsCaPem      string   `json:"tcp" msgpack:"tcp"`
+	TlsCertFile   string   `json:"tcr" msgpack:"tcr"`
+	TlsKeyFile    string   `json:"tky" msgpack:"tky"`
+	TlsServerName string   `json:"tsn" msgpack:"tsn"`
+	TlsMinVersion string   `json:"tmv" msgpack:"tmv"`
+	TlsPins       []string `json:"tpn" msgpack:"tpn"`
+	// Proxy (see proxy.go), part of the transport identity: ProxyUrl is an
+	// http://, https://, socks5:// (target resolved locally) or socks5h:// (resolved
+	// by the proxy) URL, optionally with credentials; ProxyUser/ProxyPassword
+	// override them. Empty keeps the HTTP_PROXY/HTTPS_PROXY environment. NoProxy
+	// lists the hosts that go direct ("*", IPs, CIDRs, domains, optional :port).
+	// PHP: SConcur\Features\HttpClient\ProxyOptions.
+	ProxyUrl      string   `json:"px"  msgpack:"px"`
+	ProxyUser     string   `json:"pxu" msgpack:"pxu"`
+	ProxyPassword string   `json:"pxp" msgpack:"pxp"`
+	NoProxy       []string `json:"npx" msgpack:"npx"`
+	// Retry policy (see retry.go): RetryMaxAttempts counts the first attempt, so 0
+	// or 1 sends once; more than maxRetryAttempts is rejected. Network errors and RetryStatuses are retried after an
+	// exponential backoff from RetryBackoffMs, capped at RetryMaxBackoffMs, or after
+	// the Retry-After of the response. Only idempotent methods are retried unless
+	// RetryNonIdempotent; a streamed body is never retried.
+	// PHP: SConcur\Features\HttpClient\RetryPolicy.
+	RetryMaxAttempts   int   `json:"rma" msgpack:"rma"`
+	RetryBackoffMs     int   `json:"rbo" msgpack:"rbo"`
+	RetryMaxBackoffMs  int   `json:"rmb" msgpack:"rmb"`
+	RetryStatuses      []int `json:"rst" msgpack:"rst"`
+	RetryNonIdempotent bool  `json:"rni" msgpack:"rni"`
 	// Connection-pool tuning, supplied by the PHP side (its defaults mirror Go's).
 	MaxIdleConns          int `json:"mic" msgpack:"mic"`
 	MaxIdleConnsPerHost   int `json:"mih" msgpack:"mih"`
@@ -67,6 +110,10 @@ type RequestParams struct {
 	SinkMode                string `json:"sm"  msgpack:"sm"`
 	SinkPerm                int    `json:"spm" msgpack:"spm"`
 	DownloadBufferSizeBytes int    `json:"dbs" msgpack:"dbs"`
+	// JarId names an open cookie jar (see cookiejar.go): its cookies are sent with
+	// the request and every redirect hop, and Set-Cookie responses update it.
+	// Empty means no jar.
+	JarId string `json:"jr" msgpack:"jr"`
 }
 
 // UploadParams is the `p` content of an UploadChunk/UploadEnd command: the request
@@ -78,14 +125,27 @@ type UploadParams struct {
 	Body      string `json:"b" msgpack:"b"`
 }
 
+// JarParams is the `p` content of a JarOpen/JarExport/JarImport/JarClose command:
+// the jar (Id) and, for an import, the snapshot to load (the JSON a JarExport
+// returned).
+// PHP: SConcur\Features\HttpClient\Payloads\JarPayloadParameters.
+type JarParams struct {
+	Id       string `json:"id" msgpack:"id"`
+	Snapshot string `json:"sn" msgpack:"sn"`
+}
+
 // ResponseMeta is the first result the client emits for a request: the response
 // status, headers and the inline first chunk of the body. Subsequent results
 // (pulled via next) are raw body chunks, not this struct. ContentLength is the
-// response Content-Length, or -1 when unknown (e.g. chunked transfer).
+// response Content-Length, or -1 when unknown (e.g. chunked transfer). Proto is
+// the negotiated protocol ("HTTP/1.1", "HTTP/2.0"); Attempts is how many times the
+// request was sent (1 without retries).
 // PHP: decoded in SConcur\Features\HttpClient\HttpClient::sendRequest.
 type ResponseMeta struct {
 	Status        int                 `json:"st" msgpack:"st"`
 	Headers       map[string][]string `json:"hd" msgpack:"hd"`
 	Body          string              `json:"b" msgpack:"b"`
 	ContentLength int64               `json:"cl" msgpack:"cl"`
+	Proto         string              `json:"pr" msgpack:"pr"`
+	Attempts      int                 `json:"at" msgpack:"at"`
 }
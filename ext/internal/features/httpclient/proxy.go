@@ -0,0 +1,312 @@
+package httpclient_feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sconcur/internal/features/httpclient/payloads"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+// Proxy schemes a request can use (RequestParams.ProxyUrl). socks5 resolves the
+// target host locally and hands the proxy an IP; socks5h lets the proxy resolve it.
+const (
+	proxySchemeHttp    = "http"
+	proxySchemeHttps   = "https"
+	proxySchemeSocks5  = "socks5"
+	proxySchemeSocks5h = "socks5h"
+)
+
+// proxyError marks a failure of the proxy hop: the proxy could not be reached,
+// refused the credentials, or could not open the tunnel to the target. Reported
+// to PHP with errs.CodeProxy, apart from failures of the target itself.
+type proxyError struct {
+	err error
+}
+
+func (e *proxyError) Error() string {
+	return "proxy: " + e.err.Error()
+}
+
+func (e *proxyError) Unwrap() error {
+	return e.err
+}
+
+// lookupIPAddr resolves a target host for socks5; replaced in tests.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// proxySettings is the proxy part of a transportKey: the proxy URL with its
+// credentials (empty = the HTTP_PROXY/HTTPS_PROXY environment) and the hosts that
+// bypass it, comma-joined so the key stays comparable.
+type proxySettings struct {
+	url     string
+	noProxy string
+}
+
+// newProxySettings validates the proxy of a request and folds it into the
+// transport identity. ProxyUser/ProxyPassword take precedence over credentials
+// in the URL, so a password needs no URL escaping.
+func newProxySettings(payload *payloads.RequestParams) (proxySettings, error) {
+	noProxy := make([]string, 0, len(payload.NoProxy))
+
+	for _, entry := range payload.NoProxy {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			noProxy = append(noProxy, entry)
+		}
+	}
+
+	settings := proxySettings{noProxy: strings.Join(noProxy, ",")}
+
+	if payload.ProxyUrl == "" {
+		return settings, nil
+	}
+
+	proxyUrl, err := url.Parse(payload.ProxyUrl)
+
+	if err != nil {
+		return proxySettings{}, fmt.Errorf("invalid proxy url: %w", err)
+	}
+
+	switch proxyUrl.Scheme {
+	case proxySchemeHttp, proxySchemeHttps, proxySchemeSocks5, proxySchemeSocks5h:
+	default:
+		return proxySettings{}, fmt.Errorf("unsupported proxy scheme %q", proxyUrl.Scheme)
+	}
+
+	if proxyUrl.Hostname() == "" {
+		return proxySettings{}, fmt.Errorf("proxy url %q has no host", payload.ProxyUrl)
+	}
+
+	if payload.ProxyUser != "" {
+		proxyUrl.User = url.UserPassword(payload.ProxyUser, payload.ProxyPassword)
+	}
+
+	settings.url = proxyUrl.String()
+
+	return settings, nil
+}
+
+// apply routes the transport through the proxy. HTTP(S) proxies go through
+// Transport.Proxy (absolute-form requests, CONNECT for https targets); SOCKS5 is
+// dialed here, so the DNS mode of the scheme is honoured and every failure of the
+// proxy hop is marked. Without a URL the environment proxy is kept, still
+// filtered by the bypass list.
+func (s proxySettings) apply(transport *http.Transport, dialer *net.Dialer) {
+	if s.url == "" {
+		transport.Proxy = s.bypass(http.ProxyFromEnvironment)
+
+		return
+	}
+
+	proxyUrl, _ := url.Parse(s.url)
+
+	if proxyUrl.Scheme == proxySchemeSocks5 || proxyUrl.Scheme == proxySchemeSocks5h {
+		transport.Proxy = nil
+		transport.DialContext = s.socksDialer(proxyUrl, dialer)
+
+		return
+	}
+
+	transport.Proxy = s.bypass(func(*http.Request) (*url.URL, error) {
+		return proxyUrl, nil
+	})
+
+	// A CONNECT refused by the proxy (407, 502, …) is a proxy failure; net/http
+	// would only report the status text.
+	transport.OnProxyConnectResponse = func(_ context.Context, _ *url.URL, _ *http.Request, resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return &proxyError{err: fmt.Errorf("CONNECT answered %s", resp.Status)}
+		}
+
+		return nil
+	}
+}
+
+// bypass wraps a proxy selector so the hosts of the bypass list go direct.
+func (s proxySettings) bypass(selector func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	if s.noProxy == "" {
+		return selector
+	}
+
+	return func(request *http.Request) (*url.URL, error) {
+		port := request.URL.Port()
+
+		switch {
+		case port != "":
+		case request.URL.Scheme == "https":
+			port = "443"
+		default:
+			port = "80"
+		}
+
+		if s.bypasses(request.URL.Hostname(), port) {
+			return nil, nil
+		}
+
+		return selector(request)
+	}
+}
+
+// socksDialer dials the targets through the SOCKS5 proxy, or directly for the
+// bypassed hosts. With socks5 the target is resolved here first.
+func (s proxySettings) socksDialer(proxyUrl *url.URL, dialer *net.Dialer) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	var auth *proxy.Auth
+
+	if proxyUrl.User != nil {
+		password, _ := proxyUrl.User.Password()
+
+		auth = &proxy.Auth{User: proxyUrl.User.Username(), Password: password}
+	}
+
+	// SOCKS5 only fails on a nil forward dialer.
+	socks, _ := proxy.SOCKS5("tcp", proxyUrl.Host, auth, dialer)
+	contextDialer := socks.(proxy.ContextDialer)
+	resolveLocally := proxyUrl.Scheme == proxySchemeSocks5
+
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if s.bypasses(host, port) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		if !resolveLocally || net.ParseIP(host) != nil {
+			return dialSocks(ctx, contextDialer, network, address)
+		}
+
+		addresses, err := lookupIPAddr(ctx, host)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Like a direct dial, try every address of the host until one connects.
+		for _, resolved := range addresses {
+			var conn net.Conn
+
+			conn, err = dialSocks(ctx, contextDialer, network, net.JoinHostPort(resolved.IP.String(), port))
+
+			if err == nil || ctx.Err() != nil {
+				return conn, err
+			}
+		}
+
+		return nil, err
+	}
+}
+
+// dialSocks opens a connection to address through the SOCKS5 proxy, a failure
+// being one of the proxy hop.
+func dialSocks(ctx context.Context, dialer proxy.ContextDialer, network string, address string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, network, address)
+
+	if err != nil {
+		return nil, &proxyError{err: err}
+	}
+
+	return conn, nil
+}
+
+// proxyAuthTransport is the transport of a request: it reports a 407 answered
+// by an HTTP proxy to a plain-HTTP request (sent to the proxy in absolute form,
+// no CONNECT) as a proxy failure, which net/http would return as the target's own
+// response. A 407 to a CONNECT is caught by OnProxyConnectResponse.
+type proxyAuthTransport struct {
+	*http.Transport
+}
+
+func (t proxyAuthTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	resp, err := t.Transport.RoundTrip(request)
+
+	if err != nil || resp.StatusCode != http.StatusProxyAuthRequired || request.URL.Scheme != "http" || t.Proxy == nil {
+		return resp, err
+	}
+
+	if proxyUrl, _ := t.Proxy(request); proxyUrl == nil {
+		return resp, err
+	}
+
+	discard(resp)
+
+	return nil, &proxyError{err: fmt.Errorf("proxy answered %s", resp.Status)}
+}
+
+// bypasses reports whether host (on port) is on the bypass
+// list. An entry is "*" (every host), an IP, a CIDR range, or a domain matching
+// itself and its subdomains (a leading dot is ignored); any entry may end with
+// ":port" to apply to that port only.
+func (s proxySettings) bypasses(host string, port string) bool {
+	if s.noProxy == "" {
+		return false
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(s.noProxy, ",") {
+		if entry == "*" {
+			return true
+		}
+
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil && !strings.Contains(entry, "/") {
+			if entryPort != port {
+				continue
+			}
+
+			entry = entryHost
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		if entryIp := net.ParseIP(entry); entryIp != nil {
+			if ip != nil && entryIp.Equal(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		domain := strings.TrimPrefix(entry, ".")
+
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isProxyFailure reports whether err happened on the proxy hop: a SOCKS5 or
+// CONNECT failure marked here, or net/http's "proxyconnect" error for a proxy that
+// could not be dialed or failed its TLS handshake.
+func isProxyFailure(err error) bool {
+	var marked *proxyError
+	var opError *net.OpError
+
+	return errors.As(err, &marked) || (errors.As(err, &opError) && opError.Op == "proxyconnect")
+}
+
+// sendError is the payload of a failed round-trip: a proxy failure is reported
+// as such, anything else as a network failure of the target.
+func sendError(text string, err error) string {
+	if isProxyFailure(err) {
+		return errFactory.ByProxy(text, err)
+	}
+
+	return errFactory.ByNetwork(text, err)
+}
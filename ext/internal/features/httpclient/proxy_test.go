@@ -0,0 +1,512 @@
+package httpclient_feature
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"sconcur/internal/errs"
+	"sconcur/internal/features/httpclient/payloads"
+)
+
+// upstreamHandler is the target behind the proxies.
+var upstreamHandler = http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+	_, _ = writer.Write([]byte("upstream"))
+})
+
+// tunnel copies both ways between two connections until either side closes.
+func tunnel(a net.Conn, b net.Conn) {
+	go func() {
+		_, _ = io.Copy(a, b)
+		_ = a.Close()
+	}()
+
+	_, _ = io.Copy(b, a)
+	_ = b.Close()
+}
+
+// httpProxy is a stand-in forward proxy: an absolute-form request is answered by
+// the proxy itself ("proxied <host> <Proxy-Authorization>"), a CONNECT is
+// tunnelled to its target. With credentials set, a request without them gets 407.
+func httpProxy(t *testing.T, credentials string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		auth := request.Header.Get("Proxy-Authorization")
+
+		if credentials != "" && auth != "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)) {
+			writer.WriteHeader(http.StatusProxyAuthRequired)
+
+			return
+		}
+
+		if request.Method != http.MethodConnect {
+			_, _ = writer.Write([]byte("proxied " + request.Host + " " + auth))
+
+			return
+		}
+
+		target, err := net.Dial("tcp", request.Host)
+
+		if err != nil {
+			writer.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+
+		client, _, _ := writer.(http.Hijacker).Hijack()
+
+		tunnel(client, target)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// socksProxy is a stand-in SOCKS5 proxy (RFC 1928, CONNECT only). It records the
+// target of every request as the client sent it, and resolves the names of hosts
+// (here, not by DNS). With credentials set it requires RFC 1929 auth.
+type socksProxy struct {
+	listener    net.Listener
+	credentials [2]string
+	hosts       map[string]string
+
+	mutex   sync.Mutex
+	targets []string
+}
+
+func newSocksProxy(t *testing.T, user string, password string, hosts map[string]string) *socksProxy {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := &socksProxy{listener: listener, credentials: [2]string{user, password}, hosts: hosts}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go proxy.serve(conn)
+		}
+	}()
+
+	return proxy
+}
+
+func (p *socksProxy) url(scheme string) string {
+	return scheme + "://" + p.listener.Addr().String()
+}
+
+func (p *socksProxy) lastTarget() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.targets) == 0 {
+		return ""
+	}
+
+	return p.targets[len(p.targets)-1]
+}
+
+func (p *socksProxy) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// Greeting: version, methods.
+	header := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+
+	_, _ = io.ReadFull(conn, make([]byte, header[1]))
+
+	if p.credentials[0] == "" {
+		_, _ = conn.Write([]byte{5, 0})
+	} else {
+		_, _ = conn.Write([]byte{5, 2})
+
+		if !p.authenticate(conn) {
+			return
+		}
+	}
+
+	// Request: version, command, reserved, address type, address, port.
+	request := make([]byte, 4)
+
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+
+	var host string
+
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		_, _ = io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		name := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+
+	port := make([]byte, 2)
+	_, _ = io.ReadFull(conn, port)
+
+	p.mutex.Lock()
+	p.targets = append(p.targets, host)
+	p.mutex.Unlock()
+
+	if resolved, ok := p.hosts[host]; ok {
+		host = resolved
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+
+	if err != nil {
+		// Host unreachable.
+		_, _ = conn.Write([]byte{5, 4, 0, 1, 0, 0, 0, 0, 0, 0})
+
+		return
+	}
+
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	tunnel(conn, target)
+}
+
+// authenticate runs the RFC 1929 username/password sub-negotiation.
+func (p *socksProxy) authenticate(conn net.Conn) bool {
+	read := func() string {
+		length := make([]byte, 1)
+		_, _ = io.ReadFull(conn, length)
+		value := make([]byte, length[0])
+		_, _ = io.ReadFull(conn, value)
+
+		return string(value)
+	}
+
+	_, _ = io.ReadFull(conn, make([]byte, 1))
+
+	if read() != p.credentials[0] || read() != p.credentials[1] {
+		_, _ = conn.Write([]byte{1, 1})
+
+		return false
+	}
+
+	_, _ = conn.Write([]byte{1, 0})
+
+	return true
+}
+
+// proxyKey builds the transport key of requests through the proxy of params.
+func proxyKey(t *testing.T, params payloads.RequestParams) transportKey {
+	t.Helper()
+
+	settings, err := newProxySettings(&params)
+
+	if err != nil {
+		t.Fatalf("proxy settings: %v", err)
+	}
+
+	return transportKey{verifyTls: params.VerifyTls, proxy: settings}
+}
+
+// proxyGet sends one GET through the transport of key and returns the body.
+func proxyGet(t *testing.T, key transportKey, target string) (string, error) {
+	t.Helper()
+
+	resp, err := testClient(t, key).Get(target)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+// assertErrorCode checks the code PHP would get for a failed round-trip.
+func assertErrorCode(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected a %s error", want)
+	}
+
+	details, decodeErr := errs.Decode(sendError("send request", err))
+
+	if decodeErr != nil {
+		t.Fatal(decodeErr)
+	}
+
+	if details.Code != want {
+		t.Fatalf("code %q (%s), want %q", details.Code, details.Message, want)
+	}
+}
+
+func TestHttpProxyForwardsPlainRequestsWithCredentials(t *testing.T) {
+	proxy := httpProxy(t, "")
+
+	key := proxyKey(t, payloads.RequestParams{
+		ProxyUrl:      "http://ignored:ignored@" + proxy.Listener.Addr().String(),
+		ProxyUser:     "crawler",
+		ProxyPassword: "p@ss:word",
+	})
+
+	body, err := proxyGet(t, key, "http://catalog.example/items")
+
+	want := "proxied catalog.example Basic " + base64.StdEncoding.EncodeToString([]byte("crawler:p@ss:word"))
+
+	if err != nil || body != want {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+}
+
+func TestHttpProxyTunnelsHttps(t *testing.T) {
+	upstream := httptest.NewTLSServer(upstreamHandler)
+	defer upstream.Close()
+
+	proxy := httpProxy(t, "crawler:secret")
+
+	key := proxyKey(t, payloads.RequestParams{ProxyUrl: "http://crawler:secret@" + proxy.Listener.Addr().String()})
+
+	if body, err := proxyGet(t, key, upstream.URL); err != nil || body != "upstream" {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+}
+
+// TestProxyFailuresAreReportedApart checks the failures of the proxy hop carry
+// the proxy code, while a failure of the target behind a working proxy does not.
+func TestProxyFailuresAreReportedApart(t *testing.T) {
+	upstream := httptest.NewTLSServer(upstreamHandler)
+	defer upstream.Close()
+
+	plain := httptest.NewServer(upstreamHandler)
+	defer plain.Close()
+
+	proxy := httpProxy(t, "crawler:secret")
+	proxyAddress := proxy.Listener.Addr().String()
+
+	// CONNECT refused: wrong credentials.
+	_, err := proxyGet(t, proxyKey(t, payloads.RequestParams{ProxyUrl: "http://crawler:wrong@" + proxyAddress}), upstream.URL)
+	assertErrorCode(t, err, errs.CodeProxy)
+
+	// Wrong credentials for a plain-HTTP target: the proxy's 407 is not the
+	// target's answer, and trying again would be refused again.
+	_, err = proxyGet(t, proxyKey(t, payloads.RequestParams{ProxyUrl: "http://crawler:wrong@" + proxyAddress}), plain.URL)
+	assertErrorCode(t, err, errs.CodeProxy)
+
+	if details, _ := errs.Decode(sendError("send request", err)); details.Retryable {
+		t.Fatalf("refused proxy credentials must not be retryable: %+v", details)
+	}
+
+	// The proxy is not listening.
+	closed := httptest.NewServer(upstreamHandler)
+	closed.Close()
+
+	_, err = proxyGet(t, proxyKey(t, payloads.RequestParams{ProxyUrl: closed.URL}), upstream.URL)
+	assertErrorCode(t, err, errs.CodeProxy)
+
+	// The tunnel is up, but the target does not speak TLS: a target failure.
+	target := "https://" + plain.Listener.Addr().String()
+
+	_, err = proxyGet(t, proxyKey(t, payloads.RequestParams{ProxyUrl: "http://crawler:secret@" + proxyAddress}), target)
+
+	if err == nil || isProxyFailure(err) {
+		t.Fatalf("a target failure reported as a proxy one: %v", err)
+	}
+}
+
+func TestNoProxyGoesDirect(t *testing.T) {
+	upstream := httptest.NewServer(upstreamHandler)
+	defer upstream.Close()
+
+	proxy := httpProxy(t, "")
+	port := upstream.Listener.Addr().(*net.TCPAddr).Port
+
+	for _, entry := range []string{"127.0.0.1", "127.0.0.0/8", "127.0.0.1:" + strconv.Itoa(port), "*"} {
+		key := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.URL, NoProxy: []string{"other.example", entry}})
+
+		if body, err := proxyGet(t, key, upstream.URL); err != nil || body != "upstream" {
+			t.Fatalf("%s: body %q, err %v", entry, body, err)
+		}
+	}
+
+	key := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.URL, NoProxy: []string{"127.0.0.1:1"}})
+
+	if body, _ := proxyGet(t, key, upstream.URL); body == "upstream" {
+		t.Fatal("an entry for another port must not bypass the proxy")
+	}
+}
+
+func TestNoProxyMatching(t *testing.T) {
+	settings := proxySettings{url: "http://proxy:3128", noProxy: "example.com,.internal,10.0.0.0/8,::1,api.test:8443"}
+
+	cases := []struct {
+		host   string
+		port   string
+		bypass bool
+	}{
+		{"example.com", "443", true},
+		{"cdn.example.com", "443", true},
+		{"notexample.com", "443", false},
+		{"billing.internal", "80", true},
+		{"10.1.2.3", "80", true},
+		{"11.1.2.3", "80", false},
+		{"::1", "80", true},
+		{"api.test", "8443", true},
+		{"api.test", "443", false},
+	}
+
+	for _, c := range cases {
+		if got := settings.bypasses(c.host, c.port); got != c.bypass {
+			t.Fatalf("%s:%s: bypass %v, want %v", c.host, c.port, got, c.bypass)
+		}
+	}
+}
+
+// TestSocks5hLetsTheProxyResolve checks socks5h hands the proxy the host name,
+// so a name only the proxy knows still works.
+func TestSocks5hLetsTheProxyResolve(t *testing.T) {
+	upstream := httptest.NewServer(upstreamHandler)
+	defer upstream.Close()
+
+	proxy := newSocksProxy(t, "", "", map[string]string{"upstream.test": "127.0.0.1"})
+	target := "http://upstream.test:" + strconv.Itoa(upstream.Listener.Addr().(*net.TCPAddr).Port)
+
+	if body, err := proxyGet(t, proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.url("socks5h")}), target); err != nil || body != "upstream" {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+
+	if proxy.lastTarget() != "upstream.test" {
+		t.Fatalf("the proxy got %q, want the host name", proxy.lastTarget())
+	}
+}
+
+// TestSocks5ResolvesLocally checks socks5 hands the proxy an address resolved
+// here; a name only the proxy knows is a DNS failure of the target, not of the
+// proxy.
+func TestSocks5ResolvesLocally(t *testing.T) {
+	upstream := httptest.NewServer(upstreamHandler)
+	defer upstream.Close()
+
+	proxy := newSocksProxy(t, "", "", nil)
+	port := strconv.Itoa(upstream.Listener.Addr().(*net.TCPAddr).Port)
+	key := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.url("socks5")})
+
+	if body, err := proxyGet(t, key, "http://localhost:"+port); err != nil || body != "upstream" {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+
+	if net.ParseIP(proxy.lastTarget()) == nil {
+		t.Fatalf("the proxy got %q, want an address", proxy.lastTarget())
+	}
+
+	_, err := proxyGet(t, key, "http://upstream.invalid:"+port)
+	assertErrorCode(t, err, errs.CodeDnsFailure)
+}
+
+// TestSocks5TriesEveryResolvedAddress checks socks5 moves on to the next address
+// of the target when the proxy cannot reach one.
+func TestSocks5TriesEveryResolvedAddress(t *testing.T) {
+	upstream := httptest.NewServer(upstreamHandler)
+	defer upstream.Close()
+
+	lookupIPAddr = func(context.Context, string) ([]net.IPAddr, error) {
+		// Nothing listens on 127.0.0.2: the proxy answers host unreachable.
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.2")}, {IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+
+	t.Cleanup(func() { lookupIPAddr = net.DefaultResolver.LookupIPAddr })
+
+	proxy := newSocksProxy(t, "", "", nil)
+	port := strconv.Itoa(upstream.Listener.Addr().(*net.TCPAddr).Port)
+	key := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.url("socks5")})
+
+	if body, err := proxyGet(t, key, "http://upstream.example:"+port); err != nil || body != "upstream" {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+
+	if proxy.lastTarget() != "127.0.0.1" {
+		t.Fatalf("the proxy last got %q, want the second address", proxy.lastTarget())
+	}
+}
+
+func TestSocks5Credentials(t *testing.T) {
+	upstream := httptest.NewServer(upstreamHandler)
+	defer upstream.Close()
+
+	proxy := newSocksProxy(t, "crawler", "secret", nil)
+
+	accepted := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.url("socks5"), ProxyUser: "crawler", ProxyPassword: "secret"})
+
+	if body, err := proxyGet(t, accepted, upstream.URL); err != nil || body != "upstream" {
+		t.Fatalf("body %q, err %v", body, err)
+	}
+
+	refused := proxyKey(t, payloads.RequestParams{ProxyUrl: proxy.url("socks5"), ProxyUser: "crawler", ProxyPassword: "wrong"})
+
+	_, err := proxyGet(t, refused, upstream.URL)
+	assertErrorCode(t, err, errs.CodeProxy)
+
+	// The proxy cannot reach the target: still the proxy hop.
+	closed := httptest.NewServer(upstreamHandler)
+	closed.Close()
+
+	_, err = proxyGet(t, accepted, closed.URL)
+	assertErrorCode(t, err, errs.CodeProxy)
+}
+
+func TestProxySettingsShareTransport(t *testing.T) {
+	a := proxyKey(t, payloads.RequestParams{ProxyUrl: "socks5h://127.0.0.1:1080", NoProxy: []string{" Example.com "}})
+	b := proxyKey(t, payloads.RequestParams{ProxyUrl: "socks5h://127.0.0.1:1080", NoProxy: []string{"example.com"}})
+	c := proxyKey(t, payloads.RequestParams{ProxyUrl: "socks5://127.0.0.1:1080", NoProxy: []string{"example.com"}})
+
+	if testTransport(t, a) != testTransport(t, b) {
+		t.Fatal("the same proxy must share a transport")
+	}
+
+	if testTransport(t, a) == testTransport(t, c) {
+		t.Fatal("a different proxy must not share a transport")
+	}
+}
+
+func TestInvalidProxyIsARequestError(t *testing.T) {
+	for _, proxyUrl := range []string{"ftp://proxy:21", "http://", "://bad"} {
+		result := handleRequestPayload(t, payloads.RequestParams{Method: http.MethodGet, Url: "http://127.0.0.1", ProxyUrl: proxyUrl})
+
+		if !result.IsError {
+			t.Fatalf("%s: expected a request error", proxyUrl)
+		}
+
+		assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"sconcur/internal/contracts"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features/httpclient/payloads"
 	"sconcur/internal/helpers"
 	"sync"
@@ -18,7 +19,7 @@ import (
 var _ contracts.StateContract = (*responseState)(nil)
 
 // responseBodyTooLargeMessage is the error payload returned when the response body
-// exceeds maxResponseBody mid-stream. A plain client error (no net/req marker).
+// exceeds maxResponseBody mid-stream. Validation-class, not a network error.
 const responseBodyTooLargeMessage = "response body too large"
 
 // errResponseBodyTooLarge is returned by the limiting reader once the response
@@ -42,6 +43,10 @@ type responseState struct {
 	resp            *http.Response
 	bodyReader      io.Reader
 	requested       bool
+	// retry resends the request on a retryable failure (nil: sent once); attempts
+	// is how many times it was sent.
+	retry    *retryPolicy
+	attempts int
 	// session is set in deferred (streamed-upload) mode: client.Do already runs in
 	// the background, so the first Next waits on its result instead of issuing it.
 	session *uploadSession
@@ -112,13 +117,14 @@ func (s *responseState) sendAndReadMeta() *dto.Result {
 		<-s.session.resultReady
 
 		resp, err = s.session.result.resp, s.session.result.err
+		s.attempts = 1
 	} else {
-		resp, err = s.client.Do(s.request)
+		resp, s.attempts, err = s.retry.do(s.client, s.request)
 	}
 
 	if err != nil {
 		// Connection/DNS/timeout/redirect failures are network-class (PSR-18).
-		return dto.NewErrorResult(s.message, networkErrorPayload(err.Error()))
+		return dto.NewErrorResult(s.message, sendError("send request", err))
 	}
 
 	s.resp = resp
@@ -139,6 +145,8 @@ func (s *responseState) sendAndReadMeta() *dto.Result {
 		Headers:       resp.Header,
 		Body:          string(chunk),
 		ContentLength: resp.ContentLength,
+		Proto:         resp.Proto,
+		Attempts:      s.attempts,
 	}
 
 	serialized, err := msgpack.Marshal(meta)
@@ -252,12 +260,12 @@ func (r *maxBytesReader) Read(buffer []byte) (int, error) {
 }
 
 // readErrorMessage maps a body read error to the payload PHP receives: a stable
-// marker for the over-limit case, the raw error otherwise.
+// body_too_large envelope for the over-limit case, a network-class error otherwise.
 func readErrorMessage(err error) string {
 	if errors.Is(err, errResponseBodyTooLarge) {
-		return responseBodyTooLargeMessage
+		return errs.Make(errs.CategoryValidation, errs.CodeBodyTooLarge, responseBodyTooLargeMessage)
 	}
 
 	// A failure reading the body after the connection succeeded is network-class.
-	return networkErrorPayload(err.Error())
+	return errFactory.ByNetwork("read response body", err)
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features/httpclient/payloads"
 
 	"github.com/vmihailenco/msgpack/v5"
@@ -24,7 +25,7 @@ func newTestState(t *testing.T, url string, chunkSize int, maxResponseBody int64
 		t.Fatalf("build request: %v", err)
 	}
 
-	client := buildClient(transportKey{verifyTls: true}, true, 10)
+	client := testClient(t, transportKey{verifyTls: true})
 
 	return newResponseState(&dto.Message{}, client, request, chunkSize, maxResponseBody)
 }
@@ -140,8 +141,10 @@ func TestResponseStateEnforcesMaxResponseBody(t *testing.T) {
 		result := state.Next()
 
 		if result.IsError {
-			if result.Payload != responseBodyTooLargeMessage {
-				t.Fatalf("error payload = %q, want %q", result.Payload, responseBodyTooLargeMessage)
+			details, err := errs.Decode(result.Payload)
+
+			if err != nil || details.Code != errs.CodeBodyTooLarge || details.Message != responseBodyTooLargeMessage {
+				t.Fatalf("error payload = %+v (%v), want %q", details, err, responseBodyTooLargeMessage)
 			}
 
 			sawTooLarge = true
@@ -159,9 +162,9 @@ func TestResponseStateEnforcesMaxResponseBody(t *testing.T) {
 	}
 }
 
-// TestResponseStateNetworkErrorIsMarked checks a failed connection surfaces with
-// the network-class marker so PHP can raise a PSR-18 NetworkException.
-func TestResponseStateNetworkErrorIsMarked(t *testing.T) {
+// TestResponseStateNetworkErrorIsClassified checks a failed connection surfaces as
+// a network-class error so PHP can raise a PSR-18 NetworkException.
+func TestResponseStateNetworkErrorIsClassified(t *testing.T) {
 	// Port 1 on loopback refuses the connection.
 	state := newTestState(t, "http://127.0.0.1:1", 256, 0)
 	defer state.Close()
@@ -172,8 +175,10 @@ func TestResponseStateNetworkErrorIsMarked(t *testing.T) {
 		t.Fatal("expected a network error")
 	}
 
-	if !strings.HasPrefix(result.Payload, networkErrorMarker+":") {
-		t.Fatalf("payload = %q, want a %q-marked error", result.Payload, networkErrorMarker)
+	details, err := errs.Decode(result.Payload)
+
+	if err != nil || details.Category != errs.CategoryNetwork || details.Code != errs.CodeConnectionRefused {
+		t.Fatalf("error payload = %+v (%v), want a refused network error", details, err)
 	}
 }
 
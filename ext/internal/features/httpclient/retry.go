@@ -0,0 +1,251 @@
+package httpclient_feature
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/httpclient/payloads"
+	"strconv"
+	"time"
+)
+
+// Retry fallbacks, used only when the PHP side sends a zero value (the PHP
+// RetryPolicy defaults normally supply these and mirror them).
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 5 * time.Second
+)
+
+// maxRetryAttempts bounds the attempts of one request: without a request timeout
+// nothing else would stop a policy from resending to a failing host until the
+// flow stops.
+const maxRetryAttempts = 10
+
+// retryDrainLimit bounds how much of a discarded response body is read so its
+// connection can go back to the pool; a longer body closes the connection.
+const retryDrainLimit = 64 << 10
+
+// idempotentMethods are retried by default (RFC 9110 §9.2.2): sending them twice
+// has the effect of sending them once.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryPolicy resends a request that failed on the network or was answered with
+// one of statuses, with exponential backoff and jitter between attempts. Every
+// attempt runs under the request context, so the whole sequence stays bounded
+// by RequestTimeoutMs and a flow stop. A nil policy sends once.
+type retryPolicy struct {
+	maxAttempts   int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	statuses      map[int]bool
+	nonIdempotent bool
+}
+
+// checkRetryPolicy rejects a policy asking for more than maxRetryAttempts.
+func checkRetryPolicy(payload *payloads.RequestParams) error {
+	if payload.RetryMaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry max attempts must be at most %d", maxRetryAttempts)
+	}
+
+	return nil
+}
+
+// newRetryPolicy builds the policy of a request, nil when it asks for at most one
+// attempt. A streamed body cannot be replayed, so a streamed upload is never
+// retried.
+func newRetryPolicy(payload *payloads.RequestParams) *retryPolicy {
+	if payload.RetryMaxAttempts <= 1 || payload.StreamBody {
+		return nil
+	}
+
+	statuses := make(map[int]bool, len(payload.RetryStatuses))
+
+	for _, status := range payload.RetryStatuses {
+		statuses[status] = true
+	}
+
+	return &retryPolicy{
+		maxAttempts:   payload.RetryMaxAttempts,
+		backoff:       msOrDefault(payload.RetryBackoffMs, defaultRetryBackoff),
+		maxBackoff:    msOrDefault(payload.RetryMaxBackoffMs, defaultRetryMaxBackoff),
+		statuses:      statuses,
+		nonIdempotent: payload.RetryNonIdempotent,
+	}
+}
+
+// do sends request through client, retrying it as the policy allows, and returns
+// the last response or error with the number of attempts made.
+func (p *retryPolicy) do(client *http.Client, request *http.Request) (*http.Response, int, error) {
+	resp, err := client.Do(request)
+
+	if p == nil || !p.replayable(request) {
+		return resp, 1, err
+	}
+
+	ctx := request.Context()
+
+	for attempt := 1; attempt < p.maxAttempts; attempt++ {
+		if !p.retryable(resp, err) || ctx.Err() != nil {
+			return resp, attempt, err
+		}
+
+		delay, ok := p.delay(attempt, resp)
+
+		// The server asked for a longer wait than the policy allows: keep its answer.
+		if !ok {
+			return resp, attempt, err
+		}
+
+		// Waiting past the deadline only to be cut off: keep the last outcome.
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, attempt, err
+		}
+
+		next, bodyErr := replay(request)
+
+		if bodyErr != nil {
+			return resp, attempt, err
+		}
+
+		discard(resp)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, attempt, ctx.Err()
+		}
+
+		resp, err = client.Do(next)
+	}
+
+	return resp, p.maxAttempts, err
+}
+
+// replayable reports whether the request may be sent again: an idempotent method
+// (or any when overridden) with a body that can be read anew.
+func (p *retryPolicy) replayable(request *http.Request) bool {
+	if !p.nonIdempotent && !idempotentMethods[request.Method] {
+		return false
+	}
+
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// retryable reports whether an attempt failed in a way worth retrying: a status
+// of the policy, or an error errs.Classify puts in the network or timeout
+// category. A deterministic failure — TLS verification, a pin mismatch, a proxy
+// refusing the credentials, the redirect limit — would fail the same way again.
+func (p *retryPolicy) retryable(resp *http.Response, err error) bool {
+	if err == nil {
+		return p.statuses[resp.StatusCode]
+	}
+
+	// net/http wraps every failure into a *url.Error, which passes for a network
+	// error itself: classify what it wraps.
+	var urlError *url.Error
+
+	if errors.As(err, &urlError) {
+		err = urlError.Err
+	}
+
+	// A connection closed before any answer comes back as a bare io.EOF.
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+
+	switch errs.Classify(err, "").Category {
+	case errs.CategoryNetwork, errs.CategoryTimeout:
+		return true
+	}
+
+	return false
+}
+
+// delay is the wait before the attempt after the given one: the Retry-After of
+// the response when it sets one, else the exponential backoff capped at
+// maxBackoff, with equal jitter (half fixed, half random) so clients that failed
+// together do not retry together. A Retry-After is never shortened — retrying
+// before the server said would only be refused again — so one over maxBackoff
+// gives up the retries (false) and the response goes back as it is.
+func (p *retryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if after, ok := retryAfter(resp); ok {
+		return after, after <= p.maxBackoff
+	}
+
+	backoff := p.backoff << (attempt - 1)
+
+	if backoff <= 0 || backoff > p.maxBackoff {
+		backoff = p.maxBackoff
+	}
+
+	half := backoff / 2
+
+	return half + rand.N(half+1), true
+}
+
+// retryAfter parses the Retry-After header of a response: delay-seconds or an
+// HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// replay clones request for another attempt, with a fresh copy of its body.
+func replay(request *http.Request) (*http.Request, error) {
+	next := request.Clone(request.Context())
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+
+		if err != nil {
+			return nil, err
+		}
+
+		next.Body = body
+	}
+
+	return next, nil
+}
+
+// discard releases a response that is about to be retried, draining a short body
+// so its keep-alive connection is reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	_, _ = io.CopyN(io.Discard, resp.Body, retryDrainLimit)
+	_ = resp.Body.Close()
+}
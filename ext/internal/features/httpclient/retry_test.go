@@ -0,0 +1,316 @@
+package httpclient_feature
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sconcur/internal/errs"
+	"sconcur/internal/features/httpclient/payloads"
+)
+
+// failingServer answers the first failures requests with status, then 200 with
+// the request body echoed back. It counts every request.
+func failingServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+
+		if calls.Add(1) <= failures {
+			writer.WriteHeader(status)
+
+			return
+		}
+
+		_, _ = writer.Write(body)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func testPolicy(maxAttempts int) *retryPolicy {
+	return newRetryPolicy(&payloads.RequestParams{
+		RetryMaxAttempts: maxAttempts,
+		RetryBackoffMs:   1,
+		RetryStatuses:    []int{http.StatusServiceUnavailable},
+	})
+}
+
+func send(t *testing.T, policy *retryPolicy, ctx context.Context, method string, url string, body string) (*http.Response, int, error) {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+
+	resp, attempts, err := policy.do(testClient(t, transportKey{verifyTls: true}), request)
+
+	if resp != nil {
+		t.Cleanup(func() { _ = resp.Body.Close() })
+	}
+
+	return resp, attempts, err
+}
+
+func TestRetryRetriesStatusesUntilSuccess(t *testing.T) {
+	server, calls := failingServer(t, 2, http.StatusServiceUnavailable)
+
+	resp, attempts, err := send(t, testPolicy(5), context.Background(), http.MethodPut, server.URL, "payload")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	// The buffered body is replayed on every attempt.
+	if resp.StatusCode != http.StatusOK || string(body) != "payload" || attempts != 3 || calls.Load() != 3 {
+		t.Fatalf("status %d, body %q, attempts %d, calls %d", resp.StatusCode, body, attempts, calls.Load())
+	}
+}
+
+func TestRetryStopsAtMaxAttempts(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusServiceUnavailable)
+
+	resp, attempts, err := send(t, testPolicy(3), context.Background(), http.MethodGet, server.URL, "")
+
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || attempts != 3 || calls.Load() != 3 {
+		t.Fatalf("err %v, attempts %d, calls %d", err, attempts, calls.Load())
+	}
+}
+
+func TestRetryIgnoresOtherStatuses(t *testing.T) {
+	server, calls := failingServer(t, 1, http.StatusInternalServerError)
+
+	resp, attempts, _ := send(t, testPolicy(3), context.Background(), http.MethodGet, server.URL, "")
+
+	if resp.StatusCode != http.StatusInternalServerError || attempts != 1 || calls.Load() != 1 {
+		t.Fatalf("status %d, attempts %d, calls %d", resp.StatusCode, attempts, calls.Load())
+	}
+}
+
+func TestRetrySkipsNonIdempotentMethodsUnlessAllowed(t *testing.T) {
+	server, calls := failingServer(t, 1, http.StatusServiceUnavailable)
+
+	if _, attempts, _ := send(t, testPolicy(3), context.Background(), http.MethodPost, server.URL, "x"); attempts != 1 {
+		t.Fatalf("POST retried by default: %d attempts", attempts)
+	}
+
+	policy := testPolicy(3)
+	policy.nonIdempotent = true
+
+	calls.Store(0)
+
+	if _, attempts, _ := send(t, policy, context.Background(), http.MethodPost, server.URL, "x"); attempts != 2 {
+		t.Fatalf("POST with the override: %d attempts, %d calls", attempts, calls.Load())
+	}
+}
+
+func TestRetryRetriesNetworkErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			// Drop the connection without an answer.
+			connection, _, _ := writer.(http.Hijacker).Hijack()
+			_ = connection.Close()
+
+			return
+		}
+
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, attempts, err := send(t, testPolicy(3), context.Background(), http.MethodGet, server.URL, "")
+
+	if err != nil || resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Fatalf("err %v, attempts %d", err, attempts)
+	}
+}
+
+// TestRetrySkipsDeterministicFailures checks a failed TLS verification or pin is
+// not retried: it would fail the same way on every attempt.
+func TestRetrySkipsDeterministicFailures(t *testing.T) {
+	server := httptest.NewTLSServer(peerHandler)
+	defer server.Close()
+
+	other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	keys := map[string]transportKey{
+		"untrusted CA": tlsKey(t, payloads.RequestParams{VerifyTls: true}),
+		"wrong pin":    tlsKey(t, payloads.RequestParams{TlsPins: []string{other}}),
+	}
+
+	for name, key := range keys {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, attempts, err := testPolicy(3).do(testClient(t, key), request)
+
+		if err == nil || attempts != 1 {
+			t.Fatalf("%s: err %v, attempts %d", name, err, attempts)
+		}
+	}
+}
+
+// TestRetryStaysWithinTheDeadline checks a backoff that would outlast the request
+// deadline is not waited: the last response is returned at once.
+func TestRetryStaysWithinTheDeadline(t *testing.T) {
+	server, calls := failingServer(t, 10, http.StatusServiceUnavailable)
+
+	policy := testPolicy(5)
+	policy.backoff = time.Minute
+	policy.maxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	resp, attempts, _ := send(t, policy, ctx, http.MethodGet, server.URL, "")
+
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts != 1 || calls.Load() != 1 || time.Since(start) > time.Second {
+		t.Fatalf("attempts %d, calls %d, took %s", attempts, calls.Load(), time.Since(start))
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	cases := map[string]time.Duration{
+		"3":       3 * time.Second,
+		"0":       0,
+		"garbage": -1,
+	}
+
+	for value, want := range cases {
+		resp := &http.Response{Header: http.Header{"Retry-After": {value}}}
+
+		got, ok := retryAfter(resp)
+
+		if want < 0 {
+			if ok {
+				t.Fatalf("%q: parsed as %s", value, got)
+			}
+
+			continue
+		}
+
+		if !ok || got != want {
+			t.Fatalf("%q: got %s (%v), want %s", value, got, ok, want)
+		}
+	}
+
+	date := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}}
+
+	if got, ok := retryAfter(date); !ok || got < 59*time.Minute {
+		t.Fatalf("date: got %s (%v)", got, ok)
+	}
+}
+
+func TestRetryBackoffIsCappedWithJitter(t *testing.T) {
+	policy := &retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: 300 * time.Millisecond}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		ceiling := min(100*time.Millisecond<<(attempt-1), 300*time.Millisecond)
+
+		if delay, _ := policy.delay(attempt, nil); delay < ceiling/2 || delay > ceiling {
+			t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, delay, ceiling/2, ceiling)
+		}
+	}
+}
+
+func TestRetryAfterOverMaxBackoffGivesUp(t *testing.T) {
+	policy := &retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: 2 * time.Second}
+
+	short := &http.Response{Header: http.Header{"Retry-After": {"1"}}}
+
+	if delay, ok := policy.delay(1, short); !ok || delay != time.Second {
+		t.Fatalf("a Retry-After under the cap must be kept, got %s (%v)", delay, ok)
+	}
+
+	long := &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+
+	if delay, ok := policy.delay(1, long); ok {
+		t.Fatalf("a Retry-After over the cap must not be shortened, got %s", delay)
+	}
+}
+
+// TestRetryReturnsAResponseWhoseRetryAfterIsTooLong checks the 429 asking for a
+// longer wait than maxBackoff (or than the deadline) is handed back at once,
+// not retried early.
+func TestRetryReturnsAResponseWhoseRetryAfterIsTooLong(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls.Add(1)
+		writer.Header().Set("Retry-After", "3")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	t.Cleanup(server.Close)
+
+	policy := testPolicy(5)
+	policy.statuses[http.StatusTooManyRequests] = true
+
+	cases := map[string]struct {
+		maxBackoff time.Duration
+		timeout    time.Duration
+	}{
+		"max backoff": {maxBackoff: time.Second, timeout: time.Minute},
+		"deadline":    {maxBackoff: time.Minute, timeout: 2 * time.Second},
+	}
+
+	for name, limits := range cases {
+		calls.Store(0)
+		policy.maxBackoff = limits.maxBackoff
+
+		ctx, cancel := context.WithTimeout(context.Background(), limits.timeout)
+
+		start := time.Now()
+
+		resp, attempts, err := send(t, policy, ctx, http.MethodGet, server.URL, "")
+
+		cancel()
+
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempts != 1 || calls.Load() != 1 || time.Since(start) > time.Second {
+			t.Fatalf("%s: err %v, attempts %d, calls %d, took %s", name, err, attempts, calls.Load(), time.Since(start))
+		}
+	}
+}
+
+func TestStreamedBodyIsNeverRetried(t *testing.T) {
+	if newRetryPolicy(&payloads.RequestParams{RetryMaxAttempts: 3, StreamBody: true}) != nil {
+		t.Fatal("a streamed body must not get a retry policy")
+	}
+}
+
+func TestRequestWithTooManyRetryAttemptsIsRejected(t *testing.T) {
+	result := handleRequestPayload(t, payloads.RequestParams{
+		Method:           http.MethodGet,
+		Url:              "http://127.0.0.1",
+		RetryMaxAttempts: maxRetryAttempts + 1,
+	})
+
+	if !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+
+	assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+}
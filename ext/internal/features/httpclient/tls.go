@@ -0,0 +1,165 @@
+package httpclient_feature
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sconcur/internal/features/httpclient/payloads"
+	"slices"
+	"strings"
+)
+
+// TLS versions a request can require as its minimum (RequestParams.TlsMinVersion).
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// pinPrefix is the optional algorithm prefix of a pin ("sha256/<base64>", the
+// form curl's --pinnedpubkey and HPKP use).
+const pinPrefix = "sha256/"
+
+// errPinMismatch fails a handshake whose certificate chain carries none of the
+// pinned public keys. Surfaces to PHP as a network-class error.
+var errPinMismatch = errors.New("tls: no certificate matches a pinned public key")
+
+// tlsSettings is the TLS part of a transportKey. Every field is a string so the
+// key stays comparable: the CA files and the pins are joined in a canonical order.
+type tlsSettings struct {
+	caFiles    string
+	caPem      string
+	certFile   string
+	keyFile    string
+	serverName string
+	minVersion string
+	pins       string
+}
+
+// newTlsSettings validates the TLS options of a request and folds them into the
+// transport identity. Files are read later, once per transport.
+func newTlsSettings(payload *payloads.RequestParams) (tlsSettings, error) {
+	if payload.TlsMinVersion != "" {
+		if _, ok := tlsVersions[payload.TlsMinVersion]; !ok {
+			return tlsSettings{}, fmt.Errorf("unknown TLS version %q", payload.TlsMinVersion)
+		}
+	}
+
+	if (payload.TlsCertFile == "") != (payload.TlsKeyFile == "") {
+		return tlsSettings{}, errors.New("a client certificate needs both a cert and a key file")
+	}
+
+	pins := make([]string, 0, len(payload.TlsPins))
+
+	for _, pin := range payload.TlsPins {
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, pinPrefix))
+
+		if err != nil || len(hash) != sha256.Size {
+			return tlsSettings{}, fmt.Errorf("pin %q is not a base64 SHA-256 hash", pin)
+		}
+
+		pins = append(pins, string(hash))
+	}
+
+	slices.Sort(pins)
+
+	return tlsSettings{
+		caFiles:    strings.Join(payload.TlsCaFiles, "\n"),
+		caPem:      payload.TlsCaPem,
+		certFile:   payload.TlsCertFile,
+		keyFile:    payload.TlsKeyFile,
+		serverName: payload.TlsServerName,
+		minVersion: payload.TlsMinVersion,
+		pins:       strings.Join(slices.Compact(pins), ""),
+	}, nil
+}
+
+// config builds the client TLS config of a transport, nil when the defaults do
+// (system roots, verification on). Custom CAs replace the system roots. Pins are
+// checked after the chain is verified, and also when verification is off, so a
+// self-signed peer can be pinned instead of trusted blindly.
+func (s tlsSettings) config(verifyTls bool) (*tls.Config, error) {
+	if verifyTls && s == (tlsSettings{}) {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: !verifyTls,
+		ServerName:         s.serverName,
+		MinVersion:         tlsVersions[s.minVersion],
+	}
+
+	if s.caFiles != "" || s.caPem != "" {
+		roots, err := s.roots()
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = roots
+	}
+
+	if s.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if s.pins != "" {
+		config.VerifyConnection = s.verifyPins
+	}
+
+	return config, nil
+}
+
+// roots loads the CA bundle files and the inline PEM into one pool. A source
+// without a single certificate is an error rather than an empty trust store.
+func (s tlsSettings) roots() (*x509.CertPool, error) {
+	roots := x509.NewCertPool()
+
+	if s.caFiles != "" {
+		for _, path := range strings.Split(s.caFiles, "\n") {
+			pem, err := os.ReadFile(path)
+
+			if err != nil {
+				return nil, fmt.Errorf("read CA bundle: %w", err)
+			}
+
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA bundle %s has no PEM certificates", path)
+			}
+		}
+	}
+
+	if s.caPem != "" && !roots.AppendCertsFromPEM([]byte(s.caPem)) {
+		return nil, errors.New("CA PEM has no certificates")
+	}
+
+	return roots, nil
+}
+
+// verifyPins accepts the connection when any certificate of the peer chain has a
+// pinned SPKI SHA-256 hash, so pinning the leaf, an intermediate or the CA works.
+func (s tlsSettings) verifyPins(state tls.ConnectionState) error {
+	for _, certificate := range state.PeerCertificates {
+		hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+
+		for pin := range slices.Chunk([]byte(s.pins), sha256.Size) {
+			if bytes.Equal(hash[:], pin) {
+				return nil
+			}
+		}
+	}
+
+	return errPinMismatch
+}
@@ -0,0 +1,273 @@
+package httpclient_feature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sconcur/internal/errs"
+	"sconcur/internal/features/httpclient/payloads"
+)
+
+// peerHandler answers with the common name of the client certificate, "-" when
+// the client presented none.
+var peerHandler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+	name := "-"
+
+	if len(request.TLS.PeerCertificates) > 0 {
+		name = request.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	_, _ = writer.Write([]byte(name))
+})
+
+// serverCaPem returns the PEM of the self-signed certificate of a test server.
+func serverCaPem(server *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+// writeFile writes content into a file of the test's temp dir.
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// clientCertificate generates a self-signed client certificate and returns it
+// with its cert and key PEM.
+func clientCertificate(t *testing.T, commonName string) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return certificate, string(certPem), string(keyPem)
+}
+
+// tlsKey builds the transport key of verified requests with the TLS options of
+// params.
+func tlsKey(t *testing.T, params payloads.RequestParams) transportKey {
+	t.Helper()
+
+	settings, err := newTlsSettings(&params)
+
+	if err != nil {
+		t.Fatalf("tls settings: %v", err)
+	}
+
+	return transportKey{verifyTls: params.VerifyTls, tls: settings}
+}
+
+// tlsGet sends one GET through the transport of key and returns the body.
+func tlsGet(t *testing.T, key transportKey, url string) (string, error) {
+	t.Helper()
+
+	resp, err := testClient(t, key).Get(url)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+func TestTlsCustomCaReplacesSystemRoots(t *testing.T) {
+	server := httptest.NewTLSServer(peerHandler)
+	defer server.Close()
+
+	if _, err := tlsGet(t, tlsKey(t, payloads.RequestParams{VerifyTls: true}), server.URL); err == nil {
+		t.Fatal("a private CA must not be trusted by default")
+	}
+
+	byFile := tlsKey(t, payloads.RequestParams{VerifyTls: true, TlsCaFiles: []string{writeFile(t, "ca.pem", serverCaPem(server))}})
+
+	if _, err := tlsGet(t, byFile, server.URL); err != nil {
+		t.Fatalf("CA file: %v", err)
+	}
+
+	byPem := tlsKey(t, payloads.RequestParams{VerifyTls: true, TlsCaPem: serverCaPem(server)})
+
+	if _, err := tlsGet(t, byPem, server.URL); err != nil {
+		t.Fatalf("CA PEM: %v", err)
+	}
+}
+
+func TestTlsClientCertificate(t *testing.T) {
+	certificate, certPem, keyPem := clientCertificate(t, "billing-worker")
+
+	clientCas := x509.NewCertPool()
+	clientCas.AddCert(certificate)
+
+	server := httptest.NewUnstartedServer(peerHandler)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCas}
+	server.StartTLS()
+	defer server.Close()
+
+	params := payloads.RequestParams{VerifyTls: true, TlsCaPem: serverCaPem(server)}
+
+	if _, err := tlsGet(t, tlsKey(t, params), server.URL); err == nil {
+		t.Fatal("the server requires a client certificate")
+	}
+
+	params.TlsCertFile = writeFile(t, "client.pem", certPem)
+	params.TlsKeyFile = writeFile(t, "client.key", keyPem)
+
+	if body, err := tlsGet(t, tlsKey(t, params), server.URL); err != nil || body != "billing-worker" {
+		t.Fatalf("mTLS: body %q, err %v", body, err)
+	}
+}
+
+// TestTlsServerNameOverride checks the override is the name verified against the
+// certificate (the test certificate is issued for example.com).
+func TestTlsServerNameOverride(t *testing.T) {
+	server := httptest.NewTLSServer(peerHandler)
+	defer server.Close()
+
+	params := payloads.RequestParams{VerifyTls: true, TlsCaPem: serverCaPem(server), TlsServerName: "example.com"}
+
+	if _, err := tlsGet(t, tlsKey(t, params), server.URL); err != nil {
+		t.Fatalf("matching name: %v", err)
+	}
+
+	params.TlsServerName = "billing.internal"
+
+	if _, err := tlsGet(t, tlsKey(t, params), server.URL); err == nil {
+		t.Fatal("a name the certificate is not valid for must fail")
+	}
+}
+
+func TestTlsMinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(peerHandler)
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	params := payloads.RequestParams{VerifyTls: true, TlsCaPem: serverCaPem(server), TlsMinVersion: "1.2"}
+
+	if _, err := tlsGet(t, tlsKey(t, params), server.URL); err != nil {
+		t.Fatalf("TLS 1.2 server: %v", err)
+	}
+
+	params.TlsMinVersion = "1.3"
+
+	if _, err := tlsGet(t, tlsKey(t, params), server.URL); err == nil {
+		t.Fatal("a TLS 1.2 server must be refused when 1.3 is the minimum")
+	}
+}
+
+// TestTlsPinnedPublicKey checks a pin is enforced even with verification off, so
+// a self-signed peer can be pinned instead of trusted blindly.
+func TestTlsPinnedPublicKey(t *testing.T) {
+	server := httptest.NewTLSServer(peerHandler)
+	defer server.Close()
+
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+	other := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	matching := payloads.RequestParams{TlsPins: []string{other, pinPrefix + pin}}
+
+	if _, err := tlsGet(t, tlsKey(t, matching), server.URL); err != nil {
+		t.Fatalf("matching pin: %v", err)
+	}
+
+	wrong := payloads.RequestParams{TlsPins: []string{other}}
+
+	if _, err := tlsGet(t, tlsKey(t, wrong), server.URL); err == nil || !strings.Contains(err.Error(), errPinMismatch.Error()) {
+		t.Fatalf("wrong pin: err %v", err)
+	}
+}
+
+// TestTlsSettingsShareTransport checks equal settings share a transport whatever
+// the order of the pins, and different settings do not.
+func TestTlsSettingsShareTransport(t *testing.T) {
+	first := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+	second := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", sha256.Size)))
+
+	a := tlsKey(t, payloads.RequestParams{VerifyTls: true, TlsPins: []string{first, second}})
+	b := tlsKey(t, payloads.RequestParams{VerifyTls: true, TlsPins: []string{pinPrefix + second, first}})
+	c := tlsKey(t, payloads.RequestParams{VerifyTls: true, TlsPins: []string{first}})
+
+	if testTransport(t, a) != testTransport(t, b) {
+		t.Fatal("the same pins must share a transport")
+	}
+
+	if testTransport(t, a) == testTransport(t, c) {
+		t.Fatal("different pins must not share a transport")
+	}
+}
+
+func TestTlsInvalidSettingsAreRequestErrors(t *testing.T) {
+	cases := map[string]payloads.RequestParams{
+		"version":   {TlsMinVersion: "1.4"},
+		"half pair": {TlsCertFile: "client.pem"},
+		"pin":       {TlsPins: []string{"not-a-hash"}},
+		"ca file":   {TlsCaFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}},
+		"ca pem":    {TlsCaPem: "garbage"},
+	}
+
+	for name, params := range cases {
+		params.Method = http.MethodGet
+		params.Url = "https://127.0.0.1"
+
+		result := handleRequestPayload(t, params)
+
+		if !result.IsError {
+			t.Fatalf("%s: expected a request error", name)
+		}
+
+		assertErrorCategory(t, result.Payload, errs.CategoryValidation)
+	}
+}
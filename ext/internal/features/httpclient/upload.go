@@ -77,7 +77,7 @@ func (f *HttpClientFeature) startStreamedRequest(
 
 	// Register without auto-reading the first batch: the response is pulled later,
 	// after the body has been streamed in (client.Do is still in flight).
-	if err := states.Get().Register(message.TaskKey, state); err != nil {
+	if err := states.Get().Register(message.TaskKey, states.Prefetch(state, message, payload.PrefetchDepth)); err != nil {
 		pendingUploads.Delete(payload.RequestId)
 
 		_ = pipeWriter.CloseWithError(err)
@@ -110,7 +110,7 @@ func (f *HttpClientFeature) handleUpload(task *tasks.Task, raw msgpack.RawMessag
 	var payload payloads.UploadParams
 
 	if err := msgpack.Unmarshal(raw, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse upload params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse upload params", err)))
 
 		return
 	}
@@ -158,8 +158,8 @@ func uploadWriteError(session *uploadSession, writeErr error) string {
 	<-session.resultReady
 
 	if session.result.err != nil {
-		return networkErrorPayload(session.result.err.Error())
+		return sendError("send request", session.result.err)
 	}
 
-	return networkErrorPayload(writeErr.Error())
+	return errFactory.ByNetwork("write request body", writeErr)
 }
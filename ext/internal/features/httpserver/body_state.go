@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"sconcur/internal/contracts"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/helpers"
 	"sync"
 	"time"
@@ -13,9 +14,9 @@ import (
 
 var _ contracts.StateContract = (*bodyState)(nil)
 
-// bodyTooLargeMessage is the exact error payload returned when the request body
-// exceeds maxRequestBody mid-stream. The PHP side matches on it to surface a 413
-// rather than a generic 500.
+// bodyTooLargeMessage is the message of the body_too_large error returned when the
+// request body exceeds maxRequestBody mid-stream. The PHP side matches on the code
+// to surface a 413 rather than a generic 500.
 const bodyTooLargeMessage = "request body too large"
 
 // bodyState streams the remainder of a request body to PHP, one chunk per Next
@@ -60,12 +61,13 @@ func (s *bodyState) Close() {
 }
 
 // readErrorMessage maps a body read error to the payload PHP receives: a stable
-// marker for the over-limit case (→ 413), the raw error otherwise.
+// body_too_large envelope for the over-limit case (→ 413), the classified error
+// otherwise.
 func readErrorMessage(err error) string {
 	var maxBytesError *http.MaxBytesError
 
 	if errors.As(err, &maxBytesError) {
-		return bodyTooLargeMessage
+		return errs.Make(errs.CategoryValidation, errs.CodeBodyTooLarge, bodyTooLargeMessage)
 	}
 
 	return errFactory.ByErr("read request body", err)
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 )
 
 // TestBodyStateStreamsChunksThenEOF reassembles a body from the chunks bodyState
@@ -54,8 +55,10 @@ func TestBodyStateReportsTooLarge(t *testing.T) {
 		if result.IsError {
 			sawError = true
 
-			if result.Payload != bodyTooLargeMessage {
-				t.Fatalf("want %q, got %q", bodyTooLargeMessage, result.Payload)
+			details, err := errs.Decode(result.Payload)
+
+			if err != nil || details.Code != errs.CodeBodyTooLarge || details.Message != bodyTooLargeMessage {
+				t.Fatalf("want %q, got %+v (%v)", bodyTooLargeMessage, details, err)
 			}
 
 			break
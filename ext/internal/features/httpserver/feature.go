@@ -73,7 +73,7 @@ func (f *HttpFeature) handleServe(task *tasks.Task) {
 	var payload payloads.ServePayload
 
 	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse serve payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse serve payload", err)))
 
 		return
 	}
@@ -121,7 +121,7 @@ func (f *HttpFeature) handleRespond(task *tasks.Task) {
 	}
 
 	if err := msgpack.Unmarshal(message.Payload, &idOnly); err != nil || idOnly.RequestId == "" {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond requestId", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond requestId", err)))
 
 		return
 	}
@@ -149,7 +149,7 @@ func (f *HttpFeature) handleRespond(task *tasks.Task) {
 		// Malformed payload: answer the client with a 500 instead of hanging.
 		_ = f.dispatch(task, pending, writeCommand{kind: writeFull, status: 500, body: "Internal Server Error"})
 
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond payload", err)))
 
 		return
 	}
@@ -162,7 +162,13 @@ func (f *HttpFeature) handleRespond(task *tasks.Task) {
 	}
 
 	if err := f.dispatch(task, pending, command); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("write response", err)))
+		var cause *errs.StopCause
+
+		if errors.As(err, &cause) {
+			task.AddResult(dto.NewStoppedResult(message, cause))
+		} else {
+			task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("write response", err)))
+		}
 
 		return
 	}
@@ -182,7 +188,7 @@ func (f *HttpFeature) dispatch(task *tasks.Task, pending *pendingRequest, comman
 	select {
 	case pending.commands <- command:
 	case <-pending.abandoned:
-		return errAbandoned
+		return pending.abandonedError()
 	case <-task.GetContext().Done():
 		return nil
 	}
@@ -199,7 +205,7 @@ func (f *HttpFeature) dispatch(task *tasks.Task, pending *pendingRequest, comman
 	case err := <-command.done:
 		return err
 	case <-pending.abandoned:
-		return errAbandoned
+		return pending.abandonedError()
 	case <-task.GetContext().Done():
 		return nil
 	}
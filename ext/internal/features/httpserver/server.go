@@ -7,7 +7,9 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"sconcur/internal/crashes"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features/httpserver/payloads"
 	"sconcur/internal/helpers"
 	"sconcur/internal/logger"
@@ -71,8 +73,8 @@ func configFromPayload(payload payloads.ServePayload) serverConfig {
 		shutdownTimeout:   msOrDefault(payload.ShutdownTimeoutMs, defaultShutdownTimeout),
 		maxRequestBody:    int64OrDefault(payload.MaxRequestBody, defaultMaxRequestBody),
 		// 0 stays 0 (disabled/unlimited); a negative value is treated the same.
-		handlerTimeout: time.Duration(max(payload.HandlerTimeoutMs, 0)) * time.Millisecond,
-		maxConcurrency: max(payload.MaxConcurrency, 0),
+		handlerTimeout:      time.Duration(max(payload.HandlerTimeoutMs, 0)) * time.Millisecond,
+		maxConcurrency:      max(payload.MaxConcurrency, 0),
 		telemetrySocket:     payload.TelemetrySocket,
 		serverName:          payload.ServerName,
 		telemetryIntervalMs: payload.TelemetryIntervalMs,
@@ -123,9 +125,22 @@ type writeCommand struct {
 // and the PHP handler's write commands. abandoned is closed once ServeHTTP stops
 // consuming — on a handler timeout or any return — so a handler that responds
 // late unblocks with an error instead of hanging on the unbuffered commands chan.
+// serverCtx is the server flow context, telling why the request was abandoned
+// when the server was stopped.
 type pendingRequest struct {
 	commands  chan writeCommand
 	abandoned chan struct{}
+	serverCtx context.Context
+}
+
+// abandonedError is the error of a write the connection goroutine no longer
+// consumes: the stop cause of the server flow when it was stopped with one.
+func (p *pendingRequest) abandonedError() error {
+	if cause := errs.CauseOf(p.serverCtx); cause != nil {
+		return cause
+	}
+
+	return errAbandoned
 }
 
 // serverState is the streaming state of one HTTP server: each accepted request
@@ -223,7 +238,7 @@ func (s *serverState) ServeHTTP(writer http.ResponseWriter, request *http.Reques
 	status := 0
 
 	defer func() {
-		logger.Write(formatAccessLine(start, request.Method, request.URL.Path, status))
+		logger.Write(formatAccessLine(start, request.Method, request.URL.Path, status, s.stopCode(status)))
 	}()
 
 	// Bound concurrency before touching the body, so requests waiting for a slot
@@ -245,6 +260,20 @@ func (s *serverState) ServeHTTP(writer http.ResponseWriter, request *http.Reques
 
 	requestId := nextRequestId(s.message.FlowKey)
 
+	// A panic while serving the request is recorded in the crash log, then aborts
+	// the connection the way net/http does, minus its own log line.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recovered != http.ErrAbortHandler {
+				crashes.Get().Record(crashes.NewReport(crashes.KindServer, s.message, requestId, recovered))
+			}
+
+			status = http.StatusInternalServerError
+
+			panic(http.ErrAbortHandler)
+		}
+	}()
+
 	// Track the request for the statistics: count it and its duration on return,
 	// and keep it in the in-flight set (with its start) for the age buckets.
 	s.requestStats.requestBegan(requestId, start)
@@ -284,6 +313,7 @@ func (s *serverState) ServeHTTP(writer http.ResponseWriter, request *http.Reques
 	pending := &pendingRequest{
 		commands:  make(chan writeCommand),
 		abandoned: make(chan struct{}),
+		serverCtx: s.ctx,
 	}
 
 	pendingRequests.Store(requestId, pending)
@@ -422,7 +452,7 @@ func (s *serverState) Next() *dto.Result {
 		serialized, err := msgpack.Marshal(event)
 
 		if err != nil {
-			return dto.NewErrorResult(s.message, "httpServer: marshal request: "+err.Error())
+			return dto.NewErrorResult(s.message, errFactory.ByErr("marshal request", err))
 		}
 
 		return dto.NewSuccessResultWithNext(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
@@ -537,17 +567,38 @@ func flush(flusher http.Flusher) {
 // matching the format PHP used before logging moved to the Go side. Method and
 // path are escaped (sanitizeLogField) so a control byte decoded from the URL
 // cannot forge an extra line.
-func formatAccessLine(start time.Time, method string, path string, status int) string {
+func formatAccessLine(start time.Time, method string, path string, status int, stopCode string) string {
 	elapsedMs := float64(time.Since(start).Microseconds()) / 1000.0
 
-	return fmt.Sprintf(
-		"%s %s %s %d %.2fms\n",
+	line := fmt.Sprintf(
+		"%s %s %s %d %.2fms",
 		start.Format("2006-01-02T15:04:05.000000"),
 		sanitizeLogField(method),
 		sanitizeLogField(path),
 		status,
 		elapsedMs,
 	)
+
+	if stopCode != "" {
+		line += " stopped:" + sanitizeLogField(stopCode)
+	}
+
+	return line + "\n"
+}
+
+// stopCode is the code of the server stop that made a request end with status:
+// only a 503 is the server's own answer to a stop (see writeServiceUnavailable
+// callers), so that is where the access log says why the request was aborted.
+func (s *serverState) stopCode(status int) string {
+	if status != http.StatusServiceUnavailable {
+		return ""
+	}
+
+	if cause := errs.CauseOf(s.ctx); cause != nil {
+		return cause.Code
+	}
+
+	return ""
 }
 
 // sanitizeLogField escapes control bytes (C0 range and DEL) as \xNN so a value
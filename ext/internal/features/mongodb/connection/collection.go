@@ -40,7 +40,7 @@ func (c *Collection) InsertOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse insertOne payload", err),
+			errFactory.ByInvalid("parse insertOne payload", err),
 		)
 	}
 
@@ -59,7 +59,7 @@ func (c *Collection) BulkWrite(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse bulkWrite payload", err),
+			errFactory.ByInvalid("parse bulkWrite payload", err),
 		)
 	}
 
@@ -80,7 +80,14 @@ func (c *Collection) Aggregate(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse aggregate params", err),
+			errFactory.ByInvalid("parse aggregate params", err),
+		)
+	}
+
+	if err := states.CheckPrefetchDepth(params.PrefetchDepth); err != nil {
+		return dto.NewErrorResult(
+			message,
+			errFactory.ByInvalid("parse aggregate params", err),
 		)
 	}
 
@@ -89,7 +96,7 @@ func (c *Collection) Aggregate(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse aggregate payload", err),
+			errFactory.ByInvalid("parse aggregate payload", err),
 		)
 	}
 
@@ -108,7 +115,7 @@ func (c *Collection) Aggregate(
 		client.Release,
 	)
 
-	result, err := states.Get().Start(ctx, message.TaskKey, state)
+	result, err := states.Get().Start(ctx, message.TaskKey, states.Prefetch(state, message, params.PrefetchDepth))
 
 	if err != nil {
 		client.Release()
@@ -132,7 +139,7 @@ func (c *Collection) InsertMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse insertMany payload", err),
+			errFactory.ByInvalid("parse insertMany payload", err),
 		)
 	}
 
@@ -151,7 +158,7 @@ func (c *Collection) CountDocuments(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse countDocuments payload", err),
+			errFactory.ByInvalid("parse countDocuments payload", err),
 		)
 	}
 
@@ -178,7 +185,7 @@ func (c *Collection) UpdateOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateOne params", err),
+			errFactory.ByInvalid("parse updateOne params", err),
 		)
 	}
 
@@ -187,7 +194,7 @@ func (c *Collection) UpdateOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateOne filter", err),
+			errFactory.ByInvalid("parse updateOne filter", err),
 		)
 	}
 
@@ -196,7 +203,7 @@ func (c *Collection) UpdateOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateOne update", err),
+			errFactory.ByInvalid("parse updateOne update", err),
 		)
 	}
 
@@ -230,7 +237,7 @@ func (c *Collection) FindOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOne params", err),
+			errFactory.ByInvalid("parse findOne params", err),
 		)
 	}
 
@@ -239,7 +246,7 @@ func (c *Collection) FindOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOne filter", err),
+			errFactory.ByInvalid("parse findOne filter", err),
 		)
 	}
 
@@ -251,7 +258,7 @@ func (c *Collection) FindOne(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse findOne projection", err),
+				errFactory.ByInvalid("parse findOne projection", err),
 			)
 		}
 
@@ -282,7 +289,7 @@ func (c *Collection) CreateIndex(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse createIndex params", err),
+			errFactory.ByInvalid("parse createIndex params", err),
 		)
 	}
 
@@ -291,7 +298,7 @@ func (c *Collection) CreateIndex(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse createIndex keys", err),
+			errFactory.ByInvalid("parse createIndex keys", err),
 		)
 	}
 
@@ -317,7 +324,7 @@ func (c *Collection) DeleteOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse deleteOne params", err),
+			errFactory.ByInvalid("parse deleteOne params", err),
 		)
 	}
 
@@ -326,7 +333,7 @@ func (c *Collection) DeleteOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse deleteOne filter", err),
+			errFactory.ByInvalid("parse deleteOne filter", err),
 		)
 	}
 
@@ -356,7 +363,7 @@ func (c *Collection) DeleteMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse deleteMany params", err),
+			errFactory.ByInvalid("parse deleteMany params", err),
 		)
 	}
 
@@ -365,7 +372,7 @@ func (c *Collection) DeleteMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse deleteMany filter", err),
+			errFactory.ByInvalid("parse deleteMany filter", err),
 		)
 	}
 
@@ -395,7 +402,7 @@ func (c *Collection) UpdateMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateMany params", err),
+			errFactory.ByInvalid("parse updateMany params", err),
 		)
 	}
 
@@ -404,7 +411,7 @@ func (c *Collection) UpdateMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateMany filter", err),
+			errFactory.ByInvalid("parse updateMany filter", err),
 		)
 	}
 
@@ -413,7 +420,7 @@ func (c *Collection) UpdateMany(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse updateMany update", err),
+			errFactory.ByInvalid("parse updateMany update", err),
 		)
 	}
 
@@ -457,7 +464,7 @@ func (c *Collection) DropIndex(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse dropIndex params", err),
+			errFactory.ByInvalid("parse dropIndex params", err),
 		)
 	}
 
@@ -478,7 +485,14 @@ func (c *Collection) Find(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse find params", err),
+			errFactory.ByInvalid("parse find params", err),
+		)
+	}
+
+	if err := states.CheckPrefetchDepth(params.PrefetchDepth); err != nil {
+		return dto.NewErrorResult(
+			message,
+			errFactory.ByInvalid("parse find params", err),
 		)
 	}
 
@@ -487,7 +501,7 @@ func (c *Collection) Find(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse find filter", err),
+			errFactory.ByInvalid("parse find filter", err),
 		)
 	}
 
@@ -499,7 +513,7 @@ func (c *Collection) Find(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse find projection", err),
+				errFactory.ByInvalid("parse find projection", err),
 			)
 		}
 
@@ -512,7 +526,7 @@ func (c *Collection) Find(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse find sort", err),
+				errFactory.ByInvalid("parse find sort", err),
 			)
 		}
 
@@ -554,7 +568,7 @@ func (c *Collection) Find(
 		client.Release,
 	)
 
-	result, err := states.Get().Start(ctx, message.TaskKey, state)
+	result, err := states.Get().Start(ctx, message.TaskKey, states.Prefetch(state, message, params.PrefetchDepth))
 
 	if err != nil {
 		client.Release()
@@ -580,7 +594,7 @@ func (c *Collection) Distinct(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse distinct params", err),
+			errFactory.ByInvalid("parse distinct params", err),
 		)
 	}
 
@@ -589,7 +603,7 @@ func (c *Collection) Distinct(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse distinct filter", err),
+			errFactory.ByInvalid("parse distinct filter", err),
 		)
 	}
 
@@ -631,7 +645,7 @@ func (c *Collection) FindOneAndUpdate(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndUpdate params", err),
+			errFactory.ByInvalid("parse findOneAndUpdate params", err),
 		)
 	}
 
@@ -640,7 +654,7 @@ func (c *Collection) FindOneAndUpdate(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndUpdate filter", err),
+			errFactory.ByInvalid("parse findOneAndUpdate filter", err),
 		)
 	}
 
@@ -649,7 +663,7 @@ func (c *Collection) FindOneAndUpdate(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndUpdate update", err),
+			errFactory.ByInvalid("parse findOneAndUpdate update", err),
 		)
 	}
 
@@ -669,7 +683,7 @@ func (c *Collection) FindOneAndUpdate(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse findOneAndUpdate projection", err),
+				errFactory.ByInvalid("parse findOneAndUpdate projection", err),
 			)
 		}
 
@@ -700,7 +714,7 @@ func (c *Collection) FindOneAndDelete(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndDelete params", err),
+			errFactory.ByInvalid("parse findOneAndDelete params", err),
 		)
 	}
 
@@ -709,7 +723,7 @@ func (c *Collection) FindOneAndDelete(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndDelete filter", err),
+			errFactory.ByInvalid("parse findOneAndDelete filter", err),
 		)
 	}
 
@@ -721,7 +735,7 @@ func (c *Collection) FindOneAndDelete(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse findOneAndDelete projection", err),
+				errFactory.ByInvalid("parse findOneAndDelete projection", err),
 			)
 		}
 
@@ -745,7 +759,7 @@ func (c *Collection) FindOneAndReplace(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndReplace params", err),
+			errFactory.ByInvalid("parse findOneAndReplace params", err),
 		)
 	}
 
@@ -754,7 +768,7 @@ func (c *Collection) FindOneAndReplace(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndReplace filter", err),
+			errFactory.ByInvalid("parse findOneAndReplace filter", err),
 		)
 	}
 
@@ -763,7 +777,7 @@ func (c *Collection) FindOneAndReplace(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse findOneAndReplace replacement", err),
+			errFactory.ByInvalid("parse findOneAndReplace replacement", err),
 		)
 	}
 
@@ -783,7 +797,7 @@ func (c *Collection) FindOneAndReplace(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse findOneAndReplace projection", err),
+				errFactory.ByInvalid("parse findOneAndReplace projection", err),
 			)
 		}
 
@@ -807,7 +821,7 @@ func (c *Collection) ReplaceOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse replaceOne params", err),
+			errFactory.ByInvalid("parse replaceOne params", err),
 		)
 	}
 
@@ -816,7 +830,7 @@ func (c *Collection) ReplaceOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse replaceOne filter", err),
+			errFactory.ByInvalid("parse replaceOne filter", err),
 		)
 	}
 
@@ -825,7 +839,7 @@ func (c *Collection) ReplaceOne(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse replaceOne replacement", err),
+			errFactory.ByInvalid("parse replaceOne replacement", err),
 		)
 	}
 
@@ -866,7 +880,7 @@ func (c *Collection) CreateIndexes(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse createIndexes params", err),
+			errFactory.ByInvalid("parse createIndexes params", err),
 		)
 	}
 
@@ -884,7 +898,7 @@ func (c *Collection) CreateIndexes(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse createIndexes indexes", err),
+			errFactory.ByInvalid("parse createIndexes indexes", err),
 		)
 	}
 
@@ -905,7 +919,7 @@ func (c *Collection) CreateIndexes(
 		if err != nil {
 			return dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse createIndexes keys", err),
+				errFactory.ByInvalid("parse createIndexes keys", err),
 			)
 		}
 
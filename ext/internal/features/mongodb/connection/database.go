@@ -71,7 +71,7 @@ func (d *Database) RunCommand(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse command", err),
+			errFactory.ByInvalid("parse command", err),
 		)
 	}
 
@@ -92,7 +92,7 @@ func (d *Database) RenameCollection(
 	if err != nil {
 		return dto.NewErrorResult(
 			message,
-			errFactory.ByErr("parse renameCollection params", err),
+			errFactory.ByInvalid("parse renameCollection params", err),
 		)
 	}
 
@@ -0,0 +1,66 @@
+package connection
+
+import (
+	"errors"
+	"sconcur/internal/errs"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	errs.RegisterClassifier(classifyMongoError)
+}
+
+// classifyMongoError fills in the server code and error labels of a MongoDB
+// error. The driver's own labels decide retryability: RetryableWriteError and
+// TransientTransactionError are what the server marks as safe to retry.
+func classifyMongoError(err error, details *errs.Details) bool {
+	var serverError mongo.ServerError
+
+	switch {
+	case mongo.IsTimeout(err):
+		details.Code, details.Category, details.Retryable = errs.CodeNetworkTimeout, errs.CategoryTimeout, true
+	case mongo.IsNetworkError(err):
+		details.Code, details.Category, details.Retryable = errs.CodeNetwork, errs.CategoryNetwork, true
+	case errors.As(err, &serverError):
+		details.Code, details.Category = errs.CodeDriver, errs.CategoryDriver
+
+		if codes := serverError.ErrorCodes(); len(codes) > 0 {
+			details.DriverCode = strconv.Itoa(codes[0])
+		}
+
+		if mongo.IsDuplicateKeyError(err) {
+			details.Code = errs.CodeDuplicateKey
+		}
+	default:
+		return false
+	}
+
+	details.Labels = mongoErrorLabels(err)
+
+	for _, label := range details.Labels {
+		if label == "RetryableWriteError" || label == "TransientTransactionError" {
+			details.Retryable = true
+		}
+	}
+
+	return true
+}
+
+func mongoErrorLabels(err error) []string {
+	var commandError mongo.CommandError
+	var writeException mongo.WriteException
+	var bulkWriteException mongo.BulkWriteException
+
+	switch {
+	case errors.As(err, &commandError):
+		return commandError.Labels
+	case errors.As(err, &writeException):
+		return writeException.Labels
+	case errors.As(err, &bulkWriteException):
+		return bulkWriteException.Labels
+	}
+
+	return nil
+}
@@ -83,7 +83,7 @@ func (f *CollectionFeature) Handle(task *tasks.Task) {
 		task.AddResult(
 			dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse payload error", err),
+				errFactory.ByInvalid("parse payload error", err),
 			),
 		)
 
@@ -33,6 +33,8 @@ type Payload struct {
 type AggregatePayload struct {
 	Pipeline  []byte `json:"p" msgpack:"p"`
 	BatchSize int    `json:"bs" msgpack:"bs"`
+	// PrefetchDepth is how many batches are read ahead in the background (0 = none).
+	PrefetchDepth int `json:"pf" msgpack:"pf"`
 }
 
 // UpdateOnePayload is the `dt` content of an updateOne command.
@@ -74,6 +76,8 @@ type FindPayload struct {
 	BatchSize  int    `json:"bs" msgpack:"bs"`
 	Hint       []byte `json:"hn" msgpack:"hn"`
 	Collation  []byte `json:"co" msgpack:"co"`
+	// PrefetchDepth is how many batches are read ahead in the background (0 = none).
+	PrefetchDepth int `json:"pf" msgpack:"pf"`
 }
 
 // CreateIndexPayload is the `dt` content of a createIndex command.
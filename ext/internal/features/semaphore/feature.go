@@ -0,0 +1,149 @@
+package semaphore_feature
+
+import (
+	"context"
+	"fmt"
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/semaphore/payloads"
+	"sconcur/internal/helpers"
+	"sconcur/internal/states"
+	"sconcur/internal/tasks"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/sync/semaphore"
+)
+
+var _ contracts.FeatureContract = (*SemaphoreFeature)(nil)
+
+var once sync.Once
+var instance *SemaphoreFeature
+
+var errFactory = errs.NewErrorsFactory("semaphore")
+
+// maxPermits bounds the permits of one semaphore.
+const maxPermits = 1 << 20
+
+// namedSemaphore is one named counting semaphore. Waiters are served in arrival
+// order (semaphore.Weighted is FIFO), whichever flow they belong to.
+type namedSemaphore struct {
+	permits  int64
+	weighted *semaphore.Weighted
+}
+
+// SemaphoreFeature keeps the named semaphores shared by every coroutine and flow of
+// the worker. An acquire is a task resolving once a permit is free; the permit is
+// then held by a state registered under the acquire task key and returned by a
+// next() on it, or when the acquiring flow is stopped.
+type SemaphoreFeature struct {
+	mutex      sync.Mutex
+	semaphores map[string]*namedSemaphore
+}
+
+func Get() *SemaphoreFeature {
+	once.Do(func() {
+		instance = &SemaphoreFeature{
+			semaphores: make(map[string]*namedSemaphore),
+		}
+	})
+
+	return instance
+}
+
+func (f *SemaphoreFeature) Handle(task *tasks.Task) {
+	startTime := time.Now()
+	message := task.GetMessage()
+
+	var payload payloads.AcquirePayload
+
+	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse payload", err)))
+
+		return
+	}
+
+	target, errorPayload := f.lookup(payload)
+
+	if target == nil {
+		task.AddResult(dto.NewErrorResult(message, errorPayload))
+
+		return
+	}
+
+	// Waiting is bounded by the task context: its deadline, a cancelTask or a
+	// flow stop end it with the matching error.
+	if err := target.weighted.Acquire(task.GetContext(), 1); err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("acquire "+payload.Name, context.Cause(task.GetContext()))))
+
+		return
+	}
+
+	// Holding is bound to the flow, not to the acquire task: the task deadline
+	// only limits the wait, while a flow stop returns the permit.
+	flowCtx := task.GetFlowContext()
+
+	holder := &permitState{
+		semaphore: target,
+		message:   message,
+		startTime: startTime,
+		stopWatch: context.AfterFunc(flowCtx, func() {
+			states.Get().DeleteState(message.TaskKey)
+		}),
+	}
+
+	if err := states.Get().Register(message.TaskKey, holder); err != nil {
+		holder.release()
+
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("register permit", err)))
+
+		return
+	}
+
+	// The flow may have stopped before the permit was registered: the watch
+	// above then found nothing to delete.
+	if flowCtx.Err() != nil {
+		states.Get().DeleteState(message.TaskKey)
+	}
+
+	// A task cancelled meanwhile keeps its cancelled answer: nobody will ever
+	// release this permit, so return it right away.
+	if !task.AddResult(dto.NewSuccessResultWithNext(message, "", helpers.CalcExecutionMs(startTime))) {
+		states.Get().DeleteState(message.TaskKey)
+	}
+}
+
+// lookup returns the semaphore of that name, creating it on first use, or nil and
+// the error payload to answer with. Semaphores live as long as the worker; every
+// acquire of a name must agree on its permits.
+func (f *SemaphoreFeature) lookup(payload payloads.AcquirePayload) (*namedSemaphore, string) {
+	if payload.Name == "" {
+		return nil, errFactory.ByText("name must not be empty")
+	}
+
+	if payload.Permits < 1 || payload.Permits > maxPermits {
+		return nil, errFactory.ByText(fmt.Sprintf("permits must be between 1 and %d", maxPermits))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	found, ok := f.semaphores[payload.Name]
+
+	if !ok {
+		found = &namedSemaphore{
+			permits:  payload.Permits,
+			weighted: semaphore.NewWeighted(payload.Permits),
+		}
+
+		f.semaphores[payload.Name] = found
+	} else if found.permits != payload.Permits {
+		return nil, errFactory.ByText(
+			fmt.Sprintf("semaphore %s exists with %d permits", payload.Name, found.permits),
+		)
+	}
+
+	return found, ""
+}
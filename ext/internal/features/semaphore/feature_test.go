@@ -0,0 +1,210 @@
+package semaphore_feature_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/semaphore/payloads"
+	"sconcur/internal/flows"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// testFlow drives a flows.Flow the way the handler does: messages in, results
+// pulled from the shared channel with the post-delivery bookkeeping applied.
+type testFlow struct {
+	t       *testing.T
+	flow    *flows.Flow
+	results chan *dto.Result
+}
+
+func newTestFlow(t *testing.T, key string) *testFlow {
+	results := make(chan *dto.Result, 8)
+
+	return &testFlow{t: t, flow: flows.NewFlow(context.Background(), key, results), results: results}
+}
+
+func (f *testFlow) acquire(taskKey string, name string, permits int64, timeoutMs int) {
+	f.t.Helper()
+
+	payload, err := msgpack.Marshal(payloads.AcquirePayload{Name: name, Permits: permits})
+
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	f.handle(&dto.Message{Method: types.MethodSemaphore, TaskKey: taskKey, Payload: payload, TimeoutMs: timeoutMs})
+}
+
+// release issues the next() on the acquire task key, as PHP does.
+func (f *testFlow) release(taskKey string) {
+	f.t.Helper()
+
+	f.handle(&dto.Message{TaskKey: taskKey, IsNext: true})
+}
+
+func (f *testFlow) handle(msg *dto.Message) {
+	f.t.Helper()
+
+	msg.FlowKey = "flow"
+
+	if err := f.flow.HandleMessage(msg); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *testFlow) receive() *dto.Result {
+	f.t.Helper()
+
+	select {
+	case result := <-f.results:
+		f.flow.OnDelivered(result)
+
+		return result
+	case <-time.After(time.Second):
+		f.t.Fatal("no result delivered in time")
+
+		return nil
+	}
+}
+
+func (f *testFlow) expectGranted(taskKey string) {
+	f.t.Helper()
+
+	result := f.receive()
+
+	if result.TaskKey != taskKey || result.IsError || !result.HasNext {
+		f.t.Fatalf("expected %s to hold a permit, got %+v", taskKey, result)
+	}
+}
+
+func (f *testFlow) expectNothing() {
+	f.t.Helper()
+
+	select {
+	case result := <-f.results:
+		f.t.Fatalf("expected the acquire to wait, got %+v", result)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestWaitersAreGrantedInArrivalOrderAsPermitsReturn(t *testing.T) {
+	holder := newTestFlow(t, "holder")
+	waiter := newTestFlow(t, "waiter")
+
+	holder.acquire("h-1", "order", 2, 0)
+	holder.expectGranted("h-1")
+	holder.acquire("h-2", "order", 2, 0)
+	holder.expectGranted("h-2")
+
+	waiter.acquire("w-1", "order", 2, 0)
+	waiter.expectNothing()
+	waiter.acquire("w-2", "order", 2, 0)
+	waiter.expectNothing()
+
+	holder.release("h-1")
+
+	if released := holder.receive(); released.IsError || released.HasNext {
+		t.Fatalf("expected the final release result, got %+v", released)
+	}
+
+	waiter.expectGranted("w-1")
+	waiter.expectNothing()
+
+	holder.release("h-2")
+	holder.receive()
+
+	waiter.expectGranted("w-2")
+
+	waiter.release("w-1")
+	waiter.receive()
+	waiter.release("w-2")
+	waiter.receive()
+}
+
+func TestAcquireDeadlineAnswersTimeoutAndTakesNoPermit(t *testing.T) {
+	flow := newTestFlow(t, "flow")
+
+	flow.acquire("held", "deadline", 1, 0)
+	flow.expectGranted("held")
+
+	flow.acquire("late", "deadline", 1, 20)
+
+	result := flow.receive()
+
+	details, err := errs.Decode(result.Payload)
+
+	if !result.IsError || err != nil || details.Category != errs.CategoryTimeout {
+		t.Fatalf("expected a timeout for the late acquire, got %+v (%v)", result, err)
+	}
+
+	flow.release("held")
+	flow.receive()
+
+	flow.acquire("again", "deadline", 1, 0)
+	flow.expectGranted("again")
+	flow.release("again")
+	flow.receive()
+}
+
+func TestCancelledWaiterLeavesThePermitToTheNextOne(t *testing.T) {
+	flow := newTestFlow(t, "flow")
+
+	flow.acquire("held", "cancel", 1, 0)
+	flow.expectGranted("held")
+
+	flow.acquire("cancelled", "cancel", 1, 0)
+	flow.expectNothing()
+	flow.flow.CancelTask("cancelled")
+
+	if result := flow.receive(); !result.IsCancelled {
+		t.Fatalf("expected the cancelled answer, got %+v", result)
+	}
+
+	flow.acquire("next", "cancel", 1, 0)
+	flow.expectNothing()
+
+	flow.release("held")
+	flow.receive()
+
+	flow.expectGranted("next")
+	flow.release("next")
+	flow.receive()
+}
+
+func TestStoppedFlowReturnsItsPermits(t *testing.T) {
+	stopped := newTestFlow(t, "stopped")
+	waiter := newTestFlow(t, "waiter")
+
+	stopped.acquire("s-1", "stop", 1, 0)
+	stopped.expectGranted("s-1")
+
+	waiter.acquire("w-1", "stop", 1, 0)
+	waiter.expectNothing()
+
+	stopped.flow.Cancel()
+
+	waiter.expectGranted("w-1")
+	waiter.release("w-1")
+	waiter.receive()
+}
+
+func TestAcquireRejectsADifferentPermitCount(t *testing.T) {
+	flow := newTestFlow(t, "flow")
+
+	flow.acquire("first", "mismatch", 3, 0)
+	flow.expectGranted("first")
+
+	flow.acquire("second", "mismatch", 1, 0)
+
+	if result := flow.receive(); !result.IsError {
+		t.Fatalf("expected a permits mismatch error, got %+v", result)
+	}
+
+	flow.release("first")
+	flow.receive()
+}
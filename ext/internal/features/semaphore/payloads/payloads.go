@@ -0,0 +1,12 @@
+// Package payloads holds the Go counterparts of the PHP semaphore payload objects
+// (SConcur\Features\Semaphore\Payloads\*). The struct tags are the short keys
+// emitted by the PHP getData() methods.
+package payloads
+
+// AcquirePayload is the payload of an acquire: one permit of the semaphore Name,
+// which allows Permits holders at a time (1 for a mutex).
+// PHP: SConcur\Features\Semaphore\Payloads\AcquirePayload.
+type AcquirePayload struct {
+	Name    string `json:"n"  msgpack:"n"`
+	Permits int64  `json:"pm" msgpack:"pm"`
+}
@@ -0,0 +1,43 @@
+package semaphore_feature
+
+import (
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/helpers"
+	"sync"
+	"time"
+)
+
+// permitState holds one acquired permit under the acquire task key, keeping the
+// acquire task alive (hasNext) until PHP releases it. Its Next is the release
+// pulled by PHP; Close is the safety net run when the flow stops. The permit is returned exactly once either way.
+var _ contracts.HeldStateContract = (*permitState)(nil)
+
+type permitState struct {
+	semaphore   *namedSemaphore
+	message     *dto.Message
+	startTime   time.Time
+	stopWatch   func() bool
+	releaseOnce sync.Once
+}
+
+func (p *permitState) Next() *dto.Result {
+	p.release()
+
+	return dto.NewSuccessResult(p.message, "", helpers.CalcExecutionMs(p.startTime))
+}
+
+func (p *permitState) Close() {
+	p.release()
+}
+
+// Held keeps an acquired permit from the idle reaper: closing it would hand the
+// permit to another holder while this one still uses it.
+func (p *permitState) Held() {}
+
+func (p *permitState) release() {
+	p.releaseOnce.Do(func() {
+		p.stopWatch()
+		p.semaphore.weighted.Release(1)
+	})
+}
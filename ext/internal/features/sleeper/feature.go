@@ -43,7 +43,7 @@ func (s *SleepFeature) Handle(task *tasks.Task) {
 		task.AddResult(
 			dto.NewErrorResult(
 				message,
-				errFactory.ByErr("parse error", err),
+				errFactory.ByInvalid("parse error", err),
 			),
 		)
 
@@ -63,12 +63,13 @@ func (s *SleepFeature) Handle(task *tasks.Task) {
 
 	select {
 	case <-task.GetContext().Done():
-		task.AddResult(
-			dto.NewErrorResult(
-				message,
-				errFactory.ByText("closed by task stop"),
-			),
-		)
+		if cause := errs.CauseOf(task.GetContext()); cause != nil {
+			task.AddResult(dto.NewStoppedResult(message, cause))
+
+			return
+		}
+
+		task.AddResult(dto.NewCancelledResult(message, "closed by task stop"))
 	case <-time.After(time.Duration(payload.Microseconds) * time.Microsecond):
 		task.AddResult(
 			dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)),
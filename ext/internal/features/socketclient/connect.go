@@ -126,7 +126,7 @@ func (f *SocketClientFeature) handleConnect(task *tasks.Task, raw msgpack.RawMes
 	var payload payloads.ConnectParams
 
 	if err := msgpack.Unmarshal(raw, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse connect params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse connect params", err)))
 
 		return
 	}
@@ -152,7 +152,7 @@ func (f *SocketClientFeature) handleConnect(task *tasks.Task, raw msgpack.RawMes
 
 	if err != nil {
 		// Connection refused / DNS failure / dial timeout: a network-class error.
-		task.AddResult(dto.NewErrorResult(message, networkErrorPayload(err.Error())))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByNetwork("connect", err)))
 
 		return
 	}
@@ -2,10 +2,14 @@ package socketclient_feature
 
 import (
 	"bufio"
+	"context"
 	"net"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features/socketclient/payloads"
 	"sconcur/internal/socket"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
 	"testing"
 	"time"
 
@@ -159,3 +163,23 @@ func TestConnectionStateCloseRunsCleanup(t *testing.T) {
 		t.Fatal("Close must run the cleanup hook")
 	}
 }
+
+// TestMalformedPayloadIsAValidationError checks a payload that does not decode is
+// reported as an invalid command, not as an internal failure.
+func TestMalformedPayloadIsAValidationError(t *testing.T) {
+	results := make(chan *dto.Result, 1)
+	message := &dto.Message{Method: types.MethodSocketClient, FlowKey: "f", TaskKey: "t", Payload: []byte{0xc1}}
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	result := <-results
+	details, err := errs.Decode(result.Payload)
+
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if !result.IsError || details.Category != errs.CategoryValidation {
+		t.Fatalf("category %q, want %q", details.Category, errs.CategoryValidation)
+	}
+}
@@ -22,10 +22,6 @@ var instance *SocketClientFeature
 
 var errFactory = errs.NewErrorsFactory("socketClient")
 
-// networkErrorMarker is prefixed onto a dial-failure payload so the PHP side maps it
-// to SocketClientConnectException (mirrors the HTTP client's net:/req: markers).
-const networkErrorMarker = "net"
-
 // pendingConnections maps a connectionId to the rendezvous its write loop waits on
 // for the PHP handler's send/close commands. Keyed globally so a Send/Close command
 // (arriving on a different flow) can find it.
@@ -50,7 +46,7 @@ func (f *SocketClientFeature) Handle(task *tasks.Task) {
 	var envelope payloads.Envelope
 
 	if err := msgpack.Unmarshal(message.Payload, &envelope); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse envelope", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse envelope", err)))
 
 		return
 	}
@@ -74,7 +70,7 @@ func (f *SocketClientFeature) handleSend(task *tasks.Task, raw msgpack.RawMessag
 	var params payloads.SendParams
 
 	if err := msgpack.Unmarshal(raw, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse send params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse send params", err)))
 
 		return
 	}
@@ -89,7 +85,7 @@ func (f *SocketClientFeature) handleClose(task *tasks.Task, raw msgpack.RawMessa
 	var params payloads.CloseParams
 
 	if err := msgpack.Unmarshal(raw, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse close params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse close params", err)))
 
 		return
 	}
@@ -134,7 +130,3 @@ func (f *SocketClientFeature) dispatch(task *tasks.Task, connectionId string, co
 
 	task.AddResult(dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)))
 }
-
-func networkErrorPayload(text string) string {
-	return networkErrorMarker + ": " + text
-}
@@ -64,7 +64,7 @@ func (f *SocketFeature) handleServe(task *tasks.Task) {
 	var payload payloads.ServePayload
 
 	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse serve payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse serve payload", err)))
 
 		return
 	}
@@ -110,7 +110,7 @@ func (f *SocketFeature) handleRespond(task *tasks.Task) {
 	}
 
 	if err := msgpack.Unmarshal(message.Payload, &idOnly); err != nil || idOnly.ConnectionId == "" {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond connectionId", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond connectionId", err)))
 
 		return
 	}
@@ -135,7 +135,7 @@ func (f *SocketFeature) handleRespond(task *tasks.Task) {
 	var payload payloads.RespondPayload
 
 	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond payload", err)))
 
 		return
 	}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sconcur/internal/crashes"
 	"sconcur/internal/dto"
 	"sconcur/internal/features/socketserver/payloads"
 	"sconcur/internal/helpers"
@@ -58,11 +59,11 @@ type serverConfig struct {
 // 0 as "disabled".
 func configFromPayload(payload payloads.ServePayload) serverConfig {
 	return serverConfig{
-		readTimeout:     time.Duration(max(payload.ReadTimeoutMs, 0)) * time.Millisecond,
-		writeTimeout:    msOrDefault(payload.WriteTimeoutMs, defaultWriteTimeout),
-		shutdownTimeout: msOrDefault(payload.ShutdownTimeoutMs, defaultShutdownTimeout),
-		maxMessageBytes: intOrDefault(payload.MaxMessageBytes, defaultMaxMessageBytes),
-		maxConcurrency:  max(payload.MaxConcurrency, 0),
+		readTimeout:         time.Duration(max(payload.ReadTimeoutMs, 0)) * time.Millisecond,
+		writeTimeout:        msOrDefault(payload.WriteTimeoutMs, defaultWriteTimeout),
+		shutdownTimeout:     msOrDefault(payload.ShutdownTimeoutMs, defaultShutdownTimeout),
+		maxMessageBytes:     intOrDefault(payload.MaxMessageBytes, defaultMaxMessageBytes),
+		maxConcurrency:      max(payload.MaxConcurrency, 0),
 		telemetrySocket:     payload.TelemetrySocket,
 		serverName:          payload.ServerName,
 		telemetryIntervalMs: payload.TelemetryIntervalMs,
@@ -200,6 +201,15 @@ func (s *serverState) handleConn(conn net.Conn) {
 
 	connectionId := socket.NextConnectionId(s.message.FlowKey)
 
+	// A panic while serving the connection is recorded in the crash log instead of
+	// aborting the PHP process; the teardown below has already closed the
+	// connection by then.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			crashes.Get().Record(crashes.NewReport(crashes.KindServer, s.message, connectionId, recovered))
+		}
+	}()
+
 	pending := &socket.PendingConnection{
 		Conn:      conn,
 		Commands:  make(chan socket.WriteCommand),
@@ -258,7 +268,7 @@ func (s *serverState) Next() *dto.Result {
 		serialized, err := msgpack.Marshal(event)
 
 		if err != nil {
-			return dto.NewErrorResult(s.message, "socketServer: marshal connection: "+err.Error())
+			return dto.NewErrorResult(s.message, errFactory.ByErr("marshal connection", err))
 		}
 
 		return dto.NewSuccessResultWithNext(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
@@ -1,15 +1,22 @@
 package sql_feature
 
 import (
+	"errors"
+	"sconcur/internal/errs"
+	"strconv"
 	"sync"
 
 	// Registers the "mysql" driver with database/sql for its side effect.
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 var mysqlOnce sync.Once
 var mysqlInstance *SqlFeature
 
+func init() {
+	errs.RegisterClassifier(classifyMysqlError)
+}
+
 // GetMysql returns the singleton SQL feature bound to the MySQL driver.
 func GetMysql() *SqlFeature {
 	mysqlOnce.Do(func() {
@@ -18,3 +25,28 @@ func GetMysql() *SqlFeature {
 
 	return mysqlInstance
 }
+
+// classifyMysqlError fills in the server errno of a MySQL error. A deadlock
+// (1213) or a lock wait timeout (1205) rolled the statement back and is safe to
+// retry.
+func classifyMysqlError(err error, details *errs.Details) bool {
+	var mysqlError *mysql.MySQLError
+
+	if !errors.As(err, &mysqlError) {
+		return false
+	}
+
+	details.Code, details.Category = errs.CodeDriver, errs.CategoryDriver
+	details.DriverCode = strconv.Itoa(int(mysqlError.Number))
+
+	switch mysqlError.Number {
+	case 1062:
+		details.Code = errs.CodeDuplicateKey
+	case 1213:
+		details.Code, details.Retryable = errs.CodeDeadlock, true
+	case 1205:
+		details.Code, details.Retryable = errs.CodeLockTimeout, true
+	}
+
+	return true
+}
@@ -1,8 +1,12 @@
 package sql_feature
 
 import (
+	"errors"
+	"sconcur/internal/errs"
 	"sync"
 
+	"github.com/jackc/pgx/v5/pgconn"
+
 	// Registers the "pgx" driver with database/sql for its side effect.
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
@@ -10,6 +14,10 @@ import (
 var pgsqlOnce sync.Once
 var pgsqlInstance *SqlFeature
 
+func init() {
+	errs.RegisterClassifier(classifyPgsqlError)
+}
+
 // GetPgsql returns the singleton SQL feature bound to the PostgreSQL driver (pgx).
 // Errors are labelled "pgsql" even though the database/sql driver name is "pgx".
 func GetPgsql() *SqlFeature {
@@ -19,3 +27,30 @@ func GetPgsql() *SqlFeature {
 
 	return pgsqlInstance
 }
+
+// classifyPgsqlError fills in the SQLSTATE of a PostgreSQL error. A deadlock
+// (40P01) or a serialization failure (40001) aborted the transaction and is safe
+// to retry as a whole.
+func classifyPgsqlError(err error, details *errs.Details) bool {
+	var pgError *pgconn.PgError
+
+	if !errors.As(err, &pgError) {
+		return false
+	}
+
+	details.Code, details.Category = errs.CodeDriver, errs.CategoryDriver
+	details.DriverCode = pgError.Code
+
+	switch pgError.Code {
+	case "23505":
+		details.Code = errs.CodeDuplicateKey
+	case "40P01":
+		details.Code, details.Retryable = errs.CodeDeadlock, true
+	case "40001":
+		details.Code, details.Retryable = errs.CodeSerialization, true
+	case "55P03":
+		details.Code, details.Retryable = errs.CodeLockTimeout, true
+	}
+
+	return true
+}
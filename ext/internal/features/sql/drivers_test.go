@@ -0,0 +1,38 @@
+package sql_feature
+
+import (
+	"fmt"
+	"sconcur/internal/errs"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDriverErrorsCarryNativeCodes(t *testing.T) {
+	cases := map[string]struct {
+		err        error
+		code       string
+		driverCode string
+		retryable  bool
+	}{
+		"mysql duplicate": {&mysql.MySQLError{Number: 1062}, errs.CodeDuplicateKey, "1062", false},
+		"mysql deadlock":  {&mysql.MySQLError{Number: 1213}, errs.CodeDeadlock, "1213", true},
+		"mysql other":     {&mysql.MySQLError{Number: 1146}, errs.CodeDriver, "1146", false},
+		"pgsql duplicate": {&pgconn.PgError{Code: "23505"}, errs.CodeDuplicateKey, "23505", false},
+		"pgsql serialize": {&pgconn.PgError{Code: "40001"}, errs.CodeSerialization, "40001", true},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			details := errs.Classify(fmt.Errorf("exec: %w", testCase.err), "m")
+
+			if details.Category != errs.CategoryDriver ||
+				details.Code != testCase.code ||
+				details.DriverCode != testCase.driverCode ||
+				details.Retryable != testCase.retryable {
+				t.Fatalf("got %+v", details)
+			}
+		})
+	}
+}
@@ -50,7 +50,7 @@ func (f *SqlFeature) Handle(task *tasks.Task) {
 	var envelope payloads.Envelope
 
 	if err := msgpack.Unmarshal(message.Payload, &envelope); err != nil {
-		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByErr("parse envelope", err)))
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse envelope", err)))
 
 		return
 	}
@@ -80,7 +80,13 @@ func (f *SqlFeature) handleQuery(task *tasks.Task, envelope *payloads.Envelope)
 	var params payloads.QueryParams
 
 	if err := msgpack.Unmarshal(envelope.Data, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByErr("parse query params", err)))
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse query params", err)))
+
+		return
+	}
+
+	if err := states.CheckPrefetchDepth(params.PrefetchDepth); err != nil {
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse query params", err)))
 
 		return
 	}
@@ -154,7 +160,7 @@ func (f *SqlFeature) handleQuery(task *tasks.Task, envelope *payloads.Envelope)
 		errFactory: f.errFactory,
 	}
 
-	result, err := states.Get().Start(ctx, message.TaskKey, state)
+	result, err := states.Get().Start(ctx, message.TaskKey, states.Prefetch(state, message, params.PrefetchDepth))
 
 	if err != nil {
 		state.Close()
@@ -175,7 +181,7 @@ func (f *SqlFeature) handleExec(task *tasks.Task, envelope *payloads.Envelope) {
 	var params payloads.ExecParams
 
 	if err := msgpack.Unmarshal(envelope.Data, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByErr("parse exec params", err)))
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse exec params", err)))
 
 		return
 	}
@@ -28,6 +28,8 @@ type QueryParams struct {
 	Bindings      []any  `json:"b"  msgpack:"b"`
 	TransactionId string `json:"tx" msgpack:"tx"`
 	BatchSize     int    `json:"bs" msgpack:"bs"`
+	// PrefetchDepth is how many row batches are read ahead in the background (0 = none).
+	PrefetchDepth int `json:"pf" msgpack:"pf"`
 }
 
 // ExecParams is the body of an Exec command (the `dt`).
@@ -3,6 +3,7 @@ package sql_feature
 import (
 	"context"
 	"database/sql"
+	"sconcur/internal/contracts"
 	"sconcur/internal/dto"
 	"sconcur/internal/features/sql/payloads"
 	"sconcur/internal/helpers"
@@ -64,6 +65,8 @@ func (s *transactionSession) cleanup() {
 // pinned connection survives across the transaction's commands. Its Next is the
 // release marker pulled by PHP after commit/rollback; Close rolls back as a safety
 // net (a no-op once the transaction was already finalized).
+var _ contracts.HeldStateContract = (*transactionHolderState)(nil)
+
 type transactionHolderState struct {
 	session   *transactionSession
 	message   *dto.Message
@@ -78,6 +81,10 @@ func (h *transactionHolderState) Close() {
 	_ = h.session.rollback()
 }
 
+// Held keeps an open transaction from the idle reaper: closing it would roll the
+// transaction back behind PHP's back.
+func (h *transactionHolderState) Held() {}
+
 // handleBegin opens a transaction on a pooled connection and registers the holder
 // state. The result carries hasNext so the begin task's context stays alive for the
 // whole transaction; when that context is cancelled (flow stop), database/sql rolls
@@ -89,7 +96,7 @@ func (f *SqlFeature) handleBegin(task *tasks.Task, envelope *payloads.Envelope)
 	var params payloads.BeginParams
 
 	if err := msgpack.Unmarshal(envelope.Data, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByErr("parse begin params", err)))
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse begin params", err)))
 
 		return
 	}
@@ -178,7 +185,7 @@ func (f *SqlFeature) handleFinalize(task *tasks.Task, envelope *payloads.Envelop
 	var params payloads.TransactionRefParams
 
 	if err := msgpack.Unmarshal(envelope.Data, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByErr("parse transaction ref", err)))
+		task.AddResult(dto.NewErrorResult(message, f.errFactory.ByInvalid("parse transaction ref", err)))
 
 		return
 	}
@@ -0,0 +1,80 @@
+package ticker_feature
+
+import (
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/features/ticker/payloads"
+	"sconcur/internal/states"
+	"sconcur/internal/tasks"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var _ contracts.FeatureContract = (*TickerFeature)(nil)
+
+var once sync.Once
+var instance *TickerFeature
+
+var errFactory = errs.NewErrorsFactory("ticker")
+
+// TickerFeature runs interval timers: each tick is a streamed result pulled by
+// next(), so a periodic PHP job waits on a drift-free schedule instead of looping
+// sleep. The ticker lives until MaxTicks is reached or the task/flow is stopped.
+type TickerFeature struct {
+}
+
+func Get() *TickerFeature {
+	once.Do(func() {
+		instance = &TickerFeature{}
+	})
+
+	return instance
+}
+
+func (f *TickerFeature) Handle(task *tasks.Task) {
+	message := task.GetMessage()
+
+	var payload payloads.TickerPayload
+
+	err := msgpack.Unmarshal(message.Payload, &payload)
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse error", err)))
+
+		return
+	}
+
+	if text := validatePayload(payload); text != "" {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByText(text)))
+
+		return
+	}
+
+	ctx := task.GetContext()
+
+	result, err := states.Get().Start(ctx, message.TaskKey, newTickState(ctx, message, payload))
+
+	if err != nil {
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("start ticker", err)))
+
+		return
+	}
+
+	task.AddResult(result)
+}
+
+// validatePayload returns the reason the ticker cannot start, or "" when it can.
+func validatePayload(payload payloads.TickerPayload) string {
+	switch {
+	case payload.PeriodMs <= 0:
+		return "period must be greater than zero"
+	case payload.InitialDelayMs != nil && *payload.InitialDelayMs < 0:
+		return "initial delay must not be negative"
+	case payload.MaxTicks < 0:
+		return "max ticks must not be negative"
+	}
+
+	return ""
+}
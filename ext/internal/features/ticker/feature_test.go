@@ -0,0 +1,145 @@
+package ticker_feature
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/features/ticker/payloads"
+	"sconcur/internal/tasks"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func decodeTick(t *testing.T, result *dto.Result) payloads.Tick {
+	t.Helper()
+
+	if result.IsError {
+		t.Fatalf("unexpected error result: %q", result.Payload)
+	}
+
+	var tick payloads.Tick
+
+	if err := msgpack.Unmarshal([]byte(result.Payload), &tick); err != nil {
+		t.Fatalf("decode tick: %v", err)
+	}
+
+	return tick
+}
+
+func TestHandleRejectsNonPositivePeriod(t *testing.T) {
+	data, err := msgpack.Marshal(payloads.TickerPayload{PeriodMs: 0})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := &dto.Message{Method: types.MethodTicker, FlowKey: "f", TaskKey: "ticker-invalid", Payload: data}
+	results := make(chan *dto.Result, 1)
+
+	Get().Handle(tasks.NewTask(context.Background(), results, message))
+
+	if result := <-results; !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+}
+
+// TestTicksStopAtMaxTicks checks ticks are numbered from 1 and the last one ends
+// the stream.
+func TestTicksStopAtMaxTicks(t *testing.T) {
+	state := newTickState(context.Background(), &dto.Message{}, payloads.TickerPayload{PeriodMs: 5, MaxTicks: 3})
+
+	for number := int64(1); number <= 3; number++ {
+		result := state.Next()
+		tick := decodeTick(t, result)
+
+		if tick.Number != number || tick.Missed != 0 {
+			t.Fatalf("tick %d: got number %d, missed %d", number, tick.Number, tick.Missed)
+		}
+
+		if result.HasNext != (number < 3) {
+			t.Fatalf("tick %d: unexpected HasNext %v", number, result.HasNext)
+		}
+	}
+}
+
+// TestTickReportsMissedSlots checks a late pull skips the slots it missed instead
+// of delivering them in a burst.
+func TestTickReportsMissedSlots(t *testing.T) {
+	state := newTickState(context.Background(), &dto.Message{}, payloads.TickerPayload{PeriodMs: 10})
+
+	decodeTick(t, state.Next())
+
+	time.Sleep(45 * time.Millisecond)
+
+	tick := decodeTick(t, state.Next())
+
+	if tick.Missed < 2 || tick.Number != 2+tick.Missed {
+		t.Fatalf("expected missed slots, got number %d, missed %d", tick.Number, tick.Missed)
+	}
+
+	// The schedule stays anchored: the tick after a late one is not delayed further.
+	next := decodeTick(t, state.Next())
+
+	if next.Number != tick.Number+1 || next.Missed != 0 {
+		t.Fatalf("expected slot %d, got number %d, missed %d", tick.Number+1, next.Number, next.Missed)
+	}
+}
+
+// TestZeroInitialDelayTicksAtOnce checks an explicit 0 fires the first tick right
+// away instead of waiting one period.
+func TestZeroInitialDelayTicksAtOnce(t *testing.T) {
+	state := newTickState(context.Background(), &dto.Message{}, payloads.TickerPayload{PeriodMs: 10_000, InitialDelayMs: new(int64(0))})
+
+	started := time.Now()
+
+	if tick := decodeTick(t, state.Next()); tick.Number != 1 {
+		t.Fatalf("first tick numbered %d", tick.Number)
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("first tick fired after %v, want at once", elapsed)
+	}
+}
+
+func TestInitialDelayDefersFirstTick(t *testing.T) {
+	state := newTickState(context.Background(), &dto.Message{}, payloads.TickerPayload{PeriodMs: 5, InitialDelayMs: new(int64(30))})
+
+	started := time.Now()
+
+	decodeTick(t, state.Next())
+
+	if elapsed := time.Since(started); elapsed < 25*time.Millisecond {
+		t.Fatalf("first tick fired after %v, before the initial delay", elapsed)
+	}
+}
+
+func TestCloseWakesBlockedNext(t *testing.T) {
+	state := newTickState(context.Background(), &dto.Message{}, payloads.TickerPayload{PeriodMs: 60_000})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+
+		state.Close()
+	}()
+
+	result := state.Next()
+
+	if !result.IsError || !result.IsCancelled {
+		t.Fatal("Close must answer the blocked next as cancelled")
+	}
+}
+
+func TestContextCancellationEndsNext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	state := newTickState(ctx, &dto.Message{}, payloads.TickerPayload{PeriodMs: 60_000})
+
+	cancel()
+
+	if result := state.Next(); !result.IsError {
+		t.Fatal("a cancelled task must end the ticker with an error")
+	}
+}
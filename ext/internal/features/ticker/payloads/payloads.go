@@ -0,0 +1,25 @@
+// Package payloads holds the Go counterparts of the PHP Ticker payload objects
+// (SConcur\Features\Ticker\Payloads\*). The struct tags are the short keys emitted by
+// the PHP getData() methods.
+package payloads
+
+// TickerPayload is the payload of a ticker: a tick every PeriodMs, the first one
+// after InitialDelayMs (one period when nil, at once when 0), MaxTicks ticks in
+// total (0 = until the task is stopped).
+// PHP: SConcur\Features\Ticker\Payloads\TickerPayload.
+type TickerPayload struct {
+	PeriodMs       int64  `json:"pm" msgpack:"pm"`
+	InitialDelayMs *int64 `json:"dm" msgpack:"dm"`
+	MaxTicks       int64  `json:"mt" msgpack:"mt"`
+}
+
+// Tick is the payload of one tick result. Number is the 1-based schedule slot the
+// tick fired for; Missed counts the slots skipped since the previous tick because
+// PHP pulled too late (the ticker never queues them up).
+// PHP: SConcur\Features\Ticker\Dto\TickDto.
+type Tick struct {
+	Number        int64 `json:"n"  msgpack:"n"`
+	Missed        int64 `json:"ms" msgpack:"ms"`
+	ScheduledAtMs int64 `json:"sa" msgpack:"sa"`
+	FiredAtMs     int64 `json:"fa" msgpack:"fa"`
+}
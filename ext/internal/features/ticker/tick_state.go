@@ -0,0 +1,117 @@
+package ticker_feature
+
+import (
+	"context"
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/features/ticker/payloads"
+	"sconcur/internal/helpers"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var _ contracts.StateContract = (*tickState)(nil)
+
+// tickState streams the ticks of one ticker; each Next blocks until the next
+// schedule slot. Slots are anchored to the first one (first + n*period), so the
+// schedule does not drift with the time PHP spends between pulls. Implements
+// contracts.StateContract.
+type tickState struct {
+	// mutex serializes Next calls; Close does not take it, so it never waits for a
+	// Next blocked on the timer.
+	mutex     sync.Mutex
+	ctx       context.Context
+	message   *dto.Message
+	startTime time.Time
+	first     time.Time
+	period    time.Duration
+	maxTicks  int64
+	// last is the slot number of the latest delivered tick.
+	last      int64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newTickState(ctx context.Context, message *dto.Message, payload payloads.TickerPayload) *tickState {
+	startTime := time.Now()
+	period := time.Duration(payload.PeriodMs) * time.Millisecond
+
+	delay := period
+
+	if payload.InitialDelayMs != nil {
+		delay = time.Duration(*payload.InitialDelayMs) * time.Millisecond
+	}
+
+	return &tickState{
+		ctx:       ctx,
+		message:   message,
+		startTime: startTime,
+		first:     startTime.Add(delay),
+		period:    period,
+		maxTicks:  payload.MaxTicks,
+		done:      make(chan struct{}),
+	}
+}
+
+func (s *tickState) Next() *dto.Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	next := s.last + 1
+	scheduledAt := s.slotTime(next)
+
+	if wait := time.Until(scheduledAt); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-s.ctx.Done():
+			return dto.NewErrorResult(s.message, errFactory.ByErr("tick", context.Cause(s.ctx)))
+		case <-s.done:
+			return dto.NewCancelledResult(s.message, "ticker closed")
+		case <-timer.C:
+		}
+	}
+
+	now := time.Now()
+
+	// The latest slot already due: the ones between next and it were missed.
+	due := next + int64(now.Sub(scheduledAt)/s.period)
+
+	if s.maxTicks > 0 && due > s.maxTicks {
+		due = s.maxTicks
+	}
+
+	s.last = due
+
+	serialized, err := msgpack.Marshal(payloads.Tick{
+		Number:        due,
+		Missed:        due - next,
+		ScheduledAtMs: s.slotTime(due).UnixMilli(),
+		FiredAtMs:     now.UnixMilli(),
+	})
+
+	if err != nil {
+		return dto.NewErrorResult(s.message, errFactory.ByErr("marshal tick", err))
+	}
+
+	if s.maxTicks > 0 && due == s.maxTicks {
+		return dto.NewSuccessResult(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
+	}
+
+	return dto.NewSuccessResultWithNext(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
+}
+
+// slotTime is when the given 1-based slot is scheduled.
+func (s *tickState) slotTime(slot int64) time.Time {
+	return s.first.Add(time.Duration(slot-1) * s.period)
+}
+
+// Close stops the ticker, waking a Next blocked on the timer. Nothing else is held.
+func (s *tickState) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
@@ -157,7 +157,7 @@ func (f *WsClientFeature) handleConnect(task *tasks.Task, raw msgpack.RawMessage
 	var payload payloads.ConnectParams
 
 	if err := msgpack.Unmarshal(raw, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse connect params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse connect params", err)))
 
 		return
 	}
@@ -184,7 +184,7 @@ func (f *WsClientFeature) handleConnect(task *tasks.Task, raw msgpack.RawMessage
 	if err != nil {
 		// Connection refused / DNS failure / handshake failure / dial timeout: a
 		// network-class error.
-		task.AddResult(dto.NewErrorResult(message, networkErrorPayload(err.Error())))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByNetwork("connect", err)))
 
 		return
 	}
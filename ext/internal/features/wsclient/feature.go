@@ -22,10 +22,6 @@ var instance *WsClientFeature
 
 var errFactory = errs.NewErrorsFactory("wsClient")
 
-// networkErrorMarker is prefixed onto a dial-failure payload so the PHP side maps it to
-// WsClientConnectException (mirrors the socket client's net: marker).
-const networkErrorMarker = "net"
-
 // pendingConnections maps a connectionId to the rendezvous its write loop waits on for
 // the PHP handler's send/close commands. Keyed globally so a Send/Close command
 // (arriving on a different flow) can find it.
@@ -50,7 +46,7 @@ func (f *WsClientFeature) Handle(task *tasks.Task) {
 	var envelope payloads.Envelope
 
 	if err := msgpack.Unmarshal(message.Payload, &envelope); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse envelope", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse envelope", err)))
 
 		return
 	}
@@ -74,7 +70,7 @@ func (f *WsClientFeature) handleSend(task *tasks.Task, raw msgpack.RawMessage) {
 	var params payloads.SendParams
 
 	if err := msgpack.Unmarshal(raw, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse send params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse send params", err)))
 
 		return
 	}
@@ -93,7 +89,7 @@ func (f *WsClientFeature) handleClose(task *tasks.Task, raw msgpack.RawMessage)
 	var params payloads.CloseParams
 
 	if err := msgpack.Unmarshal(raw, &params); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse close params", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse close params", err)))
 
 		return
 	}
@@ -138,7 +134,3 @@ func (f *WsClientFeature) dispatch(task *tasks.Task, connectionId string, comman
 
 	task.AddResult(dto.NewSuccessResult(message, "", helpers.CalcExecutionMs(startTime)))
 }
-
-func networkErrorPayload(text string) string {
-	return networkErrorMarker + ": " + text
-}
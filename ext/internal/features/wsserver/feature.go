@@ -64,7 +64,7 @@ func (f *WsFeature) handleServe(task *tasks.Task) {
 	var payload payloads.ServePayload
 
 	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse serve payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse serve payload", err)))
 
 		return
 	}
@@ -109,7 +109,7 @@ func (f *WsFeature) handleRespond(task *tasks.Task) {
 	}
 
 	if err := msgpack.Unmarshal(message.Payload, &idOnly); err != nil || idOnly.ConnectionId == "" {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond connectionId", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond connectionId", err)))
 
 		return
 	}
@@ -134,7 +134,7 @@ func (f *WsFeature) handleRespond(task *tasks.Task) {
 	var payload payloads.RespondPayload
 
 	if err := msgpack.Unmarshal(message.Payload, &payload); err != nil {
-		task.AddResult(dto.NewErrorResult(message, errFactory.ByErr("parse respond payload", err)))
+		task.AddResult(dto.NewErrorResult(message, errFactory.ByInvalid("parse respond payload", err)))
 
 		return
 	}
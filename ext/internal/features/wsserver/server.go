@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sconcur/internal/crashes"
 	"sconcur/internal/dto"
 	"sconcur/internal/features/wsserver/payloads"
 	"sconcur/internal/helpers"
@@ -270,6 +271,14 @@ func (s *serverState) handleConn(start time.Time, request *http.Request, conn *w
 
 	connectionId := socket.NextConnectionId(s.message.FlowKey)
 
+	// A panic while serving the connection is recorded in the crash log; the
+	// teardown below has already closed the connection by then.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			crashes.Get().Record(crashes.NewReport(crashes.KindServer, s.message, connectionId, recovered))
+		}
+	}()
+
 	pending := &ws.PendingConnection{
 		Conn:      conn,
 		Commands:  make(chan ws.WriteCommand),
@@ -372,7 +381,7 @@ func (s *serverState) Next() *dto.Result {
 		serialized, err := msgpack.Marshal(event)
 
 		if err != nil {
-			return dto.NewErrorResult(s.message, "wsServer: marshal connection: "+err.Error())
+			return dto.NewErrorResult(s.message, errFactory.ByErr("marshal connection", err))
 		}
 
 		return dto.NewSuccessResultWithNext(s.message, string(serialized), helpers.CalcExecutionMs(s.startTime))
@@ -4,38 +4,65 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"sconcur/internal/crashes"
 	"sconcur/internal/dto"
-	"sconcur/internal/features"
+	"sconcur/internal/errs"
+	"sconcur/internal/faults"
+	"sconcur/internal/readiness"
 	"sconcur/internal/states"
 	"sconcur/internal/tasks"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Flow struct {
 	mutex     sync.Mutex
 	ctx       context.Context
-	ctxCancel context.CancelFunc
+	ctxCancel context.CancelCauseFunc
 	key       string
+	createdAt time.Time
 
 	activeTasks map[string]*tasks.Task
 	tasksCount  atomic.Int32
 	results     chan *dto.Result
+
+	// streams holds the keys of delivered tasks that left a streaming state open
+	// (a cursor, a response body), each with the task that opened it: that task's
+	// context owns the state, so CancelTask and the stream end must cancel it.
+	streams map[string]*tasks.Task
+
+	resolve  Resolver
+	notifier *readiness.Notifier
+
+	// awaitedByKey marks a flow whose results are claimed by the per-flow
+	// Handler.Wait (the PHP sync path) rather than by an async consumer.
+	awaitedByKey atomic.Bool
 }
 
 // NewFlow builds a flow that publishes task results into the shared results
 // channel owned by the handler. All flows write to the same channel so the PHP
 // side can wait for any flow's result at once (waitAny), which is what lets
 // nested coroutines run concurrently with the outer flow.
+//
+// The flow context is cancelled with a cause (see Stop), which every task context
+// derived from it reports through context.Cause. Messages are resolved with
+// ResolveFeature and results signal no readiness descriptor; a Flows hands its
+// own Resolver and Notifier to the flows it creates.
 func NewFlow(handlerCtx context.Context, key string, results chan *dto.Result) *Flow {
-	ctx, ctxCancel := context.WithCancel(handlerCtx)
+	ctx, ctxCancel := context.WithCancelCause(handlerCtx)
 
 	return &Flow{
 		ctx:         ctx,
 		ctxCancel:   ctxCancel,
 		key:         key,
+		createdAt:   time.Now(),
 		activeTasks: make(map[string]*tasks.Task),
+		streams:     make(map[string]*tasks.Task),
 		results:     results,
+		resolve:     ResolveFeature,
 	}
 }
 
@@ -48,39 +75,57 @@ func NewFlow(handlerCtx context.Context, key string, results chan *dto.Result) *
 // a cancelled context cannot be reused. Called only from Flows.InitFlow, which
 // holds the Flows lock and only pools flows already detached from the registry.
 func (f *Flow) reset(handlerCtx context.Context, key string, results chan *dto.Result) {
-	ctx, ctxCancel := context.WithCancel(handlerCtx)
+	ctx, ctxCancel := context.WithCancelCause(handlerCtx)
 
 	f.ctx = ctx
 	f.ctxCancel = ctxCancel
 	f.key = key
+	f.createdAt = time.Now()
 	f.results = results
 
 	clear(f.activeTasks)
+	clear(f.streams)
 	f.tasksCount.Store(0)
+	f.awaitedByKey.Store(false)
 }
 
 func (f *Flow) HandleMessage(msg *dto.Message) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
+	return f.handleMessage(msg)
+}
+
+// HandleMessages registers a batch of messages under a single lock acquisition and
+// returns one error per message (nil when accepted): a rejected message does not
+// stop the rest of the batch.
+func (f *Flow) HandleMessages(msgs []*dto.Message) []error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	failures := make([]error, len(msgs))
+
+	for i, msg := range msgs {
+		failures[i] = f.handleMessage(msg)
+	}
+
+	return failures
+}
+
+// handleMessage starts a task for msg. Called with f.mutex held.
+func (f *Flow) handleMessage(msg *dto.Message) error {
 	// Resolve the handler before mutating flow state: a task registered for
 	// a message that will never run would corrupt the tasks accounting and
 	// leave PHP waiting forever.
-	var handle func(task *tasks.Task)
-
-	if msg.IsNext {
-		handle = states.Get().Next
-	} else {
-		handler, err := features.DetectMessageHandler(msg.Method)
+	handle, err := f.resolve(msg)
 
-		if err != nil {
-			return err
-		}
-
-		handle = handler.Handle
+	if err != nil {
+		return err
 	}
 
-	task := tasks.NewTask(f.ctx, f.results, msg)
+	handle = faults.Wrap(msg, handle)
+
+	task := tasks.NewTaskWithDeadline(f.ctx, f.results, f.notifier, msg, taskDeadline(msg))
 
 	f.activeTasks[msg.TaskKey] = task
 	f.tasksCount.Add(1)
@@ -90,15 +135,38 @@ func (f *Flow) HandleMessage(msg *dto.Message) error {
 	return nil
 }
 
+// taskDeadline resolves the deadline a task runs under: the message's own
+// TimeoutMs, counted from now, or — for a next() without one — the deadline of the
+// task that opened the streaming state, so a bounded cursor or response stream
+// stays bounded batch after batch. Zero means no deadline.
+func taskDeadline(msg *dto.Message) time.Time {
+	if msg.TimeoutMs > 0 {
+		return time.Now().Add(time.Duration(msg.TimeoutMs) * time.Millisecond)
+	}
+
+	if msg.IsNext {
+		if deadline, ok := states.Get().GetDeadline(msg.TaskKey); ok {
+			return deadline
+		}
+	}
+
+	return time.Time{}
+}
+
 // runTaskProtected converts a panic into a task error result:
 // an unrecovered panic in a c-shared library aborts the whole PHP process.
+// The panic is recorded in the crash log (see package crashes).
 func runTaskProtected(task *tasks.Task, handle func(task *tasks.Task)) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
+			crashes.Get().Record(
+				crashes.NewReport(crashes.KindTask, task.GetMessage(), task.GetMessage().TaskKey, recovered),
+			)
+
 			task.AddResult(
 				dto.NewErrorResult(
 					task.GetMessage(),
-					fmt.Sprintf("panic: %v\n%s", recovered, debug.Stack()),
+					errs.Panic(fmt.Sprintf("panic: %v\n%s", recovered, debug.Stack())),
 				),
 			)
 		}
@@ -113,7 +181,8 @@ func runTaskProtected(task *tasks.Task, handle func(task *tasks.Task)) {
 //
 // The initial task of a multi-batch find/aggregate is the exception: its
 // context owns the cursor state lifetime (states.Start hooks AfterFunc on it),
-// so it must live until the state is finished or the flow is stopped.
+// so it must live until the state is finished or the flow is stopped. The last
+// batch releases it along with the task that read that batch.
 func (f *Flow) OnDelivered(result *dto.Result) {
 	f.mutex.Lock()
 
@@ -122,21 +191,114 @@ func (f *Flow) OnDelivered(result *dto.Result) {
 	delete(f.activeTasks, result.TaskKey)
 	f.tasksCount.Add(-1)
 
+	var owner *tasks.Task
+
+	if result.HasNext {
+		if _, ok := f.streams[result.TaskKey]; !ok {
+			f.streams[result.TaskKey] = openerOf(task)
+		}
+	} else {
+		owner = f.streams[result.TaskKey]
+
+		delete(f.streams, result.TaskKey)
+	}
+
 	f.mutex.Unlock()
 
 	if task != nil && (task.GetMessage().IsNext || !result.HasNext) {
 		task.Cancel()
 	}
+
+	if owner != nil {
+		owner.Cancel()
+	}
+}
+
+// openerOf returns the task if it opened its stream, nil for a next-batch task.
+func openerOf(task *tasks.Task) *tasks.Task {
+	if task == nil || task.GetMessage().IsNext {
+		return nil
+	}
+
+	return task
+}
+
+// CancelTask aborts one task of the flow, leaving its siblings running: the task
+// context is cancelled, a streaming state keyed by the task (an open cursor, an
+// HTTP response body) is closed together with the context of the task that
+// opened it, and a still-active task is answered with a
+// cancelled result. The accounting is left to OnDelivered, as for any result. A
+// task whose result was already claimed keeps that result as its answer.
+//
+// A key the flow does not own — neither an active task nor a stream it read — is
+// ignored: one flow must not close another flow's cursor or transaction.
+func (f *Flow) CancelTask(taskKey string) {
+	f.mutex.Lock()
+	task := f.activeTasks[taskKey]
+	owner, streaming := f.streams[taskKey]
+	delete(f.streams, taskKey)
+	f.mutex.Unlock()
+
+	if task == nil && !streaming {
+		return
+	}
+
+	if task != nil {
+		task.CancelWithResult(dto.NewCancelledResult(task.GetMessage(), "task cancelled"))
+	}
+
+	// The opener's context bounds the state (and any producer reading ahead of
+	// it): cancel it first so the Close below does not wait on a live read.
+	if owner != nil {
+		owner.Cancel()
+	}
+
+	// Close outside the flow lock: a state Close may do network I/O (killCursors).
+	states.Get().DeleteState(taskKey)
+}
+
+// MarkAwaitedByKey records that the flow's results are claimed by key (see Stop).
+func (f *Flow) MarkAwaitedByKey() {
+	f.awaitedByKey.Store(true)
 }
 
 func (f *Flow) Count() int {
 	return int(f.tasksCount.Load())
 }
 
+// Cancel stops the flow with the generic stop cause.
 func (f *Flow) Cancel() {
+	f.Stop(errs.NewStopCause("", ""))
+}
+
+// Stop cancels the flow context with cause, so its running tasks unwind, and
+// answers every task still to answer with a cancelled result carrying the cause
+// (Task.Stopped). Those results are returned, ordered by task key, instead of
+// published: the flow is about to be forgotten, and the handler would drop a
+// published one as stale (see Handler.deliver).
+//
+// A flow awaited by key answers nothing: its only reader is the caller stopping
+// it, so the answers would never be claimed.
+func (f *Flow) Stop(cause *errs.StopCause) []*dto.Result {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	f.ctxCancel()
+	var stopped []*dto.Result
+
+	if !f.awaitedByKey.Load() {
+		for _, task := range f.activeTasks {
+			if result := task.Stopped(cause); result != nil {
+				stopped = append(stopped, result)
+			}
+		}
+	}
+
+	slices.SortFunc(stopped, func(left, right *dto.Result) int {
+		return strings.Compare(left.TaskKey, right.TaskKey)
+	})
+
+	f.ctxCancel(cause)
 	f.tasksCount.Store(0)
+
+	return stopped
 }
@@ -2,10 +2,13 @@ package flows
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/states"
 	"sconcur/internal/tasks"
 	"sconcur/internal/types"
 
@@ -108,6 +111,37 @@ func TestHandleMessageUnknownMethodLeavesFlowStateUntouched(t *testing.T) {
 	}
 }
 
+func TestHandleMessagesReportsPerMessageErrors(t *testing.T) {
+	flow, results := newTestFlow("flow")
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := flow.HandleMessages([]*dto.Message{
+		{FlowKey: "flow", Method: types.MethodSleep, TaskKey: "task-1", Payload: payload},
+		{FlowKey: "flow", Method: types.Method("nope"), TaskKey: "task-2"},
+		{FlowKey: "flow", Method: types.MethodSleep, TaskKey: "task-3", Payload: payload},
+	})
+
+	if len(failures) != 3 || failures[0] != nil || failures[1] == nil || failures[2] != nil {
+		t.Fatalf("want only the unknown method rejected, got %v", failures)
+	}
+
+	if flow.Count() != 2 {
+		t.Fatalf("want the two accepted tasks counted, got %d", flow.Count())
+	}
+
+	receive(t, flow, results)
+	receive(t, flow, results)
+
+	if flow.Count() != 0 {
+		t.Fatalf("want zero tasks after delivery, got %d", flow.Count())
+	}
+}
+
 func TestOnDeliveredKeepsInitialTaskContextWhileHasNext(t *testing.T) {
 	flow, results := newTestFlow("flow")
 
@@ -160,3 +194,277 @@ func TestOnDeliveredCancelsNextTaskContextEvenWithNext(t *testing.T) {
 		t.Fatal("next-task context does not own the cursor state and must be cancelled after delivery")
 	}
 }
+
+func TestCancelTaskAnswersCancelledAndKeepsSiblingsRunning(t *testing.T) {
+	flow, results := newTestFlow("flow")
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 50_000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, taskKey := range []string{"slow", "sibling"} {
+		msg := &dto.Message{
+			FlowKey: "flow",
+			Method:  types.MethodSleep,
+			TaskKey: taskKey,
+			Payload: payload,
+		}
+
+		if err := flow.HandleMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	flow.CancelTask("slow")
+
+	cancelled := receive(t, flow, results)
+
+	if cancelled.TaskKey != "slow" || !cancelled.IsError || !cancelled.IsCancelled {
+		t.Fatalf("expected a cancelled result for slow, got %+v", cancelled)
+	}
+
+	sibling := receive(t, flow, results)
+
+	if sibling.TaskKey != "sibling" || sibling.IsError {
+		t.Fatalf("expected the sibling to finish normally, got %+v", sibling)
+	}
+
+	select {
+	case extra := <-results:
+		t.Fatalf("a cancelled task must answer exactly once, got an extra %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if flow.Count() != 0 {
+		t.Fatalf("expected zero active tasks, got %d", flow.Count())
+	}
+}
+
+func TestExpiredTaskDeadlineAnswersTimeoutOnce(t *testing.T) {
+	flow, results := newTestFlow("flow")
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1_000_000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &dto.Message{
+		FlowKey:   "flow",
+		Method:    types.MethodSleep,
+		TaskKey:   "task-1",
+		Payload:   payload,
+		TimeoutMs: 20,
+	}
+
+	if err := flow.HandleMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	result := receive(t, flow, results)
+
+	if !result.IsError || result.Payload != dto.NewTimeoutResult(msg).Payload {
+		t.Fatalf("expected the uniform timeout result, got %+v", result)
+	}
+
+	select {
+	case extra := <-results:
+		t.Fatalf("an expired task must answer exactly once, got an extra %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestStopHandsItsCauseToRunningTasks(t *testing.T) {
+	flow, _ := newTestFlow("flow")
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1_000_000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &dto.Message{
+		FlowKey: "flow",
+		Method:  types.MethodSleep,
+		TaskKey: "slow",
+		Payload: payload,
+	}
+
+	if err := flow.HandleMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	task := flow.activeTasks["slow"]
+
+	flow.Stop(errs.NewStopCause("user_abort", "aborted by the user"))
+
+	select {
+	case <-task.GetContext().Done():
+	case <-time.After(time.Second):
+		t.Fatal("the task context must be cancelled with the flow")
+	}
+
+	cause := errs.CauseOf(task.GetContext())
+
+	if cause == nil || cause.Code != "user_abort" || cause.Message != "aborted by the user" {
+		t.Fatalf("expected the stop cause on the task context, got %+v", cause)
+	}
+}
+
+func TestStopAnswersNothingForAFlowAwaitedByKey(t *testing.T) {
+	flow, _ := newTestFlow("flow")
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1_000_000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &dto.Message{
+		FlowKey: "flow",
+		Method:  types.MethodSleep,
+		TaskKey: "slow",
+		Payload: payload,
+	}
+
+	if err := flow.HandleMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	task := flow.activeTasks["slow"]
+
+	flow.MarkAwaitedByKey()
+
+	if stopped := flow.Stop(errs.NewStopCause("user_abort", "")); len(stopped) != 0 {
+		t.Fatalf("expected no answers for a flow awaited by key, got %+v", stopped)
+	}
+
+	if cause := errs.CauseOf(task.GetContext()); cause == nil || cause.Code != "user_abort" {
+		t.Fatalf("the task context must still carry the stop cause, got %+v", cause)
+	}
+}
+
+// closingState records its Close, standing for a cursor or a transaction.
+type closingState struct {
+	closed atomic.Bool
+}
+
+func (s *closingState) Next() *dto.Result {
+	return nil
+}
+
+func (s *closingState) Close() {
+	s.closed.Store(true)
+}
+
+func TestCancelTaskClosesOnlyTheFlowsOwnStates(t *testing.T) {
+	flow, _ := newTestFlow("flow")
+
+	foreign := &closingState{}
+	own := &closingState{}
+
+	for key, state := range map[string]*closingState{"foreign-cursor": foreign, "own-cursor": own} {
+		if err := states.Get().Register(key, state); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { states.Get().DeleteState(key) })
+	}
+
+	// The flow read a first batch of its own cursor; the other one belongs to
+	// another flow.
+	flow.OnDelivered(&dto.Result{FlowKey: "flow", TaskKey: "own-cursor", HasNext: true})
+
+	flow.CancelTask("foreign-cursor")
+
+	if foreign.closed.Load() || states.Get().GetState("foreign-cursor") == nil {
+		t.Fatal("a flow must not close a state it does not own")
+	}
+
+	flow.CancelTask("own-cursor")
+
+	if !own.closed.Load() {
+		t.Fatal("the flow's own open cursor must be closed")
+	}
+}
+
+func TestCancelTaskCancelsTheTaskThatOpenedTheStream(t *testing.T) {
+	flow, results := newTestFlow("flow")
+
+	open := &dto.Message{FlowKey: "flow", TaskKey: "cursor"}
+	next := &dto.Message{FlowKey: "flow", TaskKey: "cursor", IsNext: true}
+
+	opener := tasks.NewTask(flow.ctx, flow.results, open)
+
+	flow.mutex.Lock()
+	flow.activeTasks[open.TaskKey] = opener
+	flow.tasksCount.Add(1)
+	flow.mutex.Unlock()
+
+	go opener.AddResult(dto.NewSuccessResultWithNext(open, "", 0))
+
+	receive(t, flow, results)
+
+	// A next batch must not take over the stream: the opener still owns it.
+	reader := tasks.NewTask(flow.ctx, flow.results, next)
+
+	flow.mutex.Lock()
+	flow.activeTasks[next.TaskKey] = reader
+	flow.tasksCount.Add(1)
+	flow.mutex.Unlock()
+
+	go reader.AddResult(dto.NewSuccessResultWithNext(next, "", 0))
+
+	receive(t, flow, results)
+
+	select {
+	case <-opener.GetContext().Done():
+		t.Fatal("the opener context must stay alive while the stream is open")
+	default:
+	}
+
+	flow.CancelTask("cursor")
+
+	select {
+	case <-opener.GetContext().Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancelling a stream must cancel the context of the task that opened it")
+	}
+}
+
+func TestOnDeliveredCancelsTheOpenerWhenTheStreamEnds(t *testing.T) {
+	flow, results := newTestFlow("flow")
+
+	open := &dto.Message{FlowKey: "flow", TaskKey: "cursor"}
+	next := &dto.Message{FlowKey: "flow", TaskKey: "cursor", IsNext: true}
+
+	opener := tasks.NewTask(flow.ctx, flow.results, open)
+
+	flow.mutex.Lock()
+	flow.activeTasks[open.TaskKey] = opener
+	flow.tasksCount.Add(1)
+	flow.mutex.Unlock()
+
+	go opener.AddResult(dto.NewSuccessResultWithNext(open, "", 0))
+
+	receive(t, flow, results)
+
+	reader := tasks.NewTask(flow.ctx, flow.results, next)
+
+	flow.mutex.Lock()
+	flow.activeTasks[next.TaskKey] = reader
+	flow.tasksCount.Add(1)
+	flow.mutex.Unlock()
+
+	go reader.AddResult(dto.NewSuccessResult(next, "", 0))
+
+	receive(t, flow, results)
+
+	select {
+	case <-opener.GetContext().Done():
+	case <-time.After(time.Second):
+		t.Fatal("the last batch must release the context of the task that opened the stream")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/readiness"
 	"sync"
 )
 
@@ -18,11 +20,18 @@ type Flows struct {
 	// cancelled context cannot be reused). Safe because flow keys are never reused
 	// (see Flow.reset). Per-Flows so it is dropped with the handler on Destroy.
 	pool sync.Pool
+
+	resolve  Resolver
+	notifier *readiness.Notifier
 }
 
-func NewFlows() *Flows {
+// NewFlows builds an empty registry whose flows resolve their messages with
+// resolve and signal notifier for every result.
+func NewFlows(resolve Resolver, notifier *readiness.Notifier) *Flows {
 	return &Flows{
-		flows: make(map[string]*Flow),
+		flows:    make(map[string]*Flow),
+		resolve:  resolve,
+		notifier: notifier,
 	}
 }
 
@@ -47,7 +56,11 @@ func (f *Flows) acquire(handlerCtx context.Context, flowKey string, results chan
 	pooled := f.pool.Get()
 
 	if pooled == nil {
-		return NewFlow(handlerCtx, flowKey, results)
+		flow := NewFlow(handlerCtx, flowKey, results)
+		flow.resolve = f.resolve
+		flow.notifier = f.notifier
+
+		return flow
 	}
 
 	flow := pooled.(*Flow)
@@ -70,17 +83,19 @@ func (f *Flows) GetFlow(flowKey string) (*Flow, error) {
 	return nil, errors.New("flow not found")
 }
 
-func (f *Flows) DeleteFlow(flowKey string) {
+// DeleteFlow stops the flow with cause and forgets it. It returns the cancelled
+// results answering the tasks the flow still ran (see Flow.Stop).
+func (f *Flows) DeleteFlow(flowKey string, cause *errs.StopCause) []*dto.Result {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
 	flow, ok := f.flows[flowKey]
 
 	if !ok {
-		return
+		return nil
 	}
 
-	flow.Cancel()
+	stopped := flow.Stop(cause)
 	delete(f.flows, flowKey)
 
 	// Recycle the detached flow. Its key is retired for good (keys are never
@@ -88,6 +103,8 @@ func (f *Flows) DeleteFlow(flowKey string) {
 	// by a key GetFlow no longer knows and are dropped — they can never reach the
 	// struct once it is re-armed for a new key.
 	f.pool.Put(flow)
+
+	return stopped
 }
 
 func (f *Flows) GetTasksCount() int {
@@ -103,12 +120,13 @@ func (f *Flows) GetTasksCount() int {
 	return count
 }
 
-func (f *Flows) Cancel() {
+// Cancel stops every flow with cause and forgets them.
+func (f *Flows) Cancel(cause *errs.StopCause) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
 	for _, flow := range f.flows {
-		flow.Cancel()
+		flow.Stop(cause)
 	}
 
 	f.flows = make(map[string]*Flow)
@@ -0,0 +1,28 @@
+package flows
+
+import (
+	"sconcur/internal/dto"
+	"sconcur/internal/features"
+	"sconcur/internal/states"
+	"sconcur/internal/tasks"
+)
+
+// Resolver picks the function that runs a message's task, or fails for a
+// message nothing handles (the push is then rejected).
+type Resolver func(msg *dto.Message) (func(task *tasks.Task), error)
+
+// ResolveFeature is the live worker's Resolver: a next() goes to the streaming
+// state its task opened, any other message to the feature of its method.
+func ResolveFeature(msg *dto.Message) (func(task *tasks.Task), error) {
+	if msg.IsNext {
+		return states.Get().Next, nil
+	}
+
+	feature, err := features.DetectMessageHandler(msg.Method)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return feature.Handle, nil
+}
@@ -0,0 +1,82 @@
+package flows
+
+import (
+	"sconcur/internal/types"
+	"sort"
+	"time"
+)
+
+// FlowSnapshot describes a live flow for runtime introspection (inspect export).
+type FlowSnapshot struct {
+	Key        string         `json:"key"`
+	TasksCount int            `json:"tasksCount"`
+	AgeMs      int64          `json:"ageMs"`
+	Tasks      []TaskSnapshot `json:"tasks"`
+}
+
+// TaskSnapshot describes a task of a flow that has not been delivered yet.
+type TaskSnapshot struct {
+	Method      types.Method `json:"method"`
+	TaskKey     string       `json:"taskKey"`
+	StartedAtMs int64        `json:"startedAtMs"`
+	AgeMs       int64        `json:"ageMs"`
+	IsNext      bool         `json:"isNext"`
+}
+
+// Snapshot captures the flow and its active tasks, oldest task first.
+func (f *Flow) Snapshot(now time.Time) FlowSnapshot {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	snapshot := FlowSnapshot{
+		Key:        f.key,
+		TasksCount: f.Count(),
+		AgeMs:      now.Sub(f.createdAt).Milliseconds(),
+		Tasks:      make([]TaskSnapshot, 0, len(f.activeTasks)),
+	}
+
+	for _, task := range f.activeTasks {
+		message := task.GetMessage()
+		startedAt := task.GetStartedAt()
+
+		snapshot.Tasks = append(snapshot.Tasks, TaskSnapshot{
+			Method:      message.Method,
+			TaskKey:     message.TaskKey,
+			StartedAtMs: startedAt.UnixMilli(),
+			AgeMs:       now.Sub(startedAt).Milliseconds(),
+			IsNext:      message.IsNext,
+		})
+	}
+
+	sort.Slice(snapshot.Tasks, func(i, j int) bool {
+		return snapshot.Tasks[i].StartedAtMs < snapshot.Tasks[j].StartedAtMs
+	})
+
+	return snapshot
+}
+
+// Snapshot captures every registered flow, oldest first.
+func (f *Flows) Snapshot(now time.Time) []FlowSnapshot {
+	f.mutex.RLock()
+
+	flows := make([]*Flow, 0, len(f.flows))
+
+	for _, flow := range f.flows {
+		flows = append(flows, flow)
+	}
+
+	f.mutex.RUnlock()
+
+	// Snapshot each flow outside the registry lock: a flow lock is taken per flow.
+	snapshots := make([]FlowSnapshot, 0, len(flows))
+
+	for _, flow := range flows {
+		snapshots = append(snapshots, flow.Snapshot(now))
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].AgeMs > snapshots[j].AgeMs
+	})
+
+	return snapshots
+}
@@ -0,0 +1,101 @@
+// Package framemem places the result frames going to PHP in C memory, where the
+// C side copies them into the PHP string: a span of the runtime's ring (package
+// arena) while one is free, else a C allocation of its own. The C side releases a
+// span itself, by setting the span's release word once copied, and frees an
+// allocation; neither calls back into Go.
+package framemem
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"sconcur/internal/arena"
+	"sync"
+	"unsafe"
+)
+
+// RingSize is the size of the ring each runtime writes its result frames into; a
+// frame or batch larger than the free part of it gets its own allocation.
+const RingSize = 1 << 20
+
+// Ring is the arena of one runtime over C memory.
+type Ring struct {
+	arena *arena.Arena
+	base  unsafe.Pointer
+}
+
+// rings holds the ring of every runtime that took a result, by runtime id.
+var rings sync.Map
+
+func NewRing(size int) *Ring {
+	base := C.malloc(C.size_t(size))
+
+	return &Ring{
+		arena: arena.New(unsafe.Slice((*byte)(base), size)),
+		base:  base,
+	}
+}
+
+// Of returns the ring of a runtime, allocating it on its first result.
+func Of(runtime int) *Ring {
+	if found, ok := rings.Load(runtime); ok {
+		return found.(*Ring)
+	}
+
+	created := NewRing(RingSize)
+
+	actual, loaded := rings.LoadOrStore(runtime, created)
+
+	if loaded {
+		created.Free()
+	}
+
+	return actual.(*Ring)
+}
+
+// Place lets write fill size bytes of C memory and returns them. A span of the
+// ring comes with its release word, which the consumer sets to 1 once the bytes
+// are copied out; an allocation of their own comes with a nil word and is freed
+// by the consumer.
+func (r *Ring) Place(size int, write func(dst []byte)) (data unsafe.Pointer, release *uint32) {
+	if offset, ok := r.arena.Reserve(size); ok {
+		write(r.arena.Bytes()[offset : offset+size])
+
+		return unsafe.Add(r.base, offset), r.arena.ReleaseWord(offset)
+	}
+
+	return Allocate(size, write), nil
+}
+
+// Used returns the bytes of the ring held by spans not released yet.
+func (r *Ring) Used() int {
+	return r.arena.Used()
+}
+
+// Free frees the ring's memory. A runtime's frames are copied out before its
+// thread makes the next call, so none is left to read once the runtime is gone.
+func (r *Ring) Free() {
+	C.free(r.base)
+}
+
+// Drop frees the ring of a destroyed runtime.
+func Drop(runtime int) {
+	if found, ok := rings.LoadAndDelete(runtime); ok {
+		found.(*Ring).Free()
+	}
+}
+
+// Allocate lets write fill a C allocation of size bytes, freed with Free (by the
+// C side: free()).
+func Allocate(size int, write func(dst []byte)) unsafe.Pointer {
+	data := C.malloc(C.size_t(size))
+
+	write(unsafe.Slice((*byte)(data), size))
+
+	return data
+}
+
+func Free(data unsafe.Pointer) {
+	C.free(data)
+}
@@ -0,0 +1,88 @@
+package framemem
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+func fill(payload []byte) func(dst []byte) {
+	return func(dst []byte) {
+		copy(dst, payload)
+	}
+}
+
+func TestPlaceFallsBackToAnAllocationWhenTheRingIsFull(t *testing.T) {
+	ring := NewRing(64)
+	defer ring.Free()
+
+	first, release := ring.Place(48, fill(bytes.Repeat([]byte{1}, 48)))
+
+	if release == nil {
+		t.Fatal("48 bytes must fit in an empty 64-byte ring")
+	}
+
+	if got := unsafe.Slice((*byte)(first), 48); !bytes.Equal(got, bytes.Repeat([]byte{1}, 48)) {
+		t.Fatalf("ring frame holds %v", got)
+	}
+
+	second, secondRelease := ring.Place(8, fill(bytes.Repeat([]byte{2}, 8)))
+
+	if secondRelease != nil {
+		t.Fatal("8 bytes must not fit next to a held 48-byte span")
+	}
+
+	if got := unsafe.Slice((*byte)(second), 8); !bytes.Equal(got, bytes.Repeat([]byte{2}, 8)) {
+		t.Fatalf("allocated frame holds %v", got)
+	}
+
+	Free(second)
+
+	// What release_frame_buffer does in sconcur.c once the frame is copied.
+	atomic.StoreUint32(release, 1)
+
+	if used := ring.Used(); used != 0 {
+		t.Fatalf("used %d after the release word was set", used)
+	}
+
+	if reused, release := ring.Place(32, fill(nil)); release == nil || reused != first {
+		t.Fatal("the released span must be reused")
+	}
+}
+
+// BenchmarkPlace measures placing a frame the way a wait export does, with the C
+// side's part included: the frame is written into C memory, copied out as into
+// the PHP string, then released to the ring or freed.
+func BenchmarkPlace(b *testing.B) {
+	for _, size := range []int{64, 4 << 10, 64 << 10} {
+		payload := bytes.Repeat([]byte{'x'}, size)
+		copied := make([]byte, size)
+
+		b.Run(fmt.Sprintf("ring/%d", size), func(b *testing.B) {
+			ring := NewRing(RingSize)
+			defer ring.Free()
+
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+
+			for b.Loop() {
+				data, release := ring.Place(size, fill(payload))
+				copy(copied, unsafe.Slice((*byte)(data), size))
+				atomic.StoreUint32(release, 1)
+			}
+		})
+
+		b.Run(fmt.Sprintf("malloc/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+
+			for b.Loop() {
+				data := Allocate(size, fill(payload))
+				copy(copied, unsafe.Slice((*byte)(data), size))
+				Free(data)
+			}
+		})
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"runtime"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/features"
 	"sconcur/internal/flows"
+	"sconcur/internal/readiness"
+	"sconcur/internal/recorder"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -44,12 +48,19 @@ const waitSpinIterations = 8
 
 type Handler struct {
 	ctx       context.Context
-	ctxCancel context.CancelFunc
+	ctxCancel context.CancelCauseFunc
 	mutex     sync.Mutex
 	index     int64
 
 	flows *flows.Flows
 
+	// resolve picks the feature for each pushed message (see flows.Resolver).
+	resolve flows.Resolver
+
+	// notifier is the handler's readiness descriptor. It outlives Destroy, so PHP
+	// keeps the descriptor it registered in its loop.
+	notifier *readiness.Notifier
+
 	// results is the single channel every flow's tasks publish into, so the PHP
 	// side can wait for the first ready result of any flow (WaitAny). This is the
 	// foundation for nested coroutines running concurrently with the outer flow.
@@ -61,10 +72,21 @@ type Handler struct {
 	// Only touched from Wait/WaitAny, which the single-threaded PHP caller
 	// serializes. Remove once PHP moves fully to WaitAny.
 	pending map[string][]*dto.Result
+
+	// recorder, when set, receives the handler's traffic (see StartRecording). It
+	// outlives Destroy, so a recording spans worker resets.
+	recorder atomic.Pointer[recorder.Recorder]
 }
 
 func NewHandler() *Handler {
-	h := &Handler{}
+	return NewHandlerWithResolver(flows.ResolveFeature)
+}
+
+// NewHandlerWithResolver builds a handler whose messages are run by what resolve
+// picks instead of the real features — the replay tool answers a recorded
+// session from its recording this way (see internal/replay).
+func NewHandlerWithResolver(resolve flows.Resolver) *Handler {
+	h := &Handler{resolve: resolve, notifier: readiness.NewNotifier()}
 	h.fresh()
 
 	return h
@@ -73,13 +95,37 @@ func NewHandler() *Handler {
 func (h *Handler) Push(msg *dto.Message) error {
 	flow := h.flows.InitFlow(h.ctx, msg.FlowKey, h.results)
 
-	return flow.HandleMessage(msg)
+	err := flow.HandleMessage(msg)
+
+	if rec := h.recorder.Load(); rec != nil {
+		rec.RecordPush(msg, err)
+	}
+
+	return err
+}
+
+// PushMany registers a batch of messages of one flow in one go (a single flow lock
+// acquisition) and returns one error per message, nil when accepted.
+func (h *Handler) PushMany(flowKey string, msgs []*dto.Message) []error {
+	flow := h.flows.InitFlow(h.ctx, flowKey, h.results)
+
+	failures := flow.HandleMessages(msgs)
+
+	if rec := h.recorder.Load(); rec != nil {
+		for index, msg := range msgs {
+			rec.RecordPush(msg, failures[index])
+		}
+	}
+
+	return failures
 }
 
 // WaitAny returns the first ready result of any flow. It is the basis of the
 // PHP-side scheduler: one global wait point that lets every flow progress
 // concurrently instead of each flow blocking on its own channel.
 func (h *Handler) WaitAny() (*dto.Result, error) {
+	defer h.rearmReadiness()
+
 	if result := h.popAnyPending(); result != nil {
 		return result, nil
 	}
@@ -128,6 +174,8 @@ func (h *Handler) WaitAny() (*dto.Result, error) {
 // result is ready within the given milliseconds, so a blocking PHP caller can
 // wake periodically (e.g. to notice a shutdown signal on an idle server).
 func (h *Handler) WaitAnyTimeout(ms int) (*dto.Result, error) {
+	defer h.rearmReadiness()
+
 	if result := h.popAnyPending(); result != nil {
 		return result, nil
 	}
@@ -155,10 +203,120 @@ func (h *Handler) WaitAnyTimeout(ms int) (*dto.Result, error) {
 	}
 }
 
+// WaitMany blocks like WaitAny (or WaitAnyTimeout for ms > 0) for the first ready
+// result, then drains every further result that is already ready, up to limit, without
+// blocking again. It lets a fan-out cross the cgo boundary once per batch instead of
+// once per result: when the buffer is full, the spin/park of WaitAny is paid once.
+//
+// With ms < 0 it never blocks: ErrWaitTimeout when nothing is ready. That is the call
+// an external event loop makes once the readiness descriptor fires.
+func (h *Handler) WaitMany(limit int, ms int) ([]*dto.Result, error) {
+	defer h.rearmReadiness()
+
+	var first *dto.Result
+	var err error
+
+	switch {
+	case ms < 0:
+		first, err = h.poll()
+	case ms > 0:
+		first, err = h.WaitAnyTimeout(ms)
+	default:
+		first, err = h.WaitAny()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*dto.Result{first}
+
+	for len(results) < limit {
+		result := h.popAnyPending()
+
+		if result == nil {
+			result = h.pollResult()
+		}
+
+		if result == nil {
+			break
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// pollResult is a non-blocking receive from the shared channel: the next ready
+// result of a known flow, or nil once nothing is ready.
+func (h *Handler) pollResult() *dto.Result {
+	for {
+		select {
+		case result, ok := <-h.results:
+			if !ok {
+				return nil
+			}
+
+			if !h.deliver(result) {
+				continue
+			}
+
+			return result
+		default:
+			return nil
+		}
+	}
+}
+
+// poll returns the next ready result without blocking, or ErrWaitTimeout.
+func (h *Handler) poll() (*dto.Result, error) {
+	if result := h.popAnyPending(); result != nil {
+		return result, nil
+	}
+
+	if result := h.pollResult(); result != nil {
+		return result, nil
+	}
+
+	return nil, ErrWaitTimeout
+}
+
+// rearmReadiness re-evaluates the readiness descriptor after a wait: it stays
+// readable only while a result is still deliverable (see package readiness).
+func (h *Handler) rearmReadiness() {
+	h.notifier.Rearm(h.hasDeliverable)
+}
+
+// ReadinessFd returns the handler's readiness descriptor, creating it on first
+// use: readable while the handler holds a deliverable result.
+func (h *Handler) ReadinessFd() (int, error) {
+	return h.notifier.Enable()
+}
+
+func (h *Handler) hasDeliverable() bool {
+	if len(h.results) > 0 {
+		return true
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.pending) > 0
+}
+
 // Wait returns the next result of a specific flow, buffering any other flow's
 // results into pending. Transitional compatibility for the per-flow PHP/sync
 // path; remove once PHP waits via WaitAny only.
 func (h *Handler) Wait(flowKey string) (*dto.Result, error) {
+	defer h.rearmReadiness()
+
+	// A flow read by key gets no answers queued for it once stopped (Flow.Stop):
+	// nothing would claim them.
+	if flow, err := h.flows.GetFlow(flowKey); err == nil {
+		flow.MarkAwaitedByKey()
+	}
+
 	if result := h.popPending(flowKey); result != nil {
 		return result, nil
 	}
@@ -200,6 +358,10 @@ func (h *Handler) deliver(result *dto.Result) bool {
 
 	flow.OnDelivered(result)
 
+	if rec := h.recorder.Load(); rec != nil {
+		rec.RecordResult(result)
+	}
+
 	return true
 }
 
@@ -252,29 +414,84 @@ func (h *Handler) pushPending(result *dto.Result) {
 	h.pending[result.FlowKey] = append(h.pending[result.FlowKey], result)
 }
 
-func (h *Handler) StopFlow(flowKey string) {
-	h.flows.DeleteFlow(flowKey)
+// StopFlow stops a flow and forgets it. Its running tasks see cause through their
+// context, and those still to answer are answered with a cancelled result
+// carrying it, returned here: the flow is gone, so nothing is left queued for a
+// caller that may never read it. nil stands for the generic errs.CodeFlowStopped
+// cause.
+func (h *Handler) StopFlow(flowKey string, cause *errs.StopCause) []*dto.Result {
+	if cause == nil {
+		cause = errs.NewStopCause("", "")
+	}
+
+	if rec := h.recorder.Load(); rec != nil {
+		rec.RecordStop(flowKey, cause)
+	}
+
+	stopped := h.flows.DeleteFlow(flowKey, cause)
 
 	// The stopped flow's results may still sit in pending (buffered there by a
 	// per-flow Wait); nobody will ever claim them, so drop them with the flow.
 	h.mutex.Lock()
 	delete(h.pending, flowKey)
 	h.mutex.Unlock()
+
+	if rec := h.recorder.Load(); rec != nil {
+		for _, result := range stopped {
+			rec.RecordResult(result)
+		}
+	}
+
+	return stopped
 }
 
+// CancelTask aborts a single task of a flow without stopping the flow: the task
+// is answered with a cancelled result while its siblings keep running. No-op for
+// an unknown flow (already stopped) — like StopFlow.
+func (h *Handler) CancelTask(flowKey string, taskKey string) {
+	if rec := h.recorder.Load(); rec != nil {
+		rec.RecordCancel(flowKey, taskKey)
+	}
+
+	flow, err := h.flows.GetFlow(flowKey)
+
+	if err != nil {
+		return
+	}
+
+	flow.CancelTask(taskKey)
+}
+
+// Destroy stops every flow, shuts the process-wide features down (pools, clients)
+// and leaves the handler fresh for reuse.
 func (h *Handler) Destroy() {
-	h.ctxCancel()
-	h.flows.Cancel()
+	h.stop()
 	features.Shutdown()
 	h.fresh()
 }
 
+// Close stops every flow of the handler, leaving the shared features running for
+// the other handlers (see package runtimes), and releases its readiness
+// descriptor. The handler is not reusable.
+func (h *Handler) Close() {
+	h.stop()
+	h.notifier.Close()
+}
+
+// stop stops every flow with the errs.CodeShutdown cause.
+func (h *Handler) stop() {
+	cause := errs.NewStopCause(errs.CodeShutdown, "extension destroyed")
+
+	h.ctxCancel(cause)
+	h.flows.Cancel(cause)
+}
+
 func (h *Handler) GetTasksCount() int {
 	return h.flows.GetTasksCount()
 }
 
 func (h *Handler) fresh() {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 
 	h.ctx = ctx
 	h.ctxCancel = cancel
@@ -287,5 +504,5 @@ func (h *Handler) fresh() {
 	h.results = make(chan *dto.Result, resultsBufferSize)
 	h.pending = make(map[string][]*dto.Result)
 
-	h.flows = flows.NewFlows()
+	h.flows = flows.NewFlows(h.resolve, h.notifier)
 }
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"unsafe"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/framemem"
 	"sconcur/internal/types"
 
 	"github.com/vmihailenco/msgpack/v5"
@@ -56,7 +59,7 @@ func BenchmarkRoundTripFreshFlow(b *testing.B) {
 			b.Fatal(err)
 		}
 
-		h.StopFlow(flowKey)
+		h.StopFlow(flowKey, nil)
 	}
 }
 
@@ -94,5 +97,89 @@ func BenchmarkRoundTripReusedFlow(b *testing.B) {
 
 	b.StopTimer()
 
-	h.StopFlow(flowKey)
+	h.StopFlow(flowKey, nil)
+}
+
+// benchFrame writes the result into a frame of size bytes, the way main.go's
+// result frame carries the keys and payload; the rest stays as it was.
+func benchFrame(result *dto.Result, dst []byte) {
+	offset := copy(dst, result.FlowKey)
+	offset += copy(dst[offset:], result.TaskKey)
+	copy(dst[offset:], result.Payload)
+}
+
+// BenchmarkRoundTripPlacedResult adds to the reused-flow round-trip the hand-over
+// of the result frame to the C side, for both transports: "cbytes" builds the
+// frame in a Go buffer and copies it into a malloc of its own (C.CBytes) that C
+// frees after copying it into the PHP string; "ring" writes it in place into the
+// runtime's arena and C releases it through its release word.
+func BenchmarkRoundTripPlacedResult(b *testing.B) {
+	for _, size := range []int{64, 4 << 10, 64 << 10} {
+		copied := make([]byte, size)
+
+		b.Run("cbytes/"+strconv.Itoa(size), func(b *testing.B) {
+			benchPlacedResult(b, func(result *dto.Result) {
+				frame := make([]byte, size)
+				benchFrame(result, frame)
+
+				data := framemem.Allocate(size, func(dst []byte) {
+					copy(dst, frame)
+				})
+
+				copy(copied, unsafe.Slice((*byte)(data), size))
+				framemem.Free(data)
+			})
+		})
+
+		b.Run("ring/"+strconv.Itoa(size), func(b *testing.B) {
+			ring := framemem.NewRing(framemem.RingSize)
+			defer ring.Free()
+
+			benchPlacedResult(b, func(result *dto.Result) {
+				data, release := ring.Place(size, func(dst []byte) {
+					benchFrame(result, dst)
+				})
+
+				copy(copied, unsafe.Slice((*byte)(data), size))
+				atomic.StoreUint32(release, 1)
+			})
+		})
+	}
+}
+
+func benchPlacedResult(b *testing.B, place func(result *dto.Result)) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	payload := benchSleepPayload(b)
+
+	const flowKey = "placed"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		msg := &dto.Message{
+			FlowKey: flowKey,
+			Method:  types.MethodSleep,
+			TaskKey: flowKey + ":" + strconv.Itoa(i),
+			Payload: payload,
+		}
+
+		if err := h.Push(msg); err != nil {
+			b.Fatal(err)
+		}
+
+		result, err := h.WaitAny()
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		place(result)
+	}
+
+	b.StopTimer()
+
+	h.StopFlow(flowKey, nil)
 }
@@ -2,9 +2,16 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/states"
+	"sconcur/internal/tasks"
 	"sconcur/internal/types"
 
 	"github.com/vmihailenco/msgpack/v5"
@@ -13,7 +20,7 @@ import (
 func sleepMessage(t *testing.T, flowKey, taskKey string, ms int64) *dto.Message {
 	t.Helper()
 
-	payload, err := msgpack.Marshal(map[string]int64{"ms": ms})
+	payload, err := msgpack.Marshal(map[string]int64{"us": ms * 1000})
 
 	if err != nil {
 		t.Fatal(err)
@@ -119,6 +126,212 @@ func TestWaitAnyTimeout(t *testing.T) {
 	}
 }
 
+// A handler's resolver is its own: one answering every message in place of the
+// features leaves a live handler running next to it on the real ones.
+func TestResolverIsPerHandler(t *testing.T) {
+	replaced := NewHandlerWithResolver(func(*dto.Message) (func(task *tasks.Task), error) {
+		return func(task *tasks.Task) {
+			task.AddResult(dto.NewSuccessResult(task.GetMessage(), "replaced", 0))
+		}, nil
+	})
+	defer replaced.Destroy()
+
+	live := NewHandler()
+	defer live.Destroy()
+
+	if err := replaced.Push(sleepMessage(t, "flow", "task-1", 5000)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := replaced.WaitAnyTimeout(1000)
+
+	if err != nil || result.Payload != "replaced" {
+		t.Fatalf("expected the resolver's answer, got %+v (%v)", result, err)
+	}
+
+	if err := live.Push(sleepMessage(t, "flow", "task-1", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = live.WaitAnyTimeout(1000)
+
+	if err != nil || result.IsError || result.Payload == "replaced" {
+		t.Fatalf("expected the real sleeper's answer, got %+v (%v)", result, err)
+	}
+}
+
+// A task still running when its flow is stopped is answered with a cancelled
+// result carrying the stop cause, returned by StopFlow since the flow is gone.
+func TestStopFlowReturnsItsCauseForRunningTasks(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	if err := h.Push(sleepMessage(t, "flow", "slow", 5000)); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := h.StopFlow("flow", errs.NewStopCause("user_abort", "aborted by the user"))
+
+	if len(stopped) != 1 || stopped[0].TaskKey != "slow" || !stopped[0].IsCancelled {
+		t.Fatalf("expected a cancelled result for the running task, got %+v", stopped)
+	}
+
+	details, err := errs.Decode(stopped[0].Payload)
+
+	if err != nil || details.Category != errs.CategoryCancelled || details.Code != "user_abort" || details.Message != "aborted by the user" {
+		t.Fatalf("expected the stop cause in the result, got %+v (%v)", details, err)
+	}
+
+	// Nothing is left queued for the forgotten flow: the count is back to 0 and
+	// no wait finds an answer of it.
+	if h.hasDeliverable() || h.GetTasksCount() != 0 {
+		t.Fatalf("a stopped flow must leave nothing behind, count %d", h.GetTasksCount())
+	}
+
+	if _, err := h.WaitAnyTimeout(50); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected no delivered answer for the stopped task, got %v", err)
+	}
+}
+
+// A flow read by key (the PHP sync path) has no reader left once stopped, so its
+// unfinished tasks must leave nothing pending behind.
+func TestStopFlowQueuesNothingForAFlowAwaitedByKey(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	if err := h.Push(sleepMessage(t, "flow", "fast", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Push(sleepMessage(t, "flow", "slow", 5000)); err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := h.Wait("flow"); err != nil || result.TaskKey != "fast" {
+		t.Fatalf("expected the fast result, got %+v (%v)", result, err)
+	}
+
+	if stopped := h.StopFlow("flow", errs.NewStopCause("user_abort", "")); len(stopped) != 0 {
+		t.Fatalf("a flow awaited by key must return no answers, got %+v", stopped)
+	}
+
+	if h.hasDeliverable() {
+		t.Fatal("a stopped flow awaited by key must leave nothing deliverable")
+	}
+
+	if _, err := h.WaitMany(10, -1); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected nothing to deliver, got %v", err)
+	}
+}
+
+// WaitMany must return every already-ready result up to max in one call, and
+// leave the rest for the next call.
+func TestWaitManyDrainsReadyResultsUpToMax(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	for i := range 5 {
+		if err := h.Push(sleepMessage(t, "flow", fmt.Sprintf("task-%d", i), 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Let every task publish so the batch sees them all ready.
+	time.Sleep(50 * time.Millisecond)
+
+	first, err := h.WaitMany(3, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 {
+		t.Fatalf("expected a batch of 3, got %d", len(first))
+	}
+
+	rest, err := h.WaitMany(10, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 {
+		t.Fatalf("expected the remaining 2, got %d", len(rest))
+	}
+
+	if h.GetTasksCount() != 0 {
+		t.Fatalf("expected zero active tasks after delivery, got %d", h.GetTasksCount())
+	}
+
+	if _, err := h.WaitMany(10, 20); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout on a drained handler, got %v", err)
+	}
+}
+
+// Inspect must list a live flow with its undelivered task without consuming
+// anything.
+func TestInspectListsLiveFlowsAndTasks(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	if err := h.Push(sleepMessage(t, "flow", "task-1", 200)); err != nil {
+		t.Fatal(err)
+	}
+
+	inspection := h.Inspect(0)
+
+	if len(inspection.Flows) != 1 || inspection.Flows[0].Key != "flow" {
+		t.Fatalf("expected the live flow, got %+v", inspection.Flows)
+	}
+
+	tasks := inspection.Flows[0].Tasks
+
+	if len(tasks) != 1 || tasks[0].TaskKey != "task-1" || tasks[0].Method != types.MethodSleep || tasks[0].IsNext {
+		t.Fatalf("expected the sleeping task, got %+v", tasks)
+	}
+
+	if inspection.ResultsCapacity != resultsBufferSize {
+		t.Fatalf("expected results capacity %d, got %d", resultsBufferSize, inspection.ResultsCapacity)
+	}
+
+	if h.GetTasksCount() != 1 {
+		t.Fatalf("inspect must not consume the task, got %d tasks", h.GetTasksCount())
+	}
+}
+
+type inspectedState struct{}
+
+func (inspectedState) Next() *dto.Result { return nil }
+
+func (inspectedState) Close() {}
+
+// The states registry is process-wide: Inspect lists only the states whose task
+// key the inspected runtime made.
+func TestInspectListsTheStatesOfItsRuntimeOnly(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	keys := map[string]int{"inspect-flow:1": 0, "inspect-flow:2:1": 2, "inspect-flow:3:1": 3}
+
+	for taskKey := range keys {
+		if err := states.Get().Register(taskKey, inspectedState{}); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { states.Get().DeleteState(taskKey) })
+	}
+
+	for taskKey, runtime := range keys {
+		var listed []string
+
+		for _, snapshot := range h.Inspect(runtime).States {
+			if strings.HasPrefix(snapshot.TaskKey, "inspect-flow:") {
+				listed = append(listed, snapshot.TaskKey)
+			}
+		}
+
+		if len(listed) != 1 || listed[0] != taskKey {
+			t.Fatalf("runtime %d: expected only %s, got %v", runtime, taskKey, listed)
+		}
+	}
+}
+
 func TestDestroyResetsHandler(t *testing.T) {
 	h := NewHandler()
 
@@ -151,3 +364,175 @@ func TestDestroyResetsHandler(t *testing.T) {
 
 	h.Destroy()
 }
+
+// A permit held by a flow goes back to the semaphore when PHP stops the flow, so a
+// coroutine that dies holding a lock cannot starve the others.
+func TestStopFlowReleasesHeldPermits(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	payload, err := msgpack.Marshal(map[string]any{"n": "handler-stop", "pm": 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquire := func(flowKey string, taskKey string) {
+		if err := h.Push(&dto.Message{FlowKey: flowKey, Method: types.MethodSemaphore, TaskKey: taskKey, Payload: payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	acquire("holder", "h-1")
+
+	held, err := h.WaitAny()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if held.TaskKey != "h-1" || held.IsError || !held.HasNext {
+		t.Fatalf("expected h-1 to hold the permit, got %+v", held)
+	}
+
+	acquire("waiter", "w-1")
+
+	if _, err := h.WaitAnyTimeout(30); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected the second acquire to wait, got %v", err)
+	}
+
+	h.StopFlow("holder", nil)
+
+	granted, err := h.WaitAnyTimeout(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if granted.TaskKey != "w-1" || granted.IsError {
+		t.Fatalf("expected w-1 to get the released permit, got %+v", granted)
+	}
+
+	h.StopFlow("waiter", nil)
+}
+
+// waitReadable reports whether fd becomes readable within timeout.
+func waitReadable(t *testing.T, fd int, timeout time.Duration) bool {
+	t.Helper()
+
+	var set syscall.FdSet
+
+	set.Bits[fd/64] |= 1 << (uint(fd) % 64)
+
+	timeval := syscall.NsecToTimeval(timeout.Nanoseconds())
+
+	ready, err := syscall.Select(fd+1, &set, nil, nil, &timeval)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ready > 0
+}
+
+// The readiness descriptor lets an external loop sleep in its own selector: it
+// turns readable once a result is ready, and a non-blocking WaitMany (ms < 0)
+// that takes the last result leaves it quiet again.
+func TestReadinessDescriptorTracksDeliverableResults(t *testing.T) {
+	h := NewHandler()
+	defer h.Destroy()
+
+	fd, err := h.ReadinessFd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.WaitMany(10, -1); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected a non-blocking miss on an idle handler, got %v", err)
+	}
+
+	if waitReadable(t, fd, 0) {
+		t.Fatal("an idle handler must not be readable")
+	}
+
+	for _, taskKey := range []string{"t-1", "t-2"} {
+		if err := h.Push(sleepMessage(t, "flow", taskKey, 5)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !waitReadable(t, fd, time.Second) {
+		t.Fatal("a ready result must make the descriptor readable")
+	}
+
+	var taken int
+
+	for taken < 2 {
+		if !waitReadable(t, fd, time.Second) {
+			t.Fatalf("the descriptor went quiet with %d of 2 results taken", taken)
+		}
+
+		results, err := h.WaitMany(1, -1)
+		if err != nil && !errors.Is(err, ErrWaitTimeout) {
+			t.Fatal(err)
+		}
+
+		taken += len(results)
+	}
+
+	if waitReadable(t, fd, 20*time.Millisecond) {
+		t.Fatal("the descriptor must be quiet once every result was taken")
+	}
+}
+
+// Each handler (runtime) has its own readiness descriptor: a result of one handler
+// wakes its own descriptor only, and its wait rearms only its own.
+func TestReadinessDescriptorIsPerHandler(t *testing.T) {
+	first := NewHandler()
+	defer first.Close()
+
+	second := NewHandler()
+	defer second.Close()
+
+	firstFd, err := first.ReadinessFd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondFd, err := second.ReadinessFd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if firstFd == secondFd {
+		t.Fatal("two handlers must not share a descriptor")
+	}
+
+	if err := first.Push(sleepMessage(t, "flow", "t-1", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitReadable(t, firstFd, time.Second) {
+		t.Fatal("a ready result must make its handler's descriptor readable")
+	}
+
+	if waitReadable(t, secondFd, 20*time.Millisecond) {
+		t.Fatal("a result of another handler must not wake the descriptor")
+	}
+
+	if err := second.Push(sleepMessage(t, "flow", "t-1", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitReadable(t, secondFd, time.Second) {
+		t.Fatal("a ready result must make its handler's descriptor readable")
+	}
+
+	if _, err := first.WaitMany(10, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	if waitReadable(t, firstFd, 20*time.Millisecond) {
+		t.Fatal("the drained handler's descriptor must be quiet")
+	}
+
+	if !waitReadable(t, secondFd, 0) {
+		t.Fatal("a wait on another handler must leave the descriptor readable")
+	}
+}
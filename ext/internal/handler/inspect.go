@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"sconcur/internal/crashes"
+	"sconcur/internal/flows"
+	"sconcur/internal/helpers"
+	"sconcur/internal/states"
+	"time"
+)
+
+// Inspection is a point-in-time dump of what the Go side holds — flows with their
+// undelivered tasks, open streaming states, and the results queued for PHP — for
+// diagnosing a stuck worker (an orphaned cursor, a task that never answers).
+type Inspection struct {
+	TakenAtMs int64                `json:"takenAtMs"`
+	Flows     []flows.FlowSnapshot `json:"flows"`
+	// States are the open states of the inspected runtime only: the registry is
+	// process-wide, so the others are told apart by their task key.
+	States []states.StateSnapshot `json:"states"`
+	// PendingResults counts results pulled from the channel but not yet claimed
+	// by a per-flow Wait.
+	PendingResults int `json:"pendingResults"`
+	// ResultsBuffered is the number of results queued in the shared channel,
+	// out of ResultsCapacity.
+	ResultsBuffered int `json:"resultsBuffered"`
+	ResultsCapacity int `json:"resultsCapacity"`
+	// ReapedStates counts states closed by the idle reaper since start, in every
+	// runtime (the reaper is process-wide).
+	ReapedStates int64 `json:"reapedStates"`
+	// Crashes are the last panics recovered by the process (see package crashes),
+	// oldest first, whichever runtime they happened in; CrashCounts counts all of
+	// them since start.
+	Crashes     []crashes.Report `json:"crashes"`
+	CrashCounts crashes.Counts   `json:"crashCounts"`
+}
+
+// Inspect captures the current state of the handler, the runtime id it is
+// registered under (see package runtimes) selecting its states. It only reads: no
+// result is pulled and no bookkeeping runs, so it is safe to call from an admin
+// endpoint while flows are live.
+func (h *Handler) Inspect(runtime int) *Inspection {
+	now := time.Now()
+
+	h.mutex.Lock()
+
+	pending := 0
+
+	for _, results := range h.pending {
+		pending += len(results)
+	}
+
+	h.mutex.Unlock()
+
+	return &Inspection{
+		TakenAtMs:       now.UnixMilli(),
+		Flows:           h.flows.Snapshot(now),
+		States:          statesOf(states.Get().Snapshot(now), runtime),
+		PendingResults:  pending,
+		ResultsBuffered: len(h.results),
+		ResultsCapacity: cap(h.results),
+		ReapedStates:    states.Get().ReapedCount(),
+		Crashes:         crashes.Get().Reports(),
+		CrashCounts:     crashes.Get().Counts(),
+	}
+}
+
+// statesOf keeps the states whose task key was made by the runtime.
+func statesOf(snapshots []states.StateSnapshot, runtime int) []states.StateSnapshot {
+	kept := snapshots[:0]
+
+	for _, snapshot := range snapshots {
+		if helpers.RuntimeOfTaskKey(snapshot.TaskKey) == runtime {
+			kept = append(kept, snapshot)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"sconcur/internal/recorder"
+)
+
+// StartRecording records the handler's traffic into path (see package recorder)
+// until StopRecording: every pushed message, delivered result, stopped flow and
+// cancelled task. A recording already running is closed and replaced.
+func (h *Handler) StartRecording(path string) error {
+	rec, err := recorder.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if previous := h.recorder.Swap(rec); previous != nil {
+		return previous.Close()
+	}
+
+	return nil
+}
+
+// StopRecording closes the running recording, if any, and returns the first error
+// met while writing it.
+func (h *Handler) StopRecording() error {
+	if rec := h.recorder.Swap(nil); rec != nil {
+		return rec.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package helpers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RuntimeOfTaskKey returns the id of the runtime whose PHP side made the task
+// key. Extension::makeTaskKey makes "flowKey:counter" in the default runtime (0)
+// and "flowKey:runtime:counter" in another one; flow keys hold no colon.
+func RuntimeOfTaskKey(taskKey string) int {
+	parts := strings.Split(taskKey, ":")
+
+	if len(parts) != 3 {
+		return 0
+	}
+
+	runtime, err := strconv.Atoi(parts[1])
+
+	if err != nil {
+		return 0
+	}
+
+	return runtime
+}
@@ -0,0 +1,159 @@
+// Package readiness exposes "a result is ready" as a file descriptor, so PHP can
+// plug sconcur into an external event loop (ReactPHP, Revolt, stream_select): it
+// watches the descriptor and calls a wait only when something is deliverable.
+//
+// Every handler owns a Notifier: its descriptor is the read end of a non-blocking
+// pipe. A producer signals the notifier of its handler after publishing a result;
+// the handler's wait calls drain the pipe and re-signal when results are still
+// left, so the descriptor stays readable exactly while that handler holds a
+// deliverable result (stale results of stopped flows may cause a spurious
+// wake-up). A notifier is disabled until Enable is called: Signal is then a single
+// atomic load.
+package readiness
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+type pipe struct {
+	readFd  int
+	writeFd int
+
+	// armed is true while a signal byte is in the pipe, so a burst of results
+	// writes one byte instead of filling the pipe.
+	armed atomic.Bool
+
+	// closed is set by Notifier.Close under the write lock: a producer that
+	// loaded the pipe before it was closed must not write to a descriptor number
+	// the process may already have reused.
+	closed bool
+}
+
+// Notifier is the readiness descriptor of one handler. A nil Notifier is valid
+// and never signals.
+type Notifier struct {
+	current atomic.Pointer[pipe]
+
+	// mutex serializes Enable and Close, and keeps Close from releasing the
+	// descriptors under a write or a drain.
+	mutex sync.RWMutex
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Enable creates the pipe on first use and returns its read end. The descriptor
+// lives as long as the notifier: PHP may keep it registered in its loop across a
+// handler Destroy.
+func (n *Notifier) Enable() (int, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if existing := n.current.Load(); existing != nil {
+		return existing.readFd, nil
+	}
+
+	var fds [2]int
+
+	if err := syscall.Pipe2(fds[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+		return -1, err
+	}
+
+	n.current.Store(&pipe{
+		readFd:  fds[0],
+		writeFd: fds[1],
+	})
+
+	return fds[0], nil
+}
+
+// Close releases the pipe of a notifier that is done with: signals after it are
+// no-ops.
+func (n *Notifier) Close() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	active := n.current.Swap(nil)
+
+	if active == nil {
+		return
+	}
+
+	active.closed = true
+
+	_ = syscall.Close(active.writeFd)
+	_ = syscall.Close(active.readFd)
+}
+
+// Signal marks a result as ready. Called by the producer after the result is in
+// the results channel, so a wait woken by it always finds the result.
+func (n *Notifier) Signal() {
+	if n == nil {
+		return
+	}
+
+	active := n.current.Load()
+
+	if active == nil || !active.armed.CompareAndSwap(false, true) {
+		return
+	}
+
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	if active.closed {
+		return
+	}
+
+	// A full pipe (EAGAIN) still leaves it readable; nothing else can fail here
+	// that the next signal would not retry.
+	_, _ = syscall.Write(active.writeFd, []byte{1})
+}
+
+// Rearm drains the pipe after a wait took its result and signals again when
+// hasMore reports that deliverable results are left. The order matters: the pipe
+// is drained while still armed, so no producer writes a byte the drain would eat;
+// a producer skipped meanwhile published its result before signalling, so the
+// check after disarming sees it. A result published after the disarm signals on
+// its own.
+func (n *Notifier) Rearm(hasMore func() bool) {
+	if n == nil {
+		return
+	}
+
+	active := n.current.Load()
+
+	if active == nil {
+		return
+	}
+
+	n.drain(active)
+
+	active.armed.Store(false)
+
+	if hasMore() {
+		n.Signal()
+	}
+}
+
+func (n *Notifier) drain(active *pipe) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	if active.closed {
+		return
+	}
+
+	buffer := make([]byte, 64)
+
+	for {
+		read, err := syscall.Read(active.readFd, buffer)
+
+		if err != nil || read < len(buffer) {
+			break
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package readiness
+
+import (
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func readable(t *testing.T, fd int) bool {
+	t.Helper()
+
+	return readableWithin(t, fd, 0)
+}
+
+// readableWithin waits up to wait for the descriptor to become readable.
+func readableWithin(t *testing.T, fd int, wait time.Duration) bool {
+	t.Helper()
+
+	var set syscall.FdSet
+
+	set.Bits[fd/64] |= 1 << (uint(fd) % 64)
+
+	timeout := syscall.NsecToTimeval(wait.Nanoseconds())
+
+	ready, err := syscall.Select(fd+1, &set, nil, nil, &timeout)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ready > 0
+}
+
+func TestDescriptorFollowsDeliverableResults(t *testing.T) {
+	notifier := NewNotifier()
+	defer notifier.Close()
+
+	fd, err := notifier.Enable()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again, _ := notifier.Enable(); again != fd {
+		t.Fatalf("Enable must return the same descriptor, got %d and %d", fd, again)
+	}
+
+	notifier.Rearm(func() bool { return false })
+
+	if readable(t, fd) {
+		t.Fatal("an idle handler must not be readable")
+	}
+
+	notifier.Signal()
+	notifier.Signal()
+
+	if !readable(t, fd) {
+		t.Fatal("a signalled result must make the descriptor readable")
+	}
+
+	// A wait took one result and another one is left: still readable.
+	notifier.Rearm(func() bool { return true })
+
+	if !readable(t, fd) {
+		t.Fatal("the descriptor must stay readable while results are left")
+	}
+
+	notifier.Rearm(func() bool { return false })
+
+	if readable(t, fd) {
+		t.Fatal("a drained handler must not be readable")
+	}
+}
+
+// TestNoWakeupIsLostUnderConcurrentSignals keeps producers signalling while a
+// consumer takes one result per wake-up and rearms: as long as results are left,
+// the descriptor must turn readable, however Signal and Rearm interleave.
+func TestNoWakeupIsLostUnderConcurrentSignals(t *testing.T) {
+	notifier := NewNotifier()
+	defer notifier.Close()
+
+	fd, err := notifier.Enable()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const producers, wakeups = 4, 20_000
+
+	var pending atomic.Int64
+	var stop atomic.Bool
+
+	notifier.Rearm(func() bool { return false })
+
+	for range producers {
+		go func() {
+			for !stop.Load() {
+				pending.Add(1)
+				notifier.Signal()
+			}
+		}()
+	}
+
+	defer stop.Store(true)
+
+	for consumed := 0; consumed < wakeups; consumed++ {
+		if !readableWithin(t, fd, time.Second) {
+			t.Fatalf("lost wake-up: %d results left after %d consumed", pending.Load(), consumed)
+		}
+
+		if pending.Load() == 0 {
+			t.Fatalf("woken with no result left after %d consumed", consumed)
+		}
+
+		pending.Add(-1)
+
+		notifier.Rearm(func() bool { return pending.Load() > 0 })
+	}
+}
@@ -0,0 +1,287 @@
+// Package recorder writes the traffic of a handler.Handler to a file: every message
+// pushed, every result delivered, every flow stopped and task cancelled, in the
+// order the handler saw them, with the time since the recording started. The file
+// is what cmd/flow-replay feeds back into a fresh handler to reproduce a
+// scheduling problem offline (see internal/replay).
+//
+// The file is a sequence of frames: a big-endian uint32 length followed by one
+// MessagePack-encoded Entry. Frames are flushed as they are written, so a crashed
+// worker leaves a readable recording up to its last call.
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/types"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// maxFrameSize bounds one frame on read, so a corrupted length does not make the
+// reader allocate gigabytes.
+const maxFrameSize = 64 << 20
+
+type Kind string
+
+const (
+	KindPush   Kind = "push"
+	KindResult Kind = "result"
+	KindStop   Kind = "stop"
+	KindCancel Kind = "cancel"
+)
+
+// Entry is one recorded event. Which fields are set depends on Kind: a push
+// carries the message (and Error when the handler rejected it), a result carries
+// the delivered result, a stop the keys and the stop cause, a cancel only the keys.
+type Entry struct {
+	Kind Kind `msgpack:"k"`
+	// AtNs is the time of the event, in nanoseconds since the recording started.
+	AtNs    int64        `msgpack:"t"`
+	FlowKey string       `msgpack:"fk"`
+	TaskKey string       `msgpack:"tk"`
+	Method  types.Method `msgpack:"md,omitempty"`
+
+	Payload   []byte `msgpack:"pl,omitempty"`
+	IsNext    bool   `msgpack:"nx,omitempty"`
+	TimeoutMs int    `msgpack:"to,omitempty"`
+	Error     string `msgpack:"e,omitempty"`
+
+	Result      string `msgpack:"rs,omitempty"`
+	IsError     bool   `msgpack:"er,omitempty"`
+	HasNext     bool   `msgpack:"hn,omitempty"`
+	IsCancelled bool   `msgpack:"cn,omitempty"`
+	ExecutionMs int    `msgpack:"ems,omitempty"`
+
+	StopCode    string `msgpack:"sc,omitempty"`
+	StopMessage string `msgpack:"sm,omitempty"`
+}
+
+// Message rebuilds the pushed message of a push entry.
+func (e *Entry) Message() *dto.Message {
+	return &dto.Message{
+		FlowKey:   e.FlowKey,
+		Method:    e.Method,
+		TaskKey:   e.TaskKey,
+		Payload:   e.Payload,
+		IsNext:    e.IsNext,
+		TimeoutMs: e.TimeoutMs,
+	}
+}
+
+// StopCause rebuilds the cause of a stop entry.
+func (e *Entry) StopCause() *errs.StopCause {
+	return errs.NewStopCause(e.StopCode, e.StopMessage)
+}
+
+// DtoResult rebuilds the delivered result of a result entry.
+func (e *Entry) DtoResult() *dto.Result {
+	return &dto.Result{
+		FlowKey:     e.FlowKey,
+		Method:      e.Method,
+		TaskKey:     e.TaskKey,
+		IsError:     e.IsError,
+		Payload:     e.Result,
+		HasNext:     e.HasNext,
+		ExecutionMs: e.ExecutionMs,
+		IsCancelled: e.IsCancelled,
+	}
+}
+
+// Recorder appends entries to a recording file. Safe for concurrent use: entries
+// are written in the order their calls take the lock.
+type Recorder struct {
+	mutex     sync.Mutex
+	file      *os.File
+	writer    *bufio.Writer
+	startedAt time.Time
+	err       error
+}
+
+// Create starts a recording into path, truncating an existing file.
+func Create(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		startedAt: time.Now(),
+	}, nil
+}
+
+// RecordPush records a pushed message and, when the handler rejected it, why.
+func (r *Recorder) RecordPush(msg *dto.Message, pushErr error) {
+	entry := &Entry{
+		Kind:      KindPush,
+		FlowKey:   msg.FlowKey,
+		TaskKey:   msg.TaskKey,
+		Method:    msg.Method,
+		Payload:   msg.Payload,
+		IsNext:    msg.IsNext,
+		TimeoutMs: msg.TimeoutMs,
+	}
+
+	if pushErr != nil {
+		entry.Error = pushErr.Error()
+	}
+
+	r.write(entry)
+}
+
+// RecordResult records a result handed to the PHP side.
+func (r *Recorder) RecordResult(result *dto.Result) {
+	r.write(&Entry{
+		Kind:        KindResult,
+		FlowKey:     result.FlowKey,
+		TaskKey:     result.TaskKey,
+		Method:      result.Method,
+		Result:      result.Payload,
+		IsError:     result.IsError,
+		HasNext:     result.HasNext,
+		IsCancelled: result.IsCancelled,
+		ExecutionMs: result.ExecutionMs,
+	})
+}
+
+func (r *Recorder) RecordStop(flowKey string, cause *errs.StopCause) {
+	r.write(&Entry{Kind: KindStop, FlowKey: flowKey, StopCode: cause.Code, StopMessage: cause.Message})
+}
+
+func (r *Recorder) RecordCancel(flowKey string, taskKey string) {
+	r.write(&Entry{Kind: KindCancel, FlowKey: flowKey, TaskKey: taskKey})
+}
+
+// write appends one frame. The first failure is kept and reported by Close; the
+// traffic being recorded is never failed because of the recording.
+func (r *Recorder) write(entry *Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil || r.file == nil {
+		return
+	}
+
+	entry.AtNs = time.Since(r.startedAt).Nanoseconds()
+
+	data, err := msgpack.Marshal(entry)
+
+	if err != nil {
+		r.err = fmt.Errorf("marshal entry: %w", err)
+
+		return
+	}
+
+	var header [4]byte
+
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+
+	if _, err = r.writer.Write(header[:]); err == nil {
+		if _, err = r.writer.Write(data); err == nil {
+			err = r.writer.Flush()
+		}
+	}
+
+	if err != nil {
+		r.err = fmt.Errorf("write entry: %w", err)
+	}
+}
+
+// Close ends the recording and returns the first error it met, if any.
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return r.err
+	}
+
+	flushErr := r.writer.Flush()
+	closeErr := r.file.Close()
+
+	r.file = nil
+
+	return errors.Join(r.err, flushErr, closeErr)
+}
+
+// Reader reads the entries of a recording in order.
+type Reader struct {
+	reader *bufio.Reader
+}
+
+func NewReader(reader io.Reader) *Reader {
+	return &Reader{reader: bufio.NewReader(reader)}
+}
+
+// Next returns the next entry, or io.EOF at the end of the recording. A frame cut
+// short (a worker killed mid-write) is reported as io.ErrUnexpectedEOF.
+func (r *Reader) Next() (*Entry, error) {
+	var header [4]byte
+
+	if _, err := io.ReadFull(r.reader, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds %d", size, maxFrameSize)
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	entry := &Entry{}
+
+	if err := msgpack.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("decode entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ReadFile loads every entry of the recording at path.
+func ReadFile(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	reader := NewReader(file)
+
+	var entries []*Entry
+
+	for {
+		entry, err := reader.Next()
+
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
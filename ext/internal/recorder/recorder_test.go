@@ -0,0 +1,113 @@
+package recorder
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/types"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec")
+
+	rec, err := Create(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &dto.Message{FlowKey: "f", Method: types.MethodSleep, TaskKey: "t", Payload: []byte{1, 2}, TimeoutMs: 50}
+
+	rec.RecordPush(msg, nil)
+	rec.RecordPush(&dto.Message{FlowKey: "f", Method: "??", TaskKey: "u"}, errors.New("unknown method: ??"))
+	rec.RecordCancel("f", "t")
+	rec.RecordResult(&dto.Result{FlowKey: "f", Method: types.MethodSleep, TaskKey: "t", IsError: true, IsCancelled: true, Payload: "gone"})
+	rec.RecordStop("f", errs.NewStopCause("user_abort", "aborted by the user"))
+
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes after Close are dropped, not failed.
+	rec.RecordStop("late", errs.NewStopCause("", ""))
+
+	entries, err := ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []Kind{KindPush, KindPush, KindCancel, KindResult, KindStop}
+
+	if len(entries) != len(kinds) {
+		t.Fatalf("read %d entries, want %d", len(entries), len(kinds))
+	}
+
+	for index, kind := range kinds {
+		if entries[index].Kind != kind {
+			t.Fatalf("entry %d is %q, want %q", index, entries[index].Kind, kind)
+		}
+
+		if index > 0 && entries[index].AtNs < entries[index-1].AtNs {
+			t.Fatalf("entry %d goes back in time", index)
+		}
+	}
+
+	if pushed := entries[0].Message(); pushed.TimeoutMs != 50 || string(pushed.Payload) != "\x01\x02" || pushed.Method != types.MethodSleep {
+		t.Fatalf("push decoded as %+v", pushed)
+	}
+
+	if entries[1].Error != "unknown method: ??" {
+		t.Fatalf("the rejection was not recorded: %+v", entries[1])
+	}
+
+	if result := entries[3].DtoResult(); !result.IsCancelled || !result.IsError || result.Payload != "gone" {
+		t.Fatalf("result decoded as %+v", result)
+	}
+
+	if cause := entries[4].StopCause(); cause.Code != "user_abort" || cause.Message != "aborted by the user" {
+		t.Fatalf("stop cause decoded as %+v", cause)
+	}
+}
+
+func TestReaderReportsATruncatedFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cut.rec")
+
+	rec, err := Create(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec.RecordStop("first", errs.NewStopCause("", ""))
+	rec.RecordStop("second", errs.NewStopCause("", ""))
+
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadFile(path)
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].FlowKey != "first" {
+		t.Fatalf("expected the complete first entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sconcur/internal/tasks"
+	"sync"
+	"time"
+)
+
+var _ contracts.FeatureContract = (*Feature)(nil)
+
+// heldTasksSize bounds the tasks held under one flow/task key pair: more than one
+// only for repeated next() calls on the same task.
+const heldTasksSize = 64
+
+// Feature stands in for every feature during a replay: it holds each task until
+// Answer hands it its recorded result.
+type Feature struct {
+	mutex sync.Mutex
+	held  map[string]chan *tasks.Task
+}
+
+func NewFeature() *Feature {
+	return &Feature{
+		held: make(map[string]chan *tasks.Task),
+	}
+}
+
+// Handle holds the task. It is queued even when already cancelled, so Answer does
+// not wait in vain for a task that was cancelled before it got here.
+func (f *Feature) Handle(task *tasks.Task) {
+	message := task.GetMessage()
+	queue := f.queue(message.FlowKey, message.TaskKey)
+
+	select {
+	case queue <- task:
+		return
+	default:
+	}
+
+	select {
+	case queue <- task:
+	case <-task.GetContext().Done():
+	}
+}
+
+// Answer completes the oldest held task of the result's flow and task keys with
+// result, waiting up to wait for the task to arrive. A task already answered (by
+// a cancel) keeps its answer. Reports whether a task was found.
+func (f *Feature) Answer(result *dto.Result, wait time.Duration) bool {
+	queue := f.queue(result.FlowKey, result.TaskKey)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case task := <-queue:
+		task.AddResult(result)
+
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (f *Feature) queue(flowKey string, taskKey string) chan *tasks.Task {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := flowKey + "\x00" + taskKey
+
+	queue, ok := f.held[key]
+
+	if !ok {
+		queue = make(chan *tasks.Task, heldTasksSize)
+		f.held[key] = queue
+	}
+
+	return queue
+}
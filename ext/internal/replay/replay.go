@@ -0,0 +1,187 @@
+// Package replay feeds a recording (see package recorder) back into a fresh
+// handler.Handler and reports where the outcome differs from what was recorded.
+//
+// The features are swapped for the recording itself (handler.NewHandlerWithResolver
+// routes every message, next() calls included, to the replay Feature): a task is
+// held until the replay reaches its recorded result, and is then answered with
+// it. So the replay exercises the scheduling layer alone — flows, task accounting,
+// cancellation, stale-result drops, delivery order — deterministically and without
+// any database, socket or timer: the same recording always replays the same way.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"sconcur/internal/dto"
+	"sconcur/internal/handler"
+	"sconcur/internal/recorder"
+	"sconcur/internal/tasks"
+	"time"
+)
+
+// DefaultResultWait bounds how long the replay waits for a task to reach the
+// replay feature and for its result to be delivered.
+const DefaultResultWait = time.Second
+
+// Mismatch is one recorded result the replay did not reproduce.
+type Mismatch struct {
+	// Index is the position of the recorded entry in the recording.
+	Index    int
+	Reason   string
+	Expected *dto.Result
+	// Got is the result delivered instead; nil when none arrived.
+	Got *dto.Result
+}
+
+type Report struct {
+	Entries    int
+	Pushes     int
+	Results    int
+	Mismatches []Mismatch
+	// Unexpected are the results delivered after the recording ended: results the
+	// recorded session never saw.
+	Unexpected []*dto.Result
+	// Recorded is the duration of the recorded session, Replayed that of the replay.
+	Recorded time.Duration
+	Replayed time.Duration
+}
+
+func (r *Report) Ok() bool {
+	return len(r.Mismatches) == 0 && len(r.Unexpected) == 0
+}
+
+// Run replays entries against a fresh handler. resultWait bounds the wait for each
+// recorded result (DefaultResultWait when 0).
+//
+// Pushes rejected in the recording are skipped: they were rejected by the feature
+// lookup, which the replay replaces. Task deadlines are dropped: the timeout
+// answers are in the recording, and a live deadline would race with it.
+func Run(entries []*recorder.Entry, resultWait time.Duration) *Report {
+	if resultWait <= 0 {
+		resultWait = DefaultResultWait
+	}
+
+	feature := NewFeature()
+
+	// Close, not Destroy: the replay owns only this handler, and the process-wide
+	// features must stay up for whoever runs it (a test, a long-lived tool).
+	h := handler.NewHandlerWithResolver(func(*dto.Message) (func(task *tasks.Task), error) {
+		return feature.Handle, nil
+	})
+	defer h.Close()
+
+	report := &Report{Entries: len(entries)}
+	startedAt := time.Now()
+
+	// stopped holds the answers a replayed flow stop returned, matched against
+	// the results recorded right after the stop instead of being waited for.
+	var stopped []*dto.Result
+
+	for index, entry := range entries {
+		switch entry.Kind {
+		case recorder.KindPush:
+			if entry.Error != "" {
+				continue
+			}
+
+			msg := entry.Message()
+			msg.TimeoutMs = 0
+
+			report.Pushes++
+
+			if err := h.Push(msg); err != nil {
+				report.Mismatches = append(report.Mismatches, Mismatch{
+					Index:  index,
+					Reason: "push rejected: " + err.Error(),
+				})
+			}
+		case recorder.KindStop:
+			stopped = append(stopped, h.StopFlow(entry.FlowKey, entry.StopCause())...)
+		case recorder.KindCancel:
+			h.CancelTask(entry.FlowKey, entry.TaskKey)
+		case recorder.KindResult:
+			report.Results++
+
+			expected := entry.DtoResult()
+
+			var (
+				got *dto.Result
+				err error
+			)
+
+			if len(stopped) > 0 {
+				got, stopped = stopped[0], stopped[1:]
+			} else {
+				feature.Answer(expected, resultWait)
+
+				got, err = h.WaitAnyTimeout(int(resultWait.Milliseconds()))
+			}
+
+			if err != nil {
+				report.Mismatches = append(report.Mismatches, Mismatch{
+					Index:    index,
+					Reason:   missingReason(err),
+					Expected: expected,
+				})
+
+				continue
+			}
+
+			if reason := diff(expected, got); reason != "" {
+				report.Mismatches = append(report.Mismatches, Mismatch{
+					Index:    index,
+					Reason:   reason,
+					Expected: expected,
+					Got:      got,
+				})
+			}
+		}
+	}
+
+	for {
+		got, err := h.WaitAnyTimeout(int(resultWait.Milliseconds() / 10))
+
+		if err != nil {
+			break
+		}
+
+		report.Unexpected = append(report.Unexpected, got)
+	}
+
+	report.Replayed = time.Since(startedAt)
+
+	if len(entries) > 0 {
+		report.Recorded = time.Duration(entries[len(entries)-1].AtNs)
+	}
+
+	return report
+}
+
+func missingReason(err error) string {
+	if errors.Is(err, handler.ErrWaitTimeout) {
+		return "result not delivered"
+	}
+
+	return "wait failed: " + err.Error()
+}
+
+// diff describes how got differs from expected, or returns "" when they match.
+// ExecutionMs is timing, not outcome, and is not compared.
+func diff(expected *dto.Result, got *dto.Result) string {
+	switch {
+	case got.FlowKey != expected.FlowKey || got.TaskKey != expected.TaskKey:
+		return fmt.Sprintf("delivered %s/%s instead", got.FlowKey, got.TaskKey)
+	case got.Method != expected.Method:
+		return fmt.Sprintf("method %q, recorded %q", got.Method, expected.Method)
+	case got.IsError != expected.IsError:
+		return fmt.Sprintf("error %t, recorded %t", got.IsError, expected.IsError)
+	case got.IsCancelled != expected.IsCancelled:
+		return fmt.Sprintf("cancelled %t, recorded %t", got.IsCancelled, expected.IsCancelled)
+	case got.HasNext != expected.HasNext:
+		return fmt.Sprintf("has next %t, recorded %t", got.HasNext, expected.HasNext)
+	case got.Payload != expected.Payload:
+		return "payload differs"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/handler"
+	"sconcur/internal/recorder"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func sleepMessage(t *testing.T, flowKey, taskKey string, ms int64) *dto.Message {
+	t.Helper()
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": ms * 1000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &dto.Message{FlowKey: flowKey, Method: types.MethodSleep, TaskKey: taskKey, Payload: payload}
+}
+
+// record runs a small session on a real handler — sleeps on two flows, a cancelled
+// task, a rejected push, a task answered by its flow stop — and returns its
+// recording.
+func record(t *testing.T) []*recorder.Entry {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "session.rec")
+
+	h := handler.NewHandler()
+	defer h.Close()
+
+	if err := h.StartRecording(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []*dto.Message{
+		sleepMessage(t, "f1", "fast", 5),
+		sleepMessage(t, "f1", "slow", 5000),
+		sleepMessage(t, "f2", "other", 20),
+		sleepMessage(t, "f2", "stopped", 5000),
+	} {
+		if err := h.Push(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := h.Push(&dto.Message{FlowKey: "f2", Method: "unknown", TaskKey: "bad"}); err == nil {
+		t.Fatal("expected the unknown method to be rejected")
+	}
+
+	h.CancelTask("f1", "slow")
+
+	for range 3 {
+		if _, err := h.WaitAnyTimeout(2000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h.StopFlow("f1", nil)
+
+	if stopped := h.StopFlow("f2", nil); len(stopped) != 1 {
+		t.Fatalf("expected the answer of the stopped task, got %+v", stopped)
+	}
+
+	if err := h.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := recorder.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
+func TestReplayReproducesTheRecordedSession(t *testing.T) {
+	entries := record(t)
+
+	report := Run(entries, 500*time.Millisecond)
+
+	if !report.Ok() {
+		t.Fatalf("replay diverged: %+v", report)
+	}
+
+	if report.Pushes != 4 || report.Results != 4 {
+		t.Fatalf("replayed %d pushes and %d results, want 4 and 4", report.Pushes, report.Results)
+	}
+}
+
+func TestReplayReportsADroppedPush(t *testing.T) {
+	var entries []*recorder.Entry
+
+	for _, entry := range record(t) {
+		if entry.Kind == recorder.KindPush && entry.TaskKey == "other" {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	report := Run(entries, 100*time.Millisecond)
+
+	if len(report.Mismatches) != 1 {
+		t.Fatalf("expected one mismatch, got %+v", report.Mismatches)
+	}
+
+	if mismatch := report.Mismatches[0]; mismatch.Expected.TaskKey != "other" || mismatch.Got != nil {
+		t.Fatalf("unexpected mismatch %+v", mismatch)
+	}
+}
+
+func TestReplayReportsResultsTheRecordingNeverSaw(t *testing.T) {
+	msg := sleepMessage(t, "f", "t", 5)
+
+	// A cancel the recorded session never took the answer of.
+	entries := []*recorder.Entry{
+		{Kind: recorder.KindPush, FlowKey: msg.FlowKey, TaskKey: msg.TaskKey, Method: msg.Method, Payload: msg.Payload},
+		{Kind: recorder.KindCancel, FlowKey: msg.FlowKey, TaskKey: msg.TaskKey},
+	}
+
+	report := Run(entries, 100*time.Millisecond)
+
+	if len(report.Mismatches) != 0 {
+		t.Fatalf("unexpected mismatches %+v", report.Mismatches)
+	}
+
+	if len(report.Unexpected) != 1 || !report.Unexpected[0].IsCancelled {
+		t.Fatalf("expected the cancelled result as unexpected, got %+v", report.Unexpected)
+	}
+}
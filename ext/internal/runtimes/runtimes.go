@@ -0,0 +1,119 @@
+// Package runtimes keeps the handlers of the PHP threads that use the extension.
+// Under NTS PHP there is one PHP thread and it uses the default runtime (id 0).
+// Under ZTS with parallel or pthreads every PHP thread creates its own runtime, so
+// each gets its own handler — results channel, flows, pending buffer — and no
+// thread takes another thread's results through WaitAny. The features, and with
+// them the expensive pools (SQL pools, MongoDB clients, HTTP transports), stay
+// process-wide and are shared by all runtimes.
+package runtimes
+
+import (
+	"errors"
+	"fmt"
+	"sconcur/internal/features"
+	"sconcur/internal/handler"
+	"sync"
+)
+
+// DefaultID is the runtime every export uses unless given another id.
+const DefaultID = 0
+
+var errDefaultRuntime = errors.New("the default runtime is released by destroy()")
+
+type Registry struct {
+	// def is the default runtime, read without the lock: it is never replaced.
+	def *handler.Handler
+
+	mutex    sync.RWMutex
+	lastID   int
+	handlers map[int]*handler.Handler
+}
+
+var once sync.Once
+var instance *Registry
+
+func Get() *Registry {
+	once.Do(func() {
+		instance = &Registry{
+			def:      handler.NewHandler(),
+			handlers: make(map[int]*handler.Handler),
+		}
+	})
+
+	return instance
+}
+
+// Create starts a runtime with its own handler and returns its id.
+func (r *Registry) Create() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lastID++
+	r.handlers[r.lastID] = handler.NewHandler()
+
+	return r.lastID
+}
+
+// Lookup returns the handler of the runtime id.
+func (r *Registry) Lookup(id int) (*handler.Handler, error) {
+	if id == DefaultID {
+		return r.def, nil
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found, ok := r.handlers[id]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown runtime %d", id)
+	}
+
+	return found, nil
+}
+
+// Destroy stops every flow of the runtime id and forgets it. The shared features
+// stay up for the other runtimes.
+func (r *Registry) Destroy(id int) error {
+	if id == DefaultID {
+		return errDefaultRuntime
+	}
+
+	r.mutex.Lock()
+	found, ok := r.handlers[id]
+	delete(r.handlers, id)
+	r.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unknown runtime %d", id)
+	}
+
+	found.Close()
+	features.ReleaseRuntime(id)
+
+	return found.StopRecording()
+}
+
+// DestroyAll stops every runtime, shuts the shared features down and leaves a
+// fresh default runtime: the process-wide destroy().
+func (r *Registry) DestroyAll() {
+	r.mutex.Lock()
+	created := r.handlers
+	r.handlers = make(map[int]*handler.Handler)
+	r.mutex.Unlock()
+
+	for _, created := range created {
+		created.Close()
+		_ = created.StopRecording()
+	}
+
+	r.def.Destroy()
+}
+
+// Count returns the number of runtimes, the default one included.
+func (r *Registry) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.handlers) + 1
+}
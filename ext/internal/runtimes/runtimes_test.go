@@ -0,0 +1,108 @@
+package runtimes_test
+
+import (
+	"errors"
+	"testing"
+
+	"sconcur/internal/dto"
+	"sconcur/internal/handler"
+	"sconcur/internal/runtimes"
+	"sconcur/internal/types"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func sleepMessage(t *testing.T, flowKey string) *dto.Message {
+	t.Helper()
+
+	payload, err := msgpack.Marshal(map[string]int64{"us": 1000})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &dto.Message{FlowKey: flowKey, Method: types.MethodSleep, TaskKey: flowKey + ":1", Payload: payload}
+}
+
+func create(t *testing.T) (int, *handler.Handler) {
+	t.Helper()
+
+	registry := runtimes.Get()
+	id := registry.Create()
+
+	h, err := registry.Lookup(id)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = registry.Destroy(id)
+	})
+
+	return id, h
+}
+
+func TestRuntimesDoNotShareResults(t *testing.T) {
+	_, first := create(t)
+	_, second := create(t)
+
+	if err := first.Push(sleepMessage(t, "first")); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := second.GetTasksCount(); count != 0 {
+		t.Fatalf("the second runtime counts %d tasks of the first", count)
+	}
+
+	if _, err := second.WaitAnyTimeout(100); !errors.Is(err, handler.ErrWaitTimeout) {
+		t.Fatalf("the second runtime must see no result, got %v", err)
+	}
+
+	result, err := first.WaitAnyTimeout(2000)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.FlowKey != "first" || result.IsError {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestDestroyForgetsOnlyThatRuntime(t *testing.T) {
+	registry := runtimes.Get()
+	id, _ := create(t)
+
+	if err := registry.Destroy(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.Lookup(id); err == nil {
+		t.Fatal("a destroyed runtime must be unknown")
+	}
+
+	if err := registry.Destroy(id); err == nil {
+		t.Fatal("destroying a runtime twice must fail")
+	}
+
+	def, err := registry.Lookup(runtimes.DefaultID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = def.Push(sleepMessage(t, "default")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = def.WaitAnyTimeout(2000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultRuntimeIsNotDestroyedAlone(t *testing.T) {
+	if err := runtimes.Get().Destroy(runtimes.DefaultID); err == nil {
+		t.Fatal("the default runtime must only be released by destroy()")
+	}
+}
@@ -0,0 +1,142 @@
+package states
+
+import (
+	"fmt"
+	"sconcur/internal/contracts"
+	"sconcur/internal/dto"
+	"sync"
+)
+
+var _ contracts.StateContract = (*prefetchState)(nil)
+
+// stateClosedMessage answers a next() that was still waiting for a prefetched
+// batch when the state got closed.
+const stateClosedMessage = "state closed"
+
+// maxPrefetchDepth bounds a read-ahead: the fetched batches are held in Go memory,
+// and the depth comes from PHP.
+const maxPrefetchDepth = 1 << 10
+
+// prefetchState reads ahead of PHP: from the first Next on, a background
+// goroutine keeps pulling batches from the wrapped state, holding at most depth of
+// them, so a next() returns at once when its batch is already fetched and the
+// round-trip for the following batch overlaps with PHP processing the current one.
+type prefetchState struct {
+	state   contracts.StateContract
+	message *dto.Message
+	// results hands the fetched batches over; the producer blocked on the send
+	// holds one more, so the buffer is depth-1.
+	results chan *dto.Result
+	done    chan struct{}
+	// stopped is closed once no producer runs and none will start: by produce on
+	// its way out, or by Close when no Next started one.
+	stopped   chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// CheckPrefetchDepth rejects a read-ahead depth Prefetch must not be given: a
+// negative one or one past maxPrefetchDepth. Features check the depth of a
+// payload before opening the state.
+func CheckPrefetchDepth(depth int) error {
+	if depth < 0 || depth > maxPrefetchDepth {
+		return fmt.Errorf("prefetch depth must be between 0 and %d", maxPrefetchDepth)
+	}
+
+	return nil
+}
+
+// Prefetch wraps state with a read-ahead of depth batches. depth <= 0 returns the
+// state unchanged (every batch is fetched when PHP asks for it); a depth past
+// maxPrefetchDepth is cut to it (see CheckPrefetchDepth).
+func Prefetch(state contracts.StateContract, message *dto.Message, depth int) contracts.StateContract {
+	if depth <= 0 {
+		return state
+	}
+
+	return &prefetchState{
+		state:   state,
+		message: message,
+		results: make(chan *dto.Result, min(depth, maxPrefetchDepth)-1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (s *prefetchState) Next() *dto.Result {
+	s.startOnce.Do(func() {
+		go s.produce()
+	})
+
+	// A closed state answers cancelled even when a fetched batch is still pending.
+	select {
+	case <-s.done:
+		return dto.NewCancelledResult(s.message, stateClosedMessage)
+	default:
+	}
+
+	select {
+	case result, ok := <-s.results:
+		if ok {
+			return result
+		}
+	case <-s.done:
+	}
+
+	return dto.NewCancelledResult(s.message, stateClosedMessage)
+}
+
+// produce pulls batches until the wrapped state reports its last one (or an
+// error) or the state is closed. Only this goroutine calls the wrapped Next, and
+// Close waits for it to exit, so the wrapped state never sees concurrent calls.
+func (s *prefetchState) produce() {
+	defer close(s.stopped)
+	defer close(s.results)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		result := s.state.Next()
+
+		select {
+		case s.results <- result:
+		case <-s.done:
+			return
+		}
+
+		if !result.HasNext || result.IsError {
+			return
+		}
+	}
+}
+
+// Close stops the read-ahead, waits for a read in progress to return (the wrapped
+// state bounds it by its task context) and then closes the wrapped state; a batch
+// fetched but not delivered yet is dropped.
+func (s *prefetchState) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		// Without a Next so far no producer runs, and none may start any more.
+		s.startOnce.Do(func() {
+			close(s.stopped)
+		})
+
+		<-s.stopped
+
+		s.state.Close()
+	})
+}
+
+// unwrapState returns the state behind a read-ahead wrapper (for introspection).
+func unwrapState(state contracts.StateContract) contracts.StateContract {
+	if prefetch, ok := state.(*prefetchState); ok {
+		return prefetch.state
+	}
+
+	return state
+}
@@ -0,0 +1,220 @@
+package states
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"sconcur/internal/dto"
+)
+
+// batchState serves batches "0".."total-1", counting the reads it was asked for.
+type batchState struct {
+	mutex   sync.Mutex
+	message *dto.Message
+	total   int
+	reads   int
+	closed  bool
+}
+
+func (s *batchState) Next() *dto.Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	batch := s.reads
+	s.reads++
+
+	if batch == s.total-1 {
+		return dto.NewSuccessResult(s.message, strconv.Itoa(batch), 0)
+	}
+
+	return dto.NewSuccessResultWithNext(s.message, strconv.Itoa(batch), 0)
+}
+
+func (s *batchState) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+}
+
+func (s *batchState) Reads() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.reads
+}
+
+func (s *batchState) Closed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.closed
+}
+
+func waitForReads(t *testing.T, state *batchState, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for state.Reads() < expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d reads, got %d", expected, state.Reads())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPrefetchWithoutDepthReturnsStateAsIs(t *testing.T) {
+	inner := &batchState{message: &dto.Message{}, total: 3}
+
+	if Prefetch(inner, inner.message, 0) != inner {
+		t.Fatal("depth 0 must not wrap the state")
+	}
+}
+
+func TestPrefetchReadsAheadUpToDepth(t *testing.T) {
+	inner := &batchState{message: &dto.Message{}, total: 10}
+
+	state := Prefetch(inner, inner.message, 2)
+
+	if result := state.Next(); result.Payload != "0" {
+		t.Fatalf("expected batch 0, got %q", result.Payload)
+	}
+
+	// Batch 0 delivered, batches 1 and 2 read ahead — and no further.
+	waitForReads(t, inner, 3)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if reads := inner.Reads(); reads != 3 {
+		t.Fatalf("read-ahead must stop at depth 2, got %d reads", reads)
+	}
+
+	for batch := 1; batch < 10; batch++ {
+		result := state.Next()
+
+		if result.Payload != strconv.Itoa(batch) {
+			t.Fatalf("expected batch %d, got %q", batch, result.Payload)
+		}
+
+		if result.HasNext != (batch < 9) {
+			t.Fatalf("unexpected HasNext on batch %d", batch)
+		}
+	}
+
+	if reads := inner.Reads(); reads != 10 {
+		t.Fatalf("must stop after the last batch, got %d reads", reads)
+	}
+}
+
+func TestPrefetchCloseStopsReadAheadAndClosesState(t *testing.T) {
+	inner := &batchState{message: &dto.Message{}, total: 100}
+
+	state := Prefetch(inner, inner.message, 1)
+
+	state.Next()
+
+	waitForReads(t, inner, 2)
+
+	state.Close()
+
+	if !inner.Closed() {
+		t.Fatal("Close must close the wrapped state")
+	}
+
+	result := state.Next()
+
+	if !result.IsError || !result.IsCancelled {
+		t.Fatal("next on a closed state must answer cancelled")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if reads := inner.Reads(); reads != 2 {
+		t.Fatalf("read-ahead must stop once closed, got %d reads", reads)
+	}
+}
+
+// overlapState is a slow state that records a Close arriving while a Next runs.
+type overlapState struct {
+	mutex   sync.Mutex
+	message *dto.Message
+	reading bool
+	overlap bool
+	closed  bool
+}
+
+func (s *overlapState) Next() *dto.Result {
+	s.mutex.Lock()
+	s.reading = true
+	s.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mutex.Lock()
+	s.reading = false
+	s.mutex.Unlock()
+
+	return dto.NewSuccessResultWithNext(s.message, "batch", 0)
+}
+
+func (s *overlapState) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.overlap = s.overlap || s.reading
+	s.closed = true
+}
+
+func TestPrefetchCloseWaitsForTheReadInProgress(t *testing.T) {
+	inner := &overlapState{message: &dto.Message{}}
+
+	state := Prefetch(inner, inner.message, 2)
+
+	state.Next()
+
+	// The producer is now inside the next read ahead.
+	time.Sleep(5 * time.Millisecond)
+
+	state.Close()
+
+	inner.mutex.Lock()
+	defer inner.mutex.Unlock()
+
+	if !inner.closed || inner.overlap {
+		t.Fatalf("closed %t, overlapping a read %t: Close must wait for the read", inner.closed, inner.overlap)
+	}
+}
+
+func TestPrefetchCloseBeforeNextStartsNoRead(t *testing.T) {
+	inner := &batchState{message: &dto.Message{}, total: 3}
+
+	state := Prefetch(inner, inner.message, 2)
+
+	state.Close()
+
+	if result := state.Next(); !result.IsCancelled {
+		t.Fatal("next on a closed state must answer cancelled")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if reads := inner.Reads(); reads != 0 {
+		t.Fatalf("a closed state must not be read, got %d reads", reads)
+	}
+}
+
+func TestCheckPrefetchDepthRejectsOversizedDepths(t *testing.T) {
+	for _, depth := range []int{-1, maxPrefetchDepth + 1, 1 << 62} {
+		if CheckPrefetchDepth(depth) == nil {
+			t.Fatalf("depth %d must be rejected", depth)
+		}
+	}
+
+	if err := CheckPrefetchDepth(maxPrefetchDepth); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,25 +3,76 @@ package states
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sconcur/internal/contracts"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
 	"sconcur/internal/tasks"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var once sync.Once
 var instance *States
 
+// sweepInterval is how often the idle reaper scans the registry. The scan is a
+// no-op while no idle TTL is set.
+const sweepInterval = 5 * time.Second
+
 type States struct {
 	mutex  sync.RWMutex
-	states map[string]contracts.StateContract
+	states map[string]*entry
+
+	// idleTTL (nanoseconds, 0 = disabled) closes a state left untouched — no
+	// next(), no lookup — for longer: a PHP coroutine that abandoned a cursor or a
+	// request body would otherwise keep it (and its connection/buffer) forever.
+	// Held states (contracts.HeldStateContract) are never reaped.
+	idleTTL atomic.Int64
+	reaped  atomic.Int64
+}
+
+// entry is one registered state plus the bookkeeping the registry keeps for it.
+type entry struct {
+	state contracts.StateContract
+	// deadline is the deadline of the task that opened the state (zero if none):
+	// every next() on the state is bounded by it.
+	deadline  time.Time
+	createdAt time.Time
+	// lastUsedAt (unix nanoseconds) is refreshed by every next() and lookup; busy
+	// counts next() calls in progress — a state blocked in Next (a socket waiting
+	// for a message) is in use, however long it waits.
+	lastUsedAt atomic.Int64
+	busy       atomic.Int32
+}
+
+func newEntry(state contracts.StateContract, deadline time.Time) *entry {
+	now := time.Now()
+
+	stored := &entry{state: state, deadline: deadline, createdAt: now}
+	stored.lastUsedAt.Store(now.UnixNano())
+
+	return stored
+}
+
+// StateSnapshot describes a registered state for runtime introspection (inspect
+// export): Type is the concrete state type, e.g. "*find_state.FindState".
+type StateSnapshot struct {
+	TaskKey    string `json:"taskKey"`
+	Type       string `json:"type"`
+	AgeMs      int64  `json:"ageMs"`
+	IdleMs     int64  `json:"idleMs"`
+	DeadlineMs int64  `json:"deadlineMs,omitempty"`
 }
 
 func Get() *States {
 	once.Do(func() {
 		instance = &States{
-			states: make(map[string]contracts.StateContract),
+			states: make(map[string]*entry),
 		}
+
+		instance.startSweeper()
 	})
 
 	return instance
@@ -38,7 +89,13 @@ func (s *States) Start(ctx context.Context, taskKey string, state contracts.Stat
 		return nil, errors.New("state already exists")
 	}
 
-	s.states[taskKey] = state
+	// The opening context carries the task deadline (and any tighter feature
+	// deadline derived from it); remember it for the next() calls.
+	deadline, _ := ctx.Deadline()
+
+	stored := newEntry(state, deadline)
+
+	s.states[taskKey] = stored
 
 	s.mutex.Unlock()
 
@@ -46,7 +103,7 @@ func (s *States) Start(ctx context.Context, taskKey string, state contracts.Stat
 		s.DeleteState(taskKey)
 	})
 
-	return s.handleNext(taskKey, state), nil
+	return s.handleNext(taskKey, stored), nil
 }
 
 // Register stores a state without reading its first batch (unlike Start) and
@@ -61,7 +118,7 @@ func (s *States) Register(taskKey string, state contracts.StateContract) error {
 		return errors.New("state already exists")
 	}
 
-	s.states[taskKey] = state
+	s.states[taskKey] = newEntry(state, time.Time{})
 
 	return nil
 }
@@ -69,20 +126,20 @@ func (s *States) Register(taskKey string, state contracts.StateContract) error {
 func (s *States) Next(task *tasks.Task) {
 	message := task.GetMessage()
 
-	state := s.GetState(message.TaskKey)
+	stored := s.getEntry(message.TaskKey)
 
-	if state == nil {
+	if stored == nil {
 		task.AddResult(
 			dto.NewErrorResult(
 				message,
-				"state not started",
+				errs.Invalid("state not started"),
 			),
 		)
 
 		return
 	}
 
-	result := s.handleNext(message.TaskKey, state)
+	result := s.handleNext(message.TaskKey, stored)
 
 	// The cursor state keeps the original message, but each next() may arrive on
 	// a different flow (a sync cursor uses a fresh flow per batch). Route the
@@ -93,21 +150,84 @@ func (s *States) Next(task *tasks.Task) {
 	task.AddResult(result)
 }
 
+// GetState returns a registered state, refreshing its idle time: a state looked
+// up out of band (an open transaction, an upload) is in use even without next().
 func (s *States) GetState(taskKey string) contracts.StateContract {
+	stored := s.getEntry(taskKey)
+
+	if stored == nil {
+		return nil
+	}
+
+	return stored.state
+}
+
+func (s *States) getEntry(taskKey string) *entry {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	state, ok := s.states[taskKey]
+	stored, ok := s.states[taskKey]
 
 	if !ok {
 		return nil
 	}
 
-	return state
+	stored.lastUsedAt.Store(time.Now().UnixNano())
+
+	return stored
 }
 
-func (s *States) handleNext(taskKey string, state contracts.StateContract) *dto.Result {
-	result := state.Next()
+// GetDeadline returns the deadline inherited by a next() on the state (the one of
+// the task that opened it). ok is false when the state is unknown or unbounded.
+func (s *States) GetDeadline(taskKey string) (deadline time.Time, ok bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored, found := s.states[taskKey]
+
+	if !found || stored.deadline.IsZero() {
+		return time.Time{}, false
+	}
+
+	return stored.deadline, true
+}
+
+// Snapshot captures every registered state, oldest first.
+func (s *States) Snapshot(now time.Time) []StateSnapshot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshots := make([]StateSnapshot, 0, len(s.states))
+
+	for taskKey, stored := range s.states {
+		snapshot := StateSnapshot{
+			TaskKey: taskKey,
+			Type:    fmt.Sprintf("%T", unwrapState(stored.state)),
+			AgeMs:   now.Sub(stored.createdAt).Milliseconds(),
+			IdleMs:  now.Sub(time.Unix(0, stored.lastUsedAt.Load())).Milliseconds(),
+		}
+
+		if !stored.deadline.IsZero() {
+			snapshot.DeadlineMs = stored.deadline.UnixMilli()
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].AgeMs > snapshots[j].AgeMs
+	})
+
+	return snapshots
+}
+
+func (s *States) handleNext(taskKey string, stored *entry) *dto.Result {
+	stored.busy.Add(1)
+
+	result := stored.state.Next()
+
+	stored.lastUsedAt.Store(time.Now().UnixNano())
+	stored.busy.Add(-1)
 
 	if !result.HasNext {
 		s.DeleteState(taskKey)
@@ -118,10 +238,75 @@ func (s *States) handleNext(taskKey string, state contracts.StateContract) *dto.
 	return result
 }
 
+// SetIdleTTL sets how long a state may stay untouched before the reaper closes
+// it; zero disables reaping (the default).
+func (s *States) SetIdleTTL(ttl time.Duration) {
+	s.idleTTL.Store(int64(max(ttl, 0)))
+}
+
+// ReapedCount is the number of states closed by the idle reaper so far.
+func (s *States) ReapedCount() int64 {
+	return s.reaped.Load()
+}
+
+func (s *States) startSweeper() {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			s.sweep(now)
+		}
+	}()
+}
+
+// sweep closes every state idle past the TTL and returns how many it closed.
+func (s *States) sweep(now time.Time) int {
+	ttl := time.Duration(s.idleTTL.Load())
+
+	if ttl == 0 {
+		return 0
+	}
+
+	expired := s.collectIdle(now.Add(-ttl).UnixNano())
+
+	// Close outside the lock, as DeleteState does.
+	for _, stored := range expired {
+		stored.state.Close()
+	}
+
+	s.reaped.Add(int64(len(expired)))
+
+	return len(expired)
+}
+
+func (s *States) collectIdle(idleBefore int64) []*entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var expired []*entry
+
+	for taskKey, stored := range s.states {
+		if stored.busy.Load() > 0 || stored.lastUsedAt.Load() >= idleBefore {
+			continue
+		}
+
+		if _, held := unwrapState(stored.state).(contracts.HeldStateContract); held {
+			continue
+		}
+
+		delete(s.states, taskKey)
+
+		expired = append(expired, stored)
+	}
+
+	return expired
+}
+
 func (s *States) DeleteState(taskKey string) {
 	s.mutex.Lock()
 
-	state, ok := s.states[taskKey]
+	stored, ok := s.states[taskKey]
 
 	delete(s.states, taskKey)
 
@@ -129,6 +314,6 @@ func (s *States) DeleteState(taskKey string) {
 
 	// Close outside the lock: it may do network I/O (killCursors).
 	if ok {
-		state.Close()
+		stored.state.Close()
 	}
 }
@@ -81,3 +81,134 @@ func TestContextCancellationClosesState(t *testing.T) {
 
 	t.Fatal("cancelling the task context must close the state")
 }
+
+func TestStartRecordsOpeningDeadlineForNext(t *testing.T) {
+	taskKey := "states-test-deadline"
+
+	stub := &stubState{message: &dto.Message{TaskKey: taskKey}}
+
+	deadline := time.Now().Add(time.Minute)
+
+	ctx, ctxCancel := context.WithDeadline(context.Background(), deadline)
+	defer ctxCancel()
+
+	if _, err := Get().Start(ctx, taskKey, stub); err != nil {
+		t.Fatal(err)
+	}
+
+	defer Get().DeleteState(taskKey)
+
+	got, ok := Get().GetDeadline(taskKey)
+
+	if !ok || !got.Equal(deadline) {
+		t.Fatalf("expected the opening deadline %v, got %v (ok=%v)", deadline, got, ok)
+	}
+
+	if _, ok := Get().GetDeadline("states-test-unknown"); ok {
+		t.Fatal("an unknown state has no deadline")
+	}
+}
+
+func TestSnapshotReportsConcreteStateType(t *testing.T) {
+	taskKey := "states-test-snapshot"
+
+	stub := &stubState{message: &dto.Message{TaskKey: taskKey}}
+
+	if err := Get().Register(taskKey, stub); err != nil {
+		t.Fatal(err)
+	}
+
+	defer Get().DeleteState(taskKey)
+
+	for _, snapshot := range Get().Snapshot(time.Now()) {
+		if snapshot.TaskKey != taskKey {
+			continue
+		}
+
+		if snapshot.Type != "*states.stubState" {
+			t.Fatalf("expected the concrete state type, got %q", snapshot.Type)
+		}
+
+		return
+	}
+
+	t.Fatal("registered state missing from the snapshot")
+}
+
+func TestSweepClosesOnlyIdleStates(t *testing.T) {
+	registry := &States{states: make(map[string]*entry)}
+	registry.SetIdleTTL(time.Minute)
+
+	idle := &stubState{message: &dto.Message{TaskKey: "idle"}}
+	fresh := &stubState{message: &dto.Message{TaskKey: "fresh"}}
+
+	if err := registry.Register("idle", idle); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.Register("fresh", fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.states["idle"].lastUsedAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+
+	if reaped := registry.sweep(time.Now()); reaped != 1 {
+		t.Fatalf("expected one reaped state, got %d", reaped)
+	}
+
+	if idle.Closed() != 1 || fresh.Closed() != 0 {
+		t.Fatalf("expected only the idle state closed, got idle=%d fresh=%d", idle.Closed(), fresh.Closed())
+	}
+
+	if registry.GetState("idle") != nil || registry.GetState("fresh") == nil {
+		t.Fatal("expected only the idle state dropped from the registry")
+	}
+
+	if registry.ReapedCount() != 1 {
+		t.Fatalf("expected the reaped count exposed, got %d", registry.ReapedCount())
+	}
+}
+
+func TestSweepKeepsStateBlockedInNext(t *testing.T) {
+	registry := &States{states: make(map[string]*entry)}
+	registry.SetIdleTTL(time.Minute)
+
+	blocked := &stubState{message: &dto.Message{TaskKey: "blocked"}}
+
+	if err := registry.Register("blocked", blocked); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := registry.states["blocked"]
+	stored.lastUsedAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	stored.busy.Add(1)
+
+	if reaped := registry.sweep(time.Now()); reaped != 0 {
+		t.Fatalf("a state with a next() in progress must not be reaped, got %d", reaped)
+	}
+}
+
+type heldStubState struct {
+	stubState
+}
+
+func (s *heldStubState) Held() {}
+
+// TestSweepKeepsHeldStates checks a state PHP holds on purpose (a transaction, a
+// permit) outlives the idle TTL.
+func TestSweepKeepsHeldStates(t *testing.T) {
+	registry := &States{states: make(map[string]*entry)}
+	registry.SetIdleTTL(time.Minute)
+
+	held := &heldStubState{stubState{message: &dto.Message{TaskKey: "held"}}}
+
+	if err := registry.Register("held", held); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.states["held"].lastUsedAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+
+	if reaped := registry.sweep(time.Now()); reaped != 0 || held.Closed() != 0 {
+		t.Fatalf("a held state must not be reaped, got %d reaped", reaped)
+	}
+}
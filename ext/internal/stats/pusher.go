@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"sconcur/internal/crashes"
 	"sconcur/internal/socket"
 	"time"
 )
@@ -158,6 +159,7 @@ func (pusher *Pusher) buildSnapshot(now time.Time) Snapshot {
 		Goroutines:    runtime.NumGoroutine(),
 		Requests:      workload.Requests,
 		Connections:   workload.Connections,
+		Crashes:       crashes.Get().Counts(),
 	}
 }
 
@@ -14,9 +14,12 @@
 //
 // The process-level metrics (memory, CPU, goroutines, uptime) are universal; the
 // workload section is feature-specific and supplied through a WorkloadProvider
-// (HTTP fills Requests, socket fills Connections).
+// (HTTP fills Requests, socket fills Connections). Crashes counts the panics the
+// worker recovered (see internal/crashes), so the collector can alert on them.
 package stats
 
+import "sconcur/internal/crashes"
+
 // Memory holds the process memory split. RssBytes is the whole process resident
 // set (with the extension); GoRuntimeBytes is the Go runtime's own footprint;
 // NonExtensionBytes is the remainder (the PHP interpreter + Zend heap), derived
@@ -75,4 +78,6 @@ type Snapshot struct {
 	Goroutines    int          `json:"goroutines"`
 	Requests      *Requests    `json:"requests,omitempty"`
 	Connections   *Connections `json:"connections,omitempty"`
+	// Crashes are the lifetime panic counters of the worker process.
+	Crashes crashes.Counts `json:"crashes"`
 }
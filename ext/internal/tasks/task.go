@@ -2,18 +2,32 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/readiness"
 	"sync"
+	"time"
 )
 
 type Task struct {
 	msg       *dto.Message
 	res       *dto.Result
+	flowCtx   context.Context
 	ctx       context.Context
 	ctxCancel context.CancelFunc
 	results   chan *dto.Result
-	mutex     sync.Mutex
-	cancelled bool
+	// notifier is the readiness descriptor of the handler reading results (nil
+	// when none): it is signalled once a result is in the channel.
+	notifier *readiness.Notifier
+	mutex    sync.Mutex
+	// resolved marks that the task's single result has been claimed — by the
+	// feature (AddResult) or by CancelWithResult — so a task never answers twice.
+	resolved bool
+	// dropping discards the feature's result without claiming the task (fault
+	// injection): the deadline, a cancel or the flow stop still answer it.
+	dropping  bool
+	startedAt time.Time
 }
 
 func NewTask(
@@ -21,20 +35,61 @@ func NewTask(
 	results chan *dto.Result,
 	msg *dto.Message,
 ) *Task {
-	ctx, cancel := context.WithCancel(flowCtx)
+	return NewTaskWithDeadline(flowCtx, results, nil, msg, time.Time{})
+}
+
+// NewTaskWithDeadline builds a task whose context expires at deadline (none when
+// zero). An expired task is answered with the uniform timeout result the moment
+// the deadline passes, whatever the feature is still doing; the feature sees its
+// context done and unwinds, and its late result is dropped. Each result put into
+// results signals notifier (see package readiness).
+func NewTaskWithDeadline(
+	flowCtx context.Context,
+	results chan *dto.Result,
+	notifier *readiness.Notifier,
+	msg *dto.Message,
+	deadline time.Time,
+) *Task {
+	var ctx context.Context
+	var cancel context.CancelFunc
 
-	return &Task{
+	if deadline.IsZero() {
+		ctx, cancel = context.WithCancel(flowCtx)
+	} else {
+		ctx, cancel = context.WithDeadline(flowCtx, deadline)
+	}
+
+	task := &Task{
 		msg:       msg,
+		flowCtx:   flowCtx,
 		ctx:       ctx,
 		ctxCancel: cancel,
 		results:   results,
+		notifier:  notifier,
+		startedAt: time.Now(),
+	}
+
+	if !deadline.IsZero() {
+		context.AfterFunc(ctx, func() {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				task.CancelWithResult(dto.NewTimeoutResult(msg))
+			}
+		})
 	}
+
+	return task
 }
 
 func (t *Task) GetContext() context.Context {
 	return t.ctx
 }
 
+// GetFlowContext returns the context of the task's flow: unlike the task context it
+// outlives the task and is cancelled only when the flow is stopped.
+func (t *Task) GetFlowContext() context.Context {
+	return t.flowCtx
+}
+
 func (t *Task) Cancel() {
 	t.ctxCancel()
 }
@@ -43,9 +98,111 @@ func (t *Task) GetMessage() *dto.Message {
 	return t.msg
 }
 
-func (t *Task) AddResult(result *dto.Result) {
+func (t *Task) GetStartedAt() time.Time {
+	return t.startedAt
+}
+
+// AddResult publishes the feature's result and reports whether it became the
+// task's answer: it is dropped (false) when the task was already answered by
+// CancelWithResult. The send is bounded by the flow context,
+// not the task one: a task cancelled on its own after claiming its result must
+// still deliver it, while a flow stop aborts a send blocked on a full channel.
+//
+// An error the feature reports once the deadline has passed is the feature
+// unwinding from it: it is answered with the uniform timeout result too, even when
+// the feature claims the task before the deadline hook does. Likewise an error
+// reported once the flow was stopped is answered with the stop cause (see
+// errs.StopCause), whatever text the feature put in it.
+func (t *Task) AddResult(result *dto.Result) bool {
+	if t.isDropping() {
+		return false
+	}
+
+	if !t.claim() {
+		return false
+	}
+
+	if result.IsError {
+		if errors.Is(t.ctx.Err(), context.DeadlineExceeded) {
+			result = dto.NewTimeoutResult(t.msg)
+		} else if cause := errs.CauseOf(t.ctx); cause != nil {
+			result = dto.NewStoppedResult(t.msg, cause)
+		}
+	}
+
 	select {
 	case t.results <- result:
-	case <-t.ctx.Done():
+		t.notifier.Signal()
+	case <-t.flowCtx.Done():
+	}
+
+	return true
+}
+
+// CancelWithResult aborts the task on its own while its flow keeps running: the
+// task context is cancelled so the feature unwinds, and the given result answers
+// the task instead of the feature's. Returns false when the feature had already
+// claimed its result — that one is (being) delivered and stays the answer.
+//
+// The send runs in the background: the caller is the PHP thread, the only
+// consumer of the results channel, and must not block on a full buffer.
+func (t *Task) CancelWithResult(result *dto.Result) bool {
+	claimed := t.claim()
+
+	t.ctxCancel()
+
+	if !claimed {
+		return false
+	}
+
+	go func() {
+		select {
+		case t.results <- result:
+			t.notifier.Signal()
+		case <-t.flowCtx.Done():
+		}
+	}()
+
+	return true
+}
+
+// Stopped answers the task in place of its feature when its flow is stopped: it
+// returns the cancelled result carrying cause, or nil when the task had already
+// claimed its result. The feature then unwinds on the flow context and its own
+// result is dropped.
+func (t *Task) Stopped(cause *errs.StopCause) *dto.Result {
+	if !t.claim() {
+		return nil
 	}
+
+	return dto.NewStoppedResult(t.msg, cause)
+}
+
+// DropResult makes AddResult discard the feature's results, as if lost on their
+// way to PHP. Used by fault injection (see package faults).
+func (t *Task) DropResult() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.dropping = true
+}
+
+func (t *Task) isDropping() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.dropping
+}
+
+func (t *Task) claim() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.resolved {
+		return false
+	}
+
+	t.resolved = true
+
+	return true
 }
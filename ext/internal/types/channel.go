@@ -0,0 +1,17 @@
+package types
+
+// ChannelCommand selects a sub-operation of the channel feature, carried in the
+// payload envelope's cm field under the single MethodChannel.
+// PHP: SConcur\Features\Channel\ChannelCommandEnum.
+type ChannelCommand string
+
+const (
+	// ChannelCreate creates the named channel (or joins it when it exists).
+	ChannelCreate ChannelCommand = "crt"
+	// ChannelSend puts one value into the channel, waiting while it is full.
+	ChannelSend ChannelCommand = "snd"
+	// ChannelReceive takes one value, waiting until one arrives.
+	ChannelReceive ChannelCommand = "rcv"
+	// ChannelClose closes the channel: buffered values can still be received.
+	ChannelClose ChannelCommand = "cls"
+)
@@ -9,4 +9,8 @@ const (
 	HttpClientRequest     HttpClientCommand = "req"
 	HttpClientUploadChunk HttpClientCommand = "upc"
 	HttpClientUploadEnd   HttpClientCommand = "upe"
+	HttpClientJarOpen     HttpClientCommand = "jop"
+	HttpClientJarExport   HttpClientCommand = "jex"
+	HttpClientJarImport   HttpClientCommand = "jim"
+	HttpClientJarClose    HttpClientCommand = "jcl"
 )
@@ -3,17 +3,21 @@ package types
 type Method string
 
 const (
-	MethodSleep       Method = "sl"
-	MethodMongodb     Method = "mng"
-	MethodHttpServe   Method = "hs"
-	MethodHttpRespond Method = "hr"
-	MethodHttpClient  Method = "hc"
-	MethodMysql       Method = "my"
-	MethodPgsql       Method = "pg"
+	MethodSleep         Method = "sl"
+	MethodMongodb       Method = "mng"
+	MethodHttpServe     Method = "hs"
+	MethodHttpRespond   Method = "hr"
+	MethodHttpClient    Method = "hc"
+	MethodMysql         Method = "my"
+	MethodPgsql         Method = "pg"
 	MethodSocketServe   Method = "ss"
 	MethodSocketRespond Method = "sr"
 	MethodSocketClient  Method = "sc"
-	MethodWsServe    Method = "wss"
-	MethodWsRespond  Method = "wsr"
-	MethodWsClient   Method = "wsc"
+	MethodWsServe       Method = "wss"
+	MethodWsRespond     Method = "wsr"
+	MethodWsClient      Method = "wsc"
+	MethodTicker        Method = "tk"
+	MethodCron          Method = "cr"
+	MethodChannel       Method = "chn"
+	MethodSemaphore     Method = "sem"
 )
@@ -9,19 +9,32 @@ typedef struct {
 	void *data;
 	int len;
 	char *err;
+	// release is the release word of a span of the runtime's result arena: the
+	// C side sets it to 1 once data is copied out, instead of freeing data.
+	// NULL for a buffer of its own.
+	unsigned int *release;
 } buffer_result_t;
 */
 import "C"
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"math"
+	"sconcur/internal/compression"
 	"sconcur/internal/dto"
+	"sconcur/internal/errs"
+	"sconcur/internal/faults"
 	httpserver_feature "sconcur/internal/features/httpserver"
 	socketserver_feature "sconcur/internal/features/socketserver"
 	wsserver_feature "sconcur/internal/features/wsserver"
+	"sconcur/internal/framemem"
 	handler2 "sconcur/internal/handler"
 	"sconcur/internal/logger"
+	"sconcur/internal/runtimes"
+	"sconcur/internal/states"
 	"sconcur/internal/types"
+	"time"
 	"unsafe"
 )
 
@@ -30,7 +43,8 @@ import (
 // stays MessagePack and is decoded once on the PHP side. Mirrors how push passes
 // its envelope as separate arguments. Must match Extension::parseWaitResponse.
 //
-//	[0]      flags    uint8  (bit0 isError, bit1 hasNext)
+//	[0]      flags    uint8  (bit0 isError, bit1 hasNext, bit2 isCancelled,
+//	                  bit3 compressed)
 //	[1]      method   length uint8
 //	[2:6]    execMs   uint32 (big-endian)
 //	[6:8]    flowKey  length uint16 (big-endian)
@@ -41,58 +55,284 @@ const (
 	frameHeaderSize  = 10
 	frameFlagError   = 1 << 0
 	frameFlagHasNext = 1 << 1
+	// frameFlagCancelled marks the result of a task aborted via cancelTask; it
+	// always comes together with frameFlagError.
+	frameFlagCancelled = 1 << 2
+	// frameFlagCompressed marks a zstd-compressed payload (see package
+	// compression); the header and keys are never compressed.
+	frameFlagCompressed = 1 << 3
 )
 
-// buildResultFrame serializes a result envelope as the fixed binary header followed
-// by the raw (already-encoded) payload bytes.
-func buildResultFrame(result *dto.Result) []byte {
-	method := []byte(result.Method)
-	flowKey := []byte(result.FlowKey)
-	taskKey := []byte(result.TaskKey)
-	payload := []byte(result.Payload)
-
-	frame := make([]byte, frameHeaderSize+len(method)+len(flowKey)+len(taskKey)+len(payload))
+// pushFlagCompressed is the flags bit of a push whose payload the PHP side
+// zstd-compressed. Must match Extension::PUSH_FLAG_COMPRESSED.
+const pushFlagCompressed = 1 << 0
+
+// resultFrame is a result envelope ready to be written as the fixed binary header
+// followed by the raw (already-encoded) payload bytes.
+type resultFrame struct {
+	flags       byte
+	executionMs int
+	method      string
+	flowKey     string
+	taskKey     string
+	payload     string
+}
 
-	var flags byte
+// newResultFrame frames a result of runtime rt, compressing its payload past the
+// runtime's threshold.
+func newResultFrame(result *dto.Result, rt C.int) resultFrame {
+	frame := resultFrame{
+		executionMs: result.ExecutionMs,
+		method:      string(result.Method),
+		flowKey:     result.FlowKey,
+		taskKey:     result.TaskKey,
+		payload:     result.Payload,
+	}
 
 	if result.IsError {
-		flags |= frameFlagError
+		frame.flags |= frameFlagError
 	}
 
 	if result.HasNext {
-		flags |= frameFlagHasNext
+		frame.flags |= frameFlagHasNext
 	}
 
-	frame[0] = flags
-	frame[1] = byte(len(method))
-	binary.BigEndian.PutUint32(frame[2:6], uint32(result.ExecutionMs))
-	binary.BigEndian.PutUint16(frame[6:8], uint16(len(flowKey)))
-	binary.BigEndian.PutUint16(frame[8:10], uint16(len(taskKey)))
+	if result.IsCancelled {
+		frame.flags |= frameFlagCancelled
+	}
 
-	offset := frameHeaderSize
-	offset += copy(frame[offset:], method)
-	offset += copy(frame[offset:], flowKey)
-	offset += copy(frame[offset:], taskKey)
-	copy(frame[offset:], payload)
+	if threshold := compression.Threshold(int(rt)); threshold > 0 && len(result.Payload) >= threshold {
+		if compressed, ok := compression.Compress(int(rt), []byte(result.Payload)); ok {
+			frame.payload = string(compressed)
+			frame.flags |= frameFlagCompressed
+		}
+	}
 
 	return frame
 }
 
+func (f *resultFrame) size() int {
+	return frameHeaderSize + len(f.method) + len(f.flowKey) + len(f.taskKey) + len(f.payload)
+}
+
+// put writes the frame into dst, exactly size() bytes long.
+func (f *resultFrame) put(dst []byte) {
+	dst[0] = f.flags
+	dst[1] = byte(len(f.method))
+	binary.BigEndian.PutUint32(dst[2:6], uint32(f.executionMs))
+	binary.BigEndian.PutUint16(dst[6:8], uint16(len(f.flowKey)))
+	binary.BigEndian.PutUint16(dst[8:10], uint16(len(f.taskKey)))
+
+	offset := frameHeaderSize
+	offset += copy(dst[offset:], f.method)
+	offset += copy(dst[offset:], f.flowKey)
+	offset += copy(dst[offset:], f.taskKey)
+	copy(dst[offset:], f.payload)
+}
+
 // frameResult builds the buffer_result_t carrying a framed result.
-func frameResult(result *dto.Result) C.buffer_result_t {
-	frame := buildResultFrame(result)
+func frameResult(result *dto.Result, rt C.int) C.buffer_result_t {
+	frame := newResultFrame(result, rt)
+
+	return placeFrame(rt, frame.size(), frame.put)
+}
+
+// placeFrame lets write fill size bytes of C memory handed to the C side: a span
+// of the runtime's ring when one is free, else a C allocation of its own (see
+// package framemem).
+func placeFrame(rt C.int, size int, write func(dst []byte)) C.buffer_result_t {
+	data, release := framemem.Of(int(rt)).Place(size, write)
 
 	return C.buffer_result_t{
-		data: C.CBytes(frame),
-		len:  C.int(len(frame)),
-		err:  nil,
+		data:    data,
+		len:     C.int(size),
+		err:     nil,
+		release: (*C.uint)(unsafe.Pointer(release)),
 	}
 }
 
-var handler *handler2.Handler
+// Batch layout (waitMany, stopFlow): a count prefix, then each result as a
+// length-prefixed frame of the layout above. Must match
+// Extension::parseBatchResponse.
+//
+//	[0:4]    count    uint32 (big-endian)
+//	then per result:
+//	[0:4]    frame    length uint32 (big-endian)
+//	[4:]     frame    bytes (resultFrame)
+const (
+	batchCountSize       = 4
+	batchFrameLengthSize = 4
+)
+
+// frameBatch packs results into one buffer of count-prefixed, length-prefixed
+// result frames.
+func frameBatch(results []*dto.Result, rt C.int) C.buffer_result_t {
+	frames := make([]resultFrame, len(results))
+	size := batchCountSize
+
+	for i, result := range results {
+		frames[i] = newResultFrame(result, rt)
+		size += batchFrameLengthSize + frames[i].size()
+	}
+
+	return placeFrame(rt, size, func(batch []byte) {
+		binary.BigEndian.PutUint32(batch[0:batchCountSize], uint32(len(frames)))
+
+		offset := batchCountSize
 
-func init() {
-	handler = handler2.NewHandler()
+		for i := range frames {
+			frameSize := frames[i].size()
+
+			binary.BigEndian.PutUint32(batch[offset:offset+batchFrameLengthSize], uint32(frameSize))
+			offset += batchFrameLengthSize
+
+			frames[i].put(batch[offset : offset+frameSize])
+			offset += frameSize
+		}
+	})
+}
+
+// Push batch layout (PHP -> Go, pushMany): a count prefix, then per message a fixed
+// header followed by its bytes. All messages belong to the flow passed alongside.
+// Must match Extension::pushMany.
+//
+//	[0:4]    count      uint32 (big-endian)
+//	then per message:
+//	[0]      method     length uint8
+//	[1:3]    taskKey    length uint16 (big-endian)
+//	[3:7]    timeoutMs  uint32 (big-endian)
+//	[7:11]   payload    length uint32 (big-endian)
+//	[11]     flags      uint8, pushFlagCompressed as in push
+//	[12:]    method bytes, then taskKey bytes, then payload bytes
+//
+// The answer is one entry per message, in order: an error length uint16
+// (big-endian) followed by the error text; length 0 means the message was accepted.
+const pushHeaderSize = 12
+
+var errPushBatchTruncated = errors.New("truncated push batch")
+
+// parsePushBatch decodes a pushMany buffer into the messages of one flow.
+func parsePushBatch(flowKey string, batch []byte) ([]*dto.Message, error) {
+	if len(batch) < batchCountSize {
+		return nil, errPushBatchTruncated
+	}
+
+	count := int(binary.BigEndian.Uint32(batch[0:batchCountSize]))
+	offset := batchCountSize
+
+	// The count is untrusted: every message takes at least a header, so a count
+	// the bytes cannot hold is rejected before anything is allocated for it.
+	if count > (len(batch)-offset)/pushHeaderSize {
+		return nil, errPushBatchTruncated
+	}
+
+	msgs := make([]*dto.Message, 0, count)
+
+	for range count {
+		if len(batch)-offset < pushHeaderSize {
+			return nil, errPushBatchTruncated
+		}
+
+		header := batch[offset : offset+pushHeaderSize]
+		methodLen := int(header[0])
+		taskKeyLen := int(binary.BigEndian.Uint16(header[1:3]))
+		timeoutMs := int(binary.BigEndian.Uint32(header[3:7]))
+		payloadLen := int(binary.BigEndian.Uint32(header[7:11]))
+		flags := header[11]
+		offset += pushHeaderSize
+
+		if len(batch)-offset < methodLen+taskKeyLen+payloadLen {
+			return nil, errPushBatchTruncated
+		}
+
+		method := string(batch[offset : offset+methodLen])
+		offset += methodLen
+		taskKey := string(batch[offset : offset+taskKeyLen])
+		offset += taskKeyLen
+		payload := batch[offset : offset+payloadLen]
+		offset += payloadLen
+
+		if flags&pushFlagCompressed != 0 {
+			decompressed, err := compression.Decompress(payload)
+
+			if err != nil {
+				return nil, err
+			}
+
+			payload = decompressed
+		}
+
+		msgs = append(msgs, &dto.Message{
+			FlowKey:   flowKey,
+			Method:    types.Method(method),
+			TaskKey:   taskKey,
+			Payload:   payload,
+			IsNext:    false,
+			TimeoutMs: timeoutMs,
+		})
+	}
+
+	return msgs, nil
+}
+
+// buildPushErrors packs the per-message outcome of a pushMany. An error text is
+// cut to what its uint16 length can describe, so it never shifts the entries
+// after it.
+func buildPushErrors(failures []error) []byte {
+	answer := make([]byte, 0, 2*len(failures))
+
+	for _, err := range failures {
+		var text string
+
+		if err != nil {
+			text = err.Error()
+		}
+
+		if len(text) > math.MaxUint16 {
+			text = text[:math.MaxUint16]
+		}
+
+		answer = binary.BigEndian.AppendUint16(answer, uint16(len(text)))
+		answer = append(answer, text...)
+	}
+
+	return answer
+}
+
+// Every export working on a handler takes the runtime id of the calling PHP thread
+// as its last argument: 0, the default runtime, unless the thread created its own
+// (createRuntime, see package runtimes).
+func lookupRuntime(rt C.int) (*handler2.Handler, error) {
+	return runtimes.Get().Lookup(int(rt))
+}
+
+// failedBuffer is the answer of a buffer-returning export that could not run.
+func failedBuffer(message string) C.buffer_result_t {
+	return C.buffer_result_t{
+		data: nil,
+		len:  0,
+		err:  C.CString(message),
+	}
+}
+
+//export createRuntime
+func createRuntime() C.int {
+	return C.int(runtimes.Get().Create())
+}
+
+// destroyRuntime stops every flow of a runtime created by createRuntime; "" on
+// success, "error: ..." otherwise.
+//
+//export destroyRuntime
+func destroyRuntime(rt C.int) *C.char {
+	if err := runtimes.Get().Destroy(int(rt)); err != nil {
+		return C.CString("error: destroyRuntime: " + err.Error())
+	}
+
+	framemem.Drop(int(rt))
+	compression.Drop(int(rt))
+
+	return C.CString("")
 }
 
 //export ping
@@ -110,16 +350,34 @@ func push(
 	tkLen C.int,
 	pl unsafe.Pointer,
 	plLen C.int,
+	timeoutMs C.int,
+	flags C.int,
+	rt C.int,
 ) *C.char {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return C.CString("error: push: " + err.Error())
+	}
+
+	payload := C.GoBytes(pl, plLen)
+
+	if flags&pushFlagCompressed != 0 {
+		if payload, err = compression.Decompress(payload); err != nil {
+			return C.CString("error: push: " + err.Error())
+		}
+	}
+
 	msg := &dto.Message{
-		FlowKey: C.GoStringN(fk, fkLen),
-		Method:  types.Method(C.GoStringN(mt, mtLen)),
-		TaskKey: C.GoStringN(tk, tkLen),
-		Payload: C.GoBytes(pl, plLen),
-		IsNext:  false,
+		FlowKey:   C.GoStringN(fk, fkLen),
+		Method:    types.Method(C.GoStringN(mt, mtLen)),
+		TaskKey:   C.GoStringN(tk, tkLen),
+		Payload:   payload,
+		IsNext:    false,
+		TimeoutMs: int(timeoutMs),
 	}
 
-	err := handler.Push(msg)
+	err = handler.Push(msg)
 
 	if err != nil {
 		return C.CString("error: push: " + err.Error())
@@ -128,15 +386,46 @@ func push(
 	return C.CString("")
 }
 
+//export pushMany
+func pushMany(fk *C.char, fkLen C.int, batch unsafe.Pointer, batchLen C.int, rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: pushMany: " + err.Error())
+	}
+
+	flowKey := C.GoStringN(fk, fkLen)
+
+	msgs, err := parsePushBatch(flowKey, C.GoBytes(batch, batchLen))
+
+	if err != nil {
+		return failedBuffer("error: pushMany: " + err.Error())
+	}
+
+	answer := buildPushErrors(handler.PushMany(flowKey, msgs))
+
+	return C.buffer_result_t{
+		data: C.CBytes(answer),
+		len:  C.int(len(answer)),
+		err:  nil,
+	}
+}
+
 //export next
-func next(fk *C.char, tk *C.char) *C.char {
+func next(fk *C.char, tk *C.char, rt C.int) *C.char {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return C.CString("error: next: " + err.Error())
+	}
+
 	msg := &dto.Message{
 		FlowKey: C.GoString(fk),
 		TaskKey: C.GoString(tk),
 		IsNext:  true,
 	}
 
-	err := handler.Push(msg)
+	err = handler.Push(msg)
 
 	if err != nil {
 		return C.CString("error: next: " + err.Error())
@@ -146,64 +435,219 @@ func next(fk *C.char, tk *C.char) *C.char {
 }
 
 //export wait
-func wait(fk *C.char, fkLen C.int) C.buffer_result_t {
+func wait(fk *C.char, fkLen C.int, rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: wait: " + err.Error())
+	}
+
 	res, err := handler.Wait(C.GoStringN(fk, fkLen))
 
 	if err != nil {
-		return C.buffer_result_t{
-			data: nil,
-			len:  0,
-			err:  C.CString("error: " + err.Error()),
-		}
+		return failedBuffer("error: " + err.Error())
 	}
 
-	return frameResult(res)
+	return frameResult(res, rt)
 }
 
 //export waitAny
-func waitAny() C.buffer_result_t {
+func waitAny(rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: waitAny: " + err.Error())
+	}
+
 	res, err := handler.WaitAny()
 
 	if err != nil {
-		return C.buffer_result_t{
-			data: nil,
-			len:  0,
-			err:  C.CString("error: " + err.Error()),
-		}
+		return failedBuffer("error: " + err.Error())
 	}
 
-	return frameResult(res)
+	return frameResult(res, rt)
 }
 
 //export waitAnyTimeout
-func waitAnyTimeout(ms C.int) C.buffer_result_t {
+func waitAnyTimeout(ms C.int, rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: waitAnyTimeout: " + err.Error())
+	}
+
 	res, err := handler.WaitAnyTimeout(int(ms))
 
 	if err != nil {
 		// A timeout is not an error: signal it with a distinct, non-"error:"
 		// sentinel the PHP side maps to "no result yet".
 		if errors.Is(err, handler2.ErrWaitTimeout) {
-			return C.buffer_result_t{data: nil, len: 0, err: C.CString("timeout")}
+			return failedBuffer("timeout")
 		}
 
-		return C.buffer_result_t{
-			data: nil,
-			len:  0,
-			err:  C.CString("error: " + err.Error()),
+		return failedBuffer("error: " + err.Error())
+	}
+
+	return frameResult(res, rt)
+}
+
+//export waitMany
+func waitMany(limit C.int, timeoutMs C.int, rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: waitMany: " + err.Error())
+	}
+
+	results, err := handler.WaitMany(max(int(limit), 1), int(timeoutMs))
+
+	if err != nil {
+		if errors.Is(err, handler2.ErrWaitTimeout) {
+			return failedBuffer("timeout")
 		}
+
+		return failedBuffer("error: " + err.Error())
 	}
 
-	return frameResult(res)
+	return frameBatch(results, rt)
+}
+
+// readinessFd returns a descriptor readable while a result of the runtime is
+// deliverable, so PHP can watch it in its own event loop and call waitMany(max, -1)
+// only then; -1 when the runtime is unknown or the descriptor cannot be created.
+//
+//export readinessFd
+func readinessFd(rt C.int) C.int {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return -1
+	}
+
+	fd, err := handler.ReadinessFd()
+
+	if err != nil {
+		return -1
+	}
+
+	return C.int(fd)
+}
+
+//export inspect
+func inspect(rt C.int) *C.char {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return C.CString("error: inspect: " + err.Error())
+	}
+
+	encoded, err := json.Marshal(handler.Inspect(int(rt)))
+
+	if err != nil {
+		return C.CString("error: inspect: " + err.Error())
+	}
+
+	return C.CString(string(encoded))
+}
+
+// startRecording records the handler traffic into path for cmd/flow-replay; "" on
+// success, "error: ..." otherwise.
+//
+//export startRecording
+func startRecording(path *C.char, rt C.int) *C.char {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return C.CString("error: startRecording: " + err.Error())
+	}
+
+	if err = handler.StartRecording(C.GoString(path)); err != nil {
+		return C.CString("error: startRecording: " + err.Error())
+	}
+
+	return C.CString("")
+}
+
+//export stopRecording
+func stopRecording(rt C.int) *C.char {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return C.CString("error: stopRecording: " + err.Error())
+	}
+
+	if err = handler.StopRecording(); err != nil {
+		return C.CString("error: stopRecording: " + err.Error())
+	}
+
+	return C.CString("")
+}
+
+// setFaults replaces the fault-injection rules with the JSON array config ("" or
+// "[]" disables them); "" on success, "error: ..." otherwise.
+//
+//export setFaults
+func setFaults(config *C.char) *C.char {
+	if err := faults.Load(C.GoString(config)); err != nil {
+		return C.CString("error: setFaults: " + err.Error())
+	}
+
+	return C.CString("")
+}
+
+// setCompression zstd-compresses the result payloads of a runtime of at least
+// thresholdBytes bytes (flagged frameFlagCompressed); 0 disables it. Per runtime,
+// like the Extension instance that mirrors it for its pushes.
+//
+//export setCompression
+func setCompression(thresholdBytes C.int, rt C.int) {
+	compression.SetThreshold(int(rt), int(thresholdBytes))
+}
+
+//export setStateIdleTtl
+func setStateIdleTtl(ms C.int) {
+	states.Get().SetIdleTTL(time.Duration(ms) * time.Millisecond)
 }
 
 //export tasksCount
-func tasksCount() int {
+func tasksCount(rt C.int) int {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return 0
+	}
+
 	return handler.GetTasksCount()
 }
 
+// stopFlow stops a flow with the reason its running tasks report in their
+// cancelled results (see errs.StopCause); an empty code stands for flow_stopped.
+// Those results are returned as a batch (the waitMany layout), since the flow is
+// gone; no buffer at all (an empty string on the PHP side) when there is none,
+// and "error: ..." for an unknown runtime.
+//
 //export stopFlow
-func stopFlow(fk *C.char) {
-	handler.StopFlow(C.GoString(fk))
+func stopFlow(fk *C.char, rc *C.char, rcLen C.int, rm *C.char, rmLen C.int, rt C.int) C.buffer_result_t {
+	handler, err := lookupRuntime(rt)
+
+	if err != nil {
+		return failedBuffer("error: stopFlow: " + err.Error())
+	}
+
+	stopped := handler.StopFlow(C.GoString(fk), errs.NewStopCause(C.GoStringN(rc, rcLen), C.GoStringN(rm, rmLen)))
+
+	if len(stopped) == 0 {
+		return C.buffer_result_t{}
+	}
+
+	return frameBatch(stopped, rt)
+}
+
+//export cancelTask
+func cancelTask(fk *C.char, tk *C.char, rt C.int) {
+	if handler, err := lookupRuntime(rt); err == nil {
+		handler.CancelTask(C.GoString(fk), C.GoString(tk))
+	}
 }
 
 //export httpStopAccepting
@@ -226,12 +670,12 @@ func destroy() {
 	// Flush any buffered log lines before tearing the runtime down.
 	logger.Flush()
 
-	handler.Destroy()
+	runtimes.Get().DestroyAll()
 }
 
 //export version
 func version() *C.char {
-	return C.CString("0.7.2")
+	return C.CString("0.10.0")
 }
 
 func main() {}
@@ -5,13 +5,25 @@
 /*
  * arginfo:
  *  - ping(string name)
- *  - push(string flowKey, string method, string taskKey, string payload)
- *  - next(string flowKey, string taskKey)
- *  - wait(string flowKey)
- *  - waitAny()
- *  - waitAnyTimeout(int timeoutMs)
- *  - tasksCount()
- *  - stopFlow(string flowKey)
+ *  - createRuntime()
+ *  - destroyRuntime(int runtime)
+ *  - push(string flowKey, string method, string taskKey, string payload, int timeoutMs = 0, int flags = 0, int runtime = 0)
+ *  - pushMany(string flowKey, string batch, int runtime = 0)
+ *  - next(string flowKey, string taskKey, int runtime = 0)
+ *  - wait(string flowKey, int runtime = 0)
+ *  - waitAny(int runtime = 0)
+ *  - waitAnyTimeout(int timeoutMs, int runtime = 0)
+ *  - waitMany(int max, int timeoutMs, int runtime = 0)
+ *  - readinessFd(int runtime = 0)
+ *  - inspect(int runtime = 0)
+ *  - startRecording(string path, int runtime = 0)
+ *  - stopRecording(int runtime = 0)
+ *  - setFaults(string config)
+ *  - setCompression(int thresholdBytes, int runtime = 0)
+ *  - setStateIdleTtl(int ms)
+ *  - tasksCount(int runtime = 0)
+ *  - stopFlow(string flowKey, string reasonCode = '', string reasonMessage = '', int runtime = 0)
+ *  - cancelTask(string flowKey, string taskKey, int runtime = 0)
  *  - httpStopAccepting(string flowKey)
  *  - socketStopAccepting(string flowKey)
  *  - wsStopAccepting(string flowKey)
@@ -24,41 +36,119 @@ ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_ping, 0, 0, 1)
     ZEND_ARG_TYPE_INFO(0, name, IS_STRING, 0)
 ZEND_END_ARG_INFO()
 
-// push(string flowKey, string method, string taskKey, string payload)
+// createRuntime()
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_createRuntime, 0, 0, 0)
+ZEND_END_ARG_INFO()
+
+// destroyRuntime(int runtime)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_destroyRuntime, 0, 0, 1)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// push(string flowKey, string method, string taskKey, string payload, int timeoutMs = 0, int flags = 0, int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_push, 0, 0, 4)
     ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
     ZEND_ARG_TYPE_INFO(0, method, IS_STRING, 0)
     ZEND_ARG_TYPE_INFO(0, taskKey, IS_STRING, 0)
     ZEND_ARG_TYPE_INFO(0, payload, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, timeoutMs, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, flags, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// pushMany(string flowKey, string batch, int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_pushMany, 0, 0, 2)
+    ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, batch, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// next(string flowKey, string taskKey)
+// next(string flowKey, string taskKey, int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_next, 0, 0, 2)
     ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
     ZEND_ARG_TYPE_INFO(0, taskKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// wait(string flowKey)
+// wait(string flowKey, int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_wait, 0, 0, 1)
     ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// waitAny()
+// waitAny(int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_waitAny, 0, 0, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// waitAnyTimeout(int timeoutMs)
+// waitAnyTimeout(int timeoutMs, int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_waitAnyTimeout, 0, 0, 1)
     ZEND_ARG_TYPE_INFO(0, timeoutMs, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// waitMany(int max, int timeoutMs, int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_waitMany, 0, 0, 2)
+    ZEND_ARG_TYPE_INFO(0, max, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, timeoutMs, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// readinessFd(int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_readinessFd, 0, 0, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// inspect(int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_inspect, 0, 0, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// startRecording(string path, int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_startRecording, 0, 0, 1)
+    ZEND_ARG_TYPE_INFO(0, path, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// stopRecording(int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_stopRecording, 0, 0, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// setFaults(string config)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_setFaults, 0, 0, 1)
+    ZEND_ARG_TYPE_INFO(0, config, IS_STRING, 0)
+ZEND_END_ARG_INFO()
+
+// setCompression(int thresholdBytes, int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_setCompression, 0, 0, 1)
+    ZEND_ARG_TYPE_INFO(0, thresholdBytes, IS_LONG, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// tasksCount()
+// setStateIdleTtl(int ms)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_setStateIdleTtl, 0, 0, 1)
+    ZEND_ARG_TYPE_INFO(0, ms, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// tasksCount(int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_tasksCount, 0, 0, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
-// stopFlow(string flowKey)
+// stopFlow(string flowKey, string reasonCode = '', string reasonMessage = '', int runtime = 0)
 ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_stopFlow, 0, 0, 1)
     ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, reasonCode, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, reasonMessage, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
+ZEND_END_ARG_INFO()
+
+// cancelTask(string flowKey, string taskKey, int runtime = 0)
+ZEND_BEGIN_ARG_INFO_EX(arginfo_sconcur_cancelTask, 0, 0, 2)
+    ZEND_ARG_TYPE_INFO(0, flowKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, taskKey, IS_STRING, 0)
+    ZEND_ARG_TYPE_INFO(0, runtime, IS_LONG, 0)
 ZEND_END_ARG_INFO()
 
 // httpStopAccepting(string flowKey)
@@ -88,6 +178,20 @@ ZEND_END_ARG_INFO()
  * Реализации PHP-функций
  */
 
+// release_frame_buffer gives back the buffer of a result frame once its bytes are
+// copied into the PHP string: a span of the runtime's result arena is released
+// through its release word, which Go reads when it next reserves a span; a frame
+// that did not fit there is freed.
+static void release_frame_buffer(buffer_result_t *response)
+{
+    if (response->release != NULL) {
+        __atomic_store_n(response->release, 1, __ATOMIC_RELEASE);
+        return;
+    }
+
+    free(response->data);
+}
+
 // PHP: SConcur\Extension\ping(string $name): string
 PHP_FUNCTION(ping)
 {
@@ -105,13 +209,47 @@ PHP_FUNCTION(ping)
     free(response);
 }
 
-// PHP: SConcur\Extension\push(string $flowKey, string $method, string $taskKey, string $payload): string
+// PHP: SConcur\Extension\createRuntime(): int
+// A runtime of its own for the calling PHP thread (ZTS); see runtimes.Registry.
+PHP_FUNCTION(createRuntime)
+{
+    if (zend_parse_parameters_none() == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    RETURN_LONG(createRuntime());
+}
+
+// PHP: SConcur\Extension\destroyRuntime(int $runtime): string
+// "" on success, "error: ..." otherwise.
+PHP_FUNCTION(destroyRuntime)
+{
+    zend_long runtime;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "l", &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    char *response = destroyRuntime((int)runtime);
+
+    RETVAL_STRING(response);
+    free(response);
+}
+
+// PHP: SConcur\Extension\push(string $flowKey, string $method, string $taskKey, string $payload, int $timeoutMs = 0, int $flags = 0, int $runtime = 0): string
+// $timeoutMs is the optional task deadline (0 = none); an expired task is answered
+// with a uniform timeout error result. $flags bit 0 marks a zstd-compressed
+// payload. $runtime selects the handler (0 = default),
+// as for every function below taking it.
 PHP_FUNCTION(push)
 {
     char *flow_key = NULL, *method = NULL, *task_key = NULL, *payload = NULL;
     size_t flow_key_len, method_len, task_key_len, payload_len;
+    zend_long timeout_ms = 0;
+    zend_long flags = 0;
+    zend_long runtime = 0;
 
-    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ssss", &flow_key, &flow_key_len, &method, &method_len, &task_key, &task_key_len, &payload, &payload_len) == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ssss|lll", &flow_key, &flow_key_len, &method, &method_len, &task_key, &task_key_len, &payload, &payload_len, &timeout_ms, &flags, &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
@@ -123,43 +261,75 @@ PHP_FUNCTION(push)
         task_key,
         (int)task_key_len,
         payload,
-        (int)payload_len
+        (int)payload_len,
+        (int)timeout_ms,
+        (int)flags,
+        (int)runtime
     );
 
     RETVAL_STRING(response);
     free(response);
 }
 
-// PHP: SConcur\Extension\next(string $flowKey, string $taskKey): string
+// PHP: SConcur\Extension\pushMany(string $flowKey, string $batch, int $runtime = 0): string
+// Returns the per-message outcomes (see main.go buildPushErrors), or an "error:"
+// string when the batch itself is malformed.
+PHP_FUNCTION(pushMany)
+{
+    char *flow_key = NULL, *batch = NULL;
+    size_t flow_key_len, batch_len;
+    zend_long runtime = 0;
+    buffer_result_t response;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ss|l", &flow_key, &flow_key_len, &batch, &batch_len, &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    response = pushMany(flow_key, (int)flow_key_len, batch, (int)batch_len, (int)runtime);
+
+    if (response.err != NULL) {
+        RETVAL_STRING(response.err);
+        free(response.err);
+        return;
+    }
+
+    RETVAL_STRINGL((char *)response.data, response.len);
+    free(response.data);
+}
+
+// PHP: SConcur\Extension\next(string $flowKey, string $taskKey, int $runtime = 0): string
 PHP_FUNCTION(next)
 {
     char *flow_key = NULL, *task_key = NULL;
     size_t flow_key_len, task_key_len;
+    zend_long runtime = 0;
 
-    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ss", &flow_key, &flow_key_len, &task_key, &task_key_len) == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ss|l", &flow_key, &flow_key_len, &task_key, &task_key_len, &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
-    char *response = next(flow_key, task_key);
+    char *response = next(flow_key, task_key, (int)runtime);
 
     RETVAL_STRING(response);
     free(response);
 }
 
-// PHP: SConcur\Extension\wait(string $flowKey): string
+// PHP: SConcur\Extension\wait(string $flowKey, int $runtime = 0): string
 PHP_FUNCTION(wait)
 {
     char *flow_key = NULL;
     size_t flow_key_len;
+    zend_long runtime = 0;
     buffer_result_t response;
 
-    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s", &flow_key, &flow_key_len) == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s|l", &flow_key, &flow_key_len, &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
     response = wait(
         flow_key,
-        (int)flow_key_len
+        (int)flow_key_len,
+        (int)runtime
     );
 
     if (response.err != NULL) {
@@ -169,19 +339,20 @@ PHP_FUNCTION(wait)
     }
 
     RETVAL_STRINGL((char *)response.data, response.len);
-    free(response.data);
+    release_frame_buffer(&response);
 }
 
-// PHP: SConcur\Extension\waitAny(): string
+// PHP: SConcur\Extension\waitAny(int $runtime = 0): string
 PHP_FUNCTION(waitAny)
 {
+    zend_long runtime = 0;
     buffer_result_t response;
 
-    if (zend_parse_parameters_none() == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "|l", &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
-    response = waitAny();
+    response = waitAny((int)runtime);
 
     if (response.err != NULL) {
         RETVAL_STRING(response.err);
@@ -190,21 +361,22 @@ PHP_FUNCTION(waitAny)
     }
 
     RETVAL_STRINGL((char *)response.data, response.len);
-    free(response.data);
+    release_frame_buffer(&response);
 }
 
-// PHP: SConcur\Extension\waitAnyTimeout(int $timeoutMs): string
+// PHP: SConcur\Extension\waitAnyTimeout(int $timeoutMs, int $runtime = 0): string
 // Returns the literal "timeout" when no result became ready in time.
 PHP_FUNCTION(waitAnyTimeout)
 {
     zend_long timeout_ms;
+    zend_long runtime = 0;
     buffer_result_t response;
 
-    if (zend_parse_parameters(ZEND_NUM_ARGS(), "l", &timeout_ms) == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "l|l", &timeout_ms, &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
-    response = waitAnyTimeout((int)timeout_ms);
+    response = waitAnyTimeout((int)timeout_ms, (int)runtime);
 
     if (response.err != NULL) {
         RETVAL_STRING(response.err);
@@ -213,31 +385,196 @@ PHP_FUNCTION(waitAnyTimeout)
     }
 
     RETVAL_STRINGL((char *)response.data, response.len);
-    free(response.data);
+    release_frame_buffer(&response);
+}
+
+// PHP: SConcur\Extension\waitMany(int $max, int $timeoutMs, int $runtime = 0): string
+// Returns a batch of up to $max ready results (count-prefixed frames), or the
+// literal "timeout" when none became ready in time ($timeoutMs > 0).
+PHP_FUNCTION(waitMany)
+{
+    zend_long max;
+    zend_long timeout_ms;
+    zend_long runtime = 0;
+    buffer_result_t response;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ll|l", &max, &timeout_ms, &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    response = waitMany((int)max, (int)timeout_ms, (int)runtime);
+
+    if (response.err != NULL) {
+        RETVAL_STRING(response.err);
+        free(response.err);
+        return;
+    }
+
+    RETVAL_STRINGL((char *)response.data, response.len);
+    release_frame_buffer(&response);
+}
+
+// PHP: SConcur\Extension\readinessFd(int $runtime = 0): int
+// -1 when the runtime is unknown or the descriptor cannot be created.
+PHP_FUNCTION(readinessFd)
+{
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "|l", &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    RETURN_LONG(readinessFd((int)runtime));
 }
 
-// PHP: SConcur\Extension\tasksCount(): int
+// PHP: SConcur\Extension\inspect(int $runtime = 0): string
+// Returns a JSON dump of the runtime state (see handler.Inspection).
+PHP_FUNCTION(inspect)
+{
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "|l", &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    char *response = inspect((int)runtime);
+
+    RETVAL_STRING(response);
+    free(response);
+}
+
+// PHP: SConcur\Extension\startRecording(string $path, int $runtime = 0): string
+// "" on success, "error: ..." otherwise.
+PHP_FUNCTION(startRecording)
+{
+    char *path = NULL;
+    size_t path_len;
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s|l", &path, &path_len, &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    char *response = startRecording(path, (int)runtime);
+
+    RETVAL_STRING(response);
+    free(response);
+}
+
+// PHP: SConcur\Extension\stopRecording(int $runtime = 0): string
+PHP_FUNCTION(stopRecording)
+{
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "|l", &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    char *response = stopRecording((int)runtime);
+
+    RETVAL_STRING(response);
+    free(response);
+}
+
+// PHP: SConcur\Extension\setFaults(string $config): string
+// "" on success, "error: ..." otherwise.
+PHP_FUNCTION(setFaults)
+{
+    char *config = NULL;
+    size_t config_len;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s", &config, &config_len) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    char *response = setFaults(config);
+
+    RETVAL_STRING(response);
+    free(response);
+}
+
+// PHP: SConcur\Extension\setCompression(int $thresholdBytes, int $runtime = 0): void
+// 0 disables compression for the runtime.
+PHP_FUNCTION(setCompression)
+{
+    zend_long threshold_bytes;
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "l|l", &threshold_bytes, &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    setCompression((int)threshold_bytes, (int)runtime);
+}
+
+// PHP: SConcur\Extension\setStateIdleTtl(int $ms): void
+// 0 disables the idle reaper.
+PHP_FUNCTION(setStateIdleTtl)
+{
+    zend_long ms;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "l", &ms) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    setStateIdleTtl((int)ms);
+}
+
+// PHP: SConcur\Extension\tasksCount(int $runtime = 0): int
 PHP_FUNCTION(tasksCount)
 {
-    if (zend_parse_parameters_none() == FAILURE) {
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "|l", &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
-    int result = tasksCount();
+    int result = tasksCount((int)runtime);
     RETURN_LONG(result);
 }
 
-// PHP: SConcur\Extension\stopFlow(string $flowKey): void
+// PHP: SConcur\Extension\stopFlow(string $flowKey, string $reasonCode = '', string $reasonMessage = '', int $runtime = 0): string
+// The cancelled results of the tasks the stop answered, as a waitMany batch; an
+// empty string when there are none, "error: ..." for an unknown runtime.
 PHP_FUNCTION(stopFlow)
 {
-    char *flow_key = NULL;
-    size_t flow_key_len;
+    char *flow_key = NULL, *reason_code = "", *reason_message = "";
+    size_t flow_key_len, reason_code_len = 0, reason_message_len = 0;
+    zend_long runtime = 0;
+    buffer_result_t response;
 
-    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s", &flow_key, &flow_key_len) == FAILURE) {
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s|ssl", &flow_key, &flow_key_len, &reason_code, &reason_code_len, &reason_message, &reason_message_len, &runtime) == FAILURE) {
+        RETURN_THROWS();
+    }
+
+    response = stopFlow(flow_key, reason_code, (int)reason_code_len, reason_message, (int)reason_message_len, (int)runtime);
+
+    if (response.err != NULL) {
+        RETVAL_STRING(response.err);
+        free(response.err);
+        return;
+    }
+
+    if (response.data == NULL) {
+        RETURN_EMPTY_STRING();
+    }
+
+    RETVAL_STRINGL((char *)response.data, response.len);
+    release_frame_buffer(&response);
+}
+
+// PHP: SConcur\Extension\cancelTask(string $flowKey, string $taskKey, int $runtime = 0): void
+PHP_FUNCTION(cancelTask)
+{
+    char *flow_key = NULL, *task_key = NULL;
+    size_t flow_key_len, task_key_len;
+    zend_long runtime = 0;
+
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "ss|l", &flow_key, &flow_key_len, &task_key, &task_key_len, &runtime) == FAILURE) {
         RETURN_THROWS();
     }
 
-    stopFlow(flow_key);
+    cancelTask(flow_key, task_key, (int)runtime);
     RETURN_NULL();
 }
 
@@ -312,13 +649,25 @@ PHP_FUNCTION(version)
  */
 static const zend_function_entry sconcur_functions[] = {
     ZEND_NS_FE("SConcur\\Extension", ping, arginfo_sconcur_ping)
+    ZEND_NS_FE("SConcur\\Extension", createRuntime, arginfo_sconcur_createRuntime)
+    ZEND_NS_FE("SConcur\\Extension", destroyRuntime, arginfo_sconcur_destroyRuntime)
     ZEND_NS_FE("SConcur\\Extension", push, arginfo_sconcur_push)
+    ZEND_NS_FE("SConcur\\Extension", pushMany, arginfo_sconcur_pushMany)
     ZEND_NS_FE("SConcur\\Extension", next, arginfo_sconcur_next)
     ZEND_NS_FE("SConcur\\Extension", wait, arginfo_sconcur_wait)
     ZEND_NS_FE("SConcur\\Extension", waitAny, arginfo_sconcur_waitAny)
     ZEND_NS_FE("SConcur\\Extension", waitAnyTimeout, arginfo_sconcur_waitAnyTimeout)
+    ZEND_NS_FE("SConcur\\Extension", waitMany, arginfo_sconcur_waitMany)
+    ZEND_NS_FE("SConcur\\Extension", readinessFd, arginfo_sconcur_readinessFd)
+    ZEND_NS_FE("SConcur\\Extension", inspect, arginfo_sconcur_inspect)
+    ZEND_NS_FE("SConcur\\Extension", startRecording, arginfo_sconcur_startRecording)
+    ZEND_NS_FE("SConcur\\Extension", stopRecording, arginfo_sconcur_stopRecording)
+    ZEND_NS_FE("SConcur\\Extension", setFaults, arginfo_sconcur_setFaults)
+    ZEND_NS_FE("SConcur\\Extension", setCompression, arginfo_sconcur_setCompression)
+    ZEND_NS_FE("SConcur\\Extension", setStateIdleTtl, arginfo_sconcur_setStateIdleTtl)
     ZEND_NS_FE("SConcur\\Extension", tasksCount, arginfo_sconcur_tasksCount)
     ZEND_NS_FE("SConcur\\Extension", stopFlow, arginfo_sconcur_stopFlow)
+    ZEND_NS_FE("SConcur\\Extension", cancelTask, arginfo_sconcur_cancelTask)
     ZEND_NS_FE("SConcur\\Extension", httpStopAccepting, arginfo_sconcur_httpStopAccepting)
     ZEND_NS_FE("SConcur\\Extension", socketStopAccepting, arginfo_sconcur_socketStopAccepting)
     ZEND_NS_FE("SConcur\\Extension", wsStopAccepting, arginfo_sconcur_wsStopAccepting)
@@ -6,31 +6,79 @@ function ping(string $str): string
 {
 }
 
-function push(string $fk, string $mt, string $tk, string $pl): string
+function createRuntime(): int
 {
 }
 
-function next(string $fk, string $tk): string
+function destroyRuntime(int $runtime): string
 {
 }
 
-function wait(string $fk): string
+function push(string $fk, string $mt, string $tk, string $pl, int $timeoutMs = 0, int $flags = 0, int $runtime = 0): string
 {
 }
 
-function waitAny(): string
+function pushMany(string $fk, string $batch, int $runtime = 0): string
 {
 }
 
-function waitAnyTimeout(int $timeoutMs): string
+function next(string $fk, string $tk, int $runtime = 0): string
 {
 }
 
-function tasksCount(): int
+function wait(string $fk, int $runtime = 0): string
 {
 }
 
-function stopFlow(string $fk): void
+function waitAny(int $runtime = 0): string
+{
+}
+
+function waitAnyTimeout(int $timeoutMs, int $runtime = 0): string
+{
+}
+
+function waitMany(int $max, int $timeoutMs, int $runtime = 0): string
+{
+}
+
+function readinessFd(int $runtime = 0): int
+{
+}
+
+function inspect(int $runtime = 0): string
+{
+}
+
+function startRecording(string $path, int $runtime = 0): string
+{
+}
+
+function stopRecording(int $runtime = 0): string
+{
+}
+
+function setFaults(string $config): string
+{
+}
+
+function setCompression(int $thresholdBytes, int $runtime = 0): void
+{
+}
+
+function setStateIdleTtl(int $ms): void
+{
+}
+
+function tasksCount(int $runtime = 0): int
+{
+}
+
+function stopFlow(string $fk, string $reasonCode = '', string $reasonMessage = '', int $runtime = 0): string
+{
+}
+
+function cancelTask(string $fk, string $tk, int $runtime = 0): void
 {
 }
 
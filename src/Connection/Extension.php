@@ -4,7 +4,9 @@ declare(strict_types=1);
 
 namespace SConcur\Connection;
 
+use SConcur\Connection\Faults\FaultRule;
 use SConcur\Dto\RunningTaskDto;
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Dto\TaskResultDto;
 use SConcur\Exceptions\ExtensionCallException;
 use SConcur\Exceptions\ExtensionNotLoadedException;
@@ -15,17 +17,29 @@ use SConcur\Features\MethodEnum;
 use SConcur\Transport\MessagePackTransport;
 use SConcur\Transport\PayloadInterface;
 use Throwable;
+use function SConcur\Extension\cancelTask;
+use function SConcur\Extension\createRuntime;
 use function SConcur\Extension\destroy;
+use function SConcur\Extension\destroyRuntime;
 use function SConcur\Extension\httpStopAccepting;
+use function SConcur\Extension\inspect;
 use function SConcur\Extension\next;
 use function SConcur\Extension\push;
+use function SConcur\Extension\pushMany;
+use function SConcur\Extension\readinessFd;
+use function SConcur\Extension\setCompression;
+use function SConcur\Extension\setFaults;
+use function SConcur\Extension\setStateIdleTtl;
 use function SConcur\Extension\socketStopAccepting;
+use function SConcur\Extension\startRecording;
 use function SConcur\Extension\stopFlow;
+use function SConcur\Extension\stopRecording;
 use function SConcur\Extension\tasksCount;
 use function SConcur\Extension\version;
 use function SConcur\Extension\wait;
 use function SConcur\Extension\waitAny;
 use function SConcur\Extension\waitAnyTimeout;
+use function SConcur\Extension\waitMany;
 use function SConcur\Extension\wsStopAccepting;
 
 class Extension
@@ -38,27 +52,77 @@ class Extension
      * rejected instead of silently misbehaving. Public so tooling (bin/sconcur-status)
      * can report the version the package expects.
      */
-    public const string REQUIRED_EXTENSION_VERSION = '0.7.2';
+    public const string REQUIRED_EXTENSION_VERSION = '0.10.0';
 
     /**
-     * Result frame layout (Go -> PHP), see main.go buildResultFrame. The envelope is
+     * Result frame layout (Go -> PHP), see main.go resultFrame. The envelope is
      * a fixed binary header, not MessagePack; only the feature payload stays
      * MessagePack and is decoded once by the feature. Header: flags(1) +
      * methodLen(1) + execMs(uint32) + flowKeyLen(uint16) + taskKeyLen(uint16), then
      * method, flowKey, taskKey and the raw payload (the rest).
      */
-    private const int FRAME_HEADER_SIZE   = 10;
-    private const int FRAME_FLAG_ERROR    = 1 << 0;
-    private const int FRAME_FLAG_HAS_NEXT = 1 << 1;
+    private const int FRAME_HEADER_SIZE    = 10;
+    private const int FRAME_FLAG_ERROR     = 1 << 0;
+    private const int FRAME_FLAG_HAS_NEXT  = 1 << 1;
+    private const int FRAME_FLAG_CANCELLED = 1 << 2;
+
+    /**
+     * Compression (see setCompression): a result frame flagged FRAME_FLAG_COMPRESSED
+     * carries a zstd-compressed payload, and a push flagged PUSH_FLAG_COMPRESSED
+     * sends one (main.go pushFlagCompressed).
+     */
+    private const int FRAME_FLAG_COMPRESSED = 1 << 3;
+    private const int PUSH_FLAG_COMPRESSED  = 1 << 0;
+
+    /**
+     * Batch layout (waitMany, stopFlow), see main.go frameBatch: count(uint32), then per
+     * result a frameLen(uint32) followed by a result frame of the layout above.
+     */
+    private const int BATCH_COUNT_SIZE        = 4;
+    private const int BATCH_FRAME_LENGTH_SIZE = 4;
+
+    /**
+     * Push batch layout (pushMany), see main.go parsePushBatch: count(uint32), then
+     * per message methodLen(uint8) + taskKeyLen(uint16) + timeoutMs(uint32) +
+     * payloadLen(uint32) + flags(uint8, PUSH_FLAG_COMPRESSED), then method, taskKey
+     * and payload. The answer is one
+     * errorLen(uint16) + error per message; an empty error means accepted.
+     */
+    private const int PUSH_ERROR_LENGTH_SIZE = 2;
 
     protected static ?Extension $instance = null;
 
     protected static bool $checked     = false;
     protected static int $tasksCounter = 0;
 
+    /**
+     * The readiness descriptor opened as a stream, see readinessStream().
+     *
+     * @var resource|null
+     */
+    protected $readinessStream = null;
+
+    /**
+     * The Go-side handler runtime this instance talks to. A non-thread-safe PHP has
+     * one request thread and uses the default runtime (0). Under ZTS every thread
+     * gets its own Extension (statics are per thread) and creates its own runtime,
+     * so its flows, results and task counts never mix with another thread's. See
+     * docs/runtimes.md.
+     */
+    private int $runtime;
+
+    /**
+     * Payloads of at least this many bytes are compressed on push and pushMany;
+     * 0 = never. Mirrors the threshold of this instance's runtime on the Go side,
+     * which compresses the results.
+     */
+    private int $compressionThreshold = 0;
+
     private function __construct()
     {
         $this->checkExtension();
+
+        $this->runtime = PHP_ZTS ? createRuntime() : 0;
     }
 
     public static function get(): Extension
@@ -66,13 +130,26 @@ class Extension
         return static::$instance ??= new Extension();
     }
 
-    public function push(string $flowKey, PayloadInterface $payload): RunningTaskDto
+    /**
+     * $timeoutMs is an optional deadline for the task (0 = none), enforced on the Go
+     * side for every feature alike: an expired task resolves with a timeout error,
+     * and a cursor or response stream it opens stays bounded by the same deadline.
+     */
+    public function push(string $flowKey, PayloadInterface $payload, int $timeoutMs = 0): RunningTaskDto
     {
-        ++static::$tasksCounter;
-
-        $taskKey = $flowKey . ':' . static::$tasksCounter;
-
-        $response = push($flowKey, $payload->getMethod()->value, $taskKey, MessagePackTransport::pack($payload));
+        $taskKey = $this->makeTaskKey($flowKey);
+
+        [$packed, $flags] = $this->packPayload($payload);
+
+        $response = push(
+            $flowKey,
+            $payload->getMethod()->value,
+            $taskKey,
+            $packed,
+            $timeoutMs,
+            $flags,
+            $this->runtime,
+        );
 
         static::checkCallResponse(flowKey: $flowKey, response: $response);
 
@@ -81,9 +158,78 @@ class Extension
         );
     }
 
+    /**
+     * Submits many tasks of one flow in a single extension call (one flow lock on
+     * the Go side). Returns one entry per payload, in order: the running task, or
+     * the ExtensionCallException that rejected that payload — a rejected payload
+     * does not stop the rest. $timeoutMs applies to every task, as in push().
+     *
+     * @param list<PayloadInterface> $payloads
+     *
+     * @return list<RunningTaskDto|ExtensionCallException>
+     */
+    public function pushMany(string $flowKey, array $payloads, int $timeoutMs = 0): array
+    {
+        $taskKeys = [];
+        $batch    = pack('N', count($payloads));
+
+        foreach ($payloads as $payload) {
+            $taskKey = $this->makeTaskKey($flowKey);
+            $method  = $payload->getMethod()->value;
+
+            [$packed, $flags] = $this->packPayload($payload);
+
+            $taskKeys[] = $taskKey;
+
+            $batch .= pack('CnNNC', strlen($method), strlen($taskKey), $timeoutMs, strlen($packed), $flags)
+                . $method
+                . $taskKey
+                . $packed;
+        }
+
+        $response = pushMany($flowKey, $batch, $this->runtime);
+
+        static::checkCallResponse(flowKey: $flowKey, response: $response);
+
+        $results = [];
+        $offset  = 0;
+
+        foreach ($taskKeys as $taskKey) {
+            $header = unpack('nerrorLen', $response, $offset);
+
+            if ($header === false) {
+                throw new UnexpectedResponseFormatException(
+                    message: 'Could not unpack push batch answer.',
+                );
+            }
+
+            $offset += self::PUSH_ERROR_LENGTH_SIZE;
+
+            if ($header['errorLen'] === 0) {
+                $results[] = new RunningTaskDto(
+                    key: $taskKey,
+                );
+
+                continue;
+            }
+
+            $results[] = new ExtensionCallException(
+                message: sprintf(
+                    'flow %s: error: push: %s',
+                    $flowKey,
+                    substr($response, $offset, $header['errorLen']),
+                ),
+            );
+
+            $offset += $header['errorLen'];
+        }
+
+        return $results;
+    }
+
     public function next(string $flowKey, string $taskKey): RunningTaskDto
     {
-        $response = next($flowKey, $taskKey);
+        $response = next($flowKey, $taskKey, $this->runtime);
 
         static::checkCallResponse(flowKey: $flowKey, response: $response);
 
@@ -96,7 +242,7 @@ class Extension
     {
         $start = microtime(true);
 
-        $response = wait($flowKey);
+        $response = wait($flowKey, $this->runtime);
 
         return static::parseWaitResponse(
             response: $response,
@@ -114,7 +260,7 @@ class Extension
     {
         $start = microtime(true);
 
-        $response = waitAny();
+        $response = waitAny($this->runtime);
 
         return static::parseWaitResponse(
             response: $response,
@@ -132,7 +278,7 @@ class Extension
     {
         $start = microtime(true);
 
-        $response = waitAnyTimeout($timeoutMs);
+        $response = waitAnyTimeout($timeoutMs, $this->runtime);
 
         // Distinct, non-"error:" sentinel the Go side returns on timeout. A real
         // result is msgpack (binary) and an error starts with "error:", so this
@@ -148,14 +294,244 @@ class Extension
         );
     }
 
+    /**
+     * Drains up to $max ready results in one extension call: blocks like waitAny
+     * (or waitAnyTimeout for $timeoutMs > 0) for the first result, then takes every
+     * further result that is already ready without blocking again. Returns an empty
+     * list when $timeoutMs elapsed with nothing ready. A negative $timeoutMs never
+     * blocks: what is ready now, possibly nothing (see readinessStream()).
+     *
+     * @return list<TaskResultDto>
+     */
+    public function waitMany(int $max, int $timeoutMs = 0): array
+    {
+        $start = microtime(true);
+
+        $response = waitMany($max, $timeoutMs, $this->runtime);
+
+        if ($response === 'timeout') {
+            return [];
+        }
+
+        if (str_starts_with($response, 'error:')) {
+            throw new TaskErrorException(
+                message: 'waitMany: ' . $response,
+            );
+        }
+
+        return static::parseBatchResponse(
+            response: $response,
+            errorContext: 'waitMany',
+            start: $start,
+        );
+    }
+
+    /**
+     * The descriptor of the readiness pipe: readable while a result is ready to be
+     * taken, drained by the wait calls. Created on the first call and kept for the
+     * lifetime of the runtime. Every runtime has its own pipe, signalled by its
+     * results only, so a ZTS thread watches its own runtime.
+     */
+    public function readinessFd(): int
+    {
+        $fd = readinessFd($this->runtime);
+
+        if ($fd < 0) {
+            throw new ExtensionCallException(
+                message: 'readinessFd: could not create the readiness descriptor',
+            );
+        }
+
+        return $fd;
+    }
+
+    /**
+     * The readiness descriptor as a PHP stream, for an external event loop
+     * (stream_select, ReactPHP, Revolt): watch it for reads and, when it fires,
+     * take the results with waitMany($max, -1) — or let Scheduler::pump() do that.
+     * Never read from the stream itself: the wait calls drain it.
+     *
+     * @return resource
+     */
+    public function readinessStream()
+    {
+        if ($this->readinessStream !== null) {
+            return $this->readinessStream;
+        }
+
+        $stream = fopen('php://fd/' . $this->readinessFd(), 'rb');
+
+        if ($stream === false) {
+            throw new ExtensionCallException(
+                message: 'readinessStream: could not open the readiness descriptor',
+            );
+        }
+
+        stream_set_blocking($stream, false);
+
+        return $this->readinessStream = $stream;
+    }
+
+    /**
+     * Dumps what the Go side currently holds, for diagnosing a stuck worker: every
+     * flow (key, task count, age) with its undelivered tasks (method, key, start
+     * time, whether it is a next), every open streaming state with its concrete Go
+     * type, and the sizes of the pending buffer and the results channel. Read-only.
+     * It also lists the last panics the Go side recovered (crashes: method,
+     * command, keys, payload preview, stack) with their lifetime counts
+     * (crashCounts); see docs/crash-telemetry.md.
+     *
+     * @return array<string, mixed>
+     */
+    public function inspect(): array
+    {
+        $response = inspect($this->runtime);
+
+        if (str_starts_with($response, 'error:')) {
+            throw new ExtensionCallException(
+                message: $response,
+            );
+        }
+
+        /** @var array<string, mixed> */
+        return json_decode($response, true, flags: JSON_THROW_ON_ERROR);
+    }
+
+    /**
+     * Records this worker's traffic with the Go side into $path (truncated): every
+     * task pushed, every result taken, every flow stopped and task cancelled, with
+     * timings. Replay the file offline with ext/cmd/flow-replay to reproduce a
+     * scheduling problem without the databases and peers it involved. Recording
+     * costs a file write per call, so keep it for debugging sessions. A recording
+     * in progress is closed and replaced. See docs/recording.md.
+     */
+    public function startRecording(string $path): void
+    {
+        $response = startRecording($path, $this->runtime);
+
+        if ($response !== '') {
+            throw new ExtensionCallException(
+                message: $response,
+            );
+        }
+    }
+
+    /**
+     * Closes the recording in progress, if any. Throws when writing it failed at
+     * some point: the file then ends at the first failed entry.
+     */
+    public function stopRecording(): void
+    {
+        $response = stopRecording($this->runtime);
+
+        if ($response !== '') {
+            throw new ExtensionCallException(
+                message: $response,
+            );
+        }
+    }
+
+    /**
+     * Replaces the fault-injection rules, for testing retry and timeout handling:
+     * matching pushes get delayed, fail with a synthetic error, lose their result or
+     * panic on the Go side. An empty list disables fault injection (the default;
+     * the SCONCUR_FAULTS environment variable may preset rules when the extension
+     * loads). Never enable it in production. See docs/fault-injection.md.
+     *
+     * @param array<int, FaultRule> $rules
+     */
+    public function setFaults(array $rules): void
+    {
+        $response = setFaults(json_encode(array_values($rules), JSON_THROW_ON_ERROR));
+
+        if ($response !== '') {
+            throw new ExtensionCallException(
+                message: $response,
+            );
+        }
+    }
+
+    /**
+     * Compresses the payloads of at least $thresholdBytes bytes crossing the
+     * extension boundary with zstd: the results (large MongoDB and SQL batches,
+     * HTTP bodies) and the pushed payloads. Worth it when copying megabytes between
+     * PHP and Go costs more than a fast decompress. Needs the zstd PHP extension;
+     * 0 disables it (the default). Applies to this instance's runtime only: under
+     * ZTS every thread sets its own. See docs/compression.md.
+     */
+    public function setCompression(int $thresholdBytes): void
+    {
+        if ($thresholdBytes > 0 && !function_exists('zstd_uncompress')) {
+            throw new ExtensionCallException(
+                message: 'setCompression: the "zstd" PHP extension is not loaded',
+            );
+        }
+
+        setCompression(max($thresholdBytes, 0), $this->runtime);
+
+        $this->compressionThreshold = max($thresholdBytes, 0);
+    }
+
+    /**
+     * Enables the idle reaper for streaming states (cursors, response and request
+     * bodies, upload sessions): a state no next() or lookup touched for $ms is
+     * closed, so a coroutine that abandoned it does not leak it for the worker's
+     * lifetime. A state with a next() in progress is never reaped, nor one PHP
+     * holds on purpose (an open SQL transaction, an acquired semaphore or mutex
+     * permit). 0 disables it (the default). The reaped count is reported by
+     * inspect() as reapedStates.
+     */
+    public function setStateIdleTtl(int $ms): void
+    {
+        setStateIdleTtl($ms);
+    }
+
     public function count(): int
     {
-        return tasksCount();
+        return tasksCount($this->runtime);
+    }
+
+    /**
+     * Stops a flow and every task it runs. A task still answering gets a
+     * cancelled result (TaskResultDto::$isCancelled) whose error carries
+     * $reasonCode and $reasonMessage, so a user abort can be told from a shutdown
+     * or a deadline; without a code it is TaskErrorDto::CODE_FLOW_STOPPED.
+     *
+     * Those results are returned here, not delivered by the wait calls: the flow
+     * is gone, so nothing is left queued for it. A caller that unwound the
+     * flow's coroutines itself may ignore them. An unknown runtime (one already
+     * destroyed) throws ExtensionCallException.
+     *
+     * @return list<TaskResultDto>
+     */
+    public function stopFlow(string $flowKey, string $reasonCode = '', string $reasonMessage = ''): array
+    {
+        $start = microtime(true);
+
+        $response = stopFlow($flowKey, $reasonCode, $reasonMessage, $this->runtime);
+
+        if ($response === '') {
+            return [];
+        }
+
+        static::checkCallResponse(flowKey: $flowKey, response: $response);
+
+        return static::parseBatchResponse(
+            response: $response,
+            errorContext: 'stopFlow',
+            start: $start,
+        );
     }
 
-    public function stopFlow(string $flowKey): void
+    /**
+     * Cancels a single task of a flow without stopping the flow: its siblings keep
+     * running. A still-running task is answered with a cancelled error result
+     * (TaskResultDto::$isCancelled); an open cursor or response body keyed by the
+     * task is closed. A task key the flow does not own is ignored.
+     */
+    public function cancelTask(string $flowKey, string $taskKey): void
     {
-        stopFlow($flowKey);
+        cancelTask($flowKey, $taskKey, $this->runtime);
     }
 
     /**
@@ -188,9 +564,32 @@ class Extension
         wsStopAccepting($flowKey);
     }
 
+    /**
+     * Releases the Go-side state: everything the extension holds, or under ZTS only
+     * this thread's runtime (other threads keep theirs), replaced by a fresh one so
+     * the instance stays usable, as it does after the process-wide destroy.
+     */
     public function destroy(): void
     {
-        destroy();
+        if ($this->runtime === 0) {
+            destroy();
+
+            return;
+        }
+
+        $this->releaseRuntime();
+
+        $this->runtime = createRuntime();
+
+        // The threshold went with the old runtime.
+        if ($this->compressionThreshold > 0) {
+            setCompression($this->compressionThreshold, $this->runtime);
+        }
+    }
+
+    public function getRuntime(): int
+    {
+        return $this->runtime;
     }
 
     public function version(): string
@@ -198,6 +597,38 @@ class Extension
         return version();
     }
 
+    /**
+     * Task keys are unique per process: the runtime is part of them under ZTS, as
+     * the streaming states (cursors, bodies) keyed by task are shared by runtimes.
+     */
+    protected function makeTaskKey(string $flowKey): string
+    {
+        ++static::$tasksCounter;
+
+        if ($this->runtime === 0) {
+            return $flowKey . ':' . static::$tasksCounter;
+        }
+
+        return $flowKey . ':' . $this->runtime . ':' . static::$tasksCounter;
+    }
+
+    /**
+     * Packs a payload for push or pushMany, zstd-compressed (with
+     * PUSH_FLAG_COMPRESSED) when it reaches the compression threshold.
+     *
+     * @return array{0: string, 1: int} the packed payload and its push flags
+     */
+    private function packPayload(PayloadInterface $payload): array
+    {
+        $packed = MessagePackTransport::pack($payload);
+
+        if ($this->compressionThreshold > 0 && strlen($packed) >= $this->compressionThreshold) {
+            return [zstd_compress($packed), self::PUSH_FLAG_COMPRESSED];
+        }
+
+        return [$packed, 0];
+    }
+
     protected static function parseWaitResponse(string $response, string $errorContext, float $start): TaskResultDto
     {
         if (str_starts_with($response, 'error:')) {
@@ -230,15 +661,24 @@ class Extension
             $offset += $header['taskKeyLen'];
             $payload = substr($response, $offset);
 
+            if (($header['flags'] & self::FRAME_FLAG_COMPRESSED) !== 0) {
+                $payload = static::decompress($payload);
+            }
+
+            $isError = ($header['flags'] & self::FRAME_FLAG_ERROR) !== 0;
+            $error   = $isError ? self::parseTaskError($payload) : null;
+
             return new TaskResultDto(
                 flowKey: $flowKey,
                 method: MethodEnum::from($method),
                 key: $taskKey,
-                isError: ($header['flags'] & self::FRAME_FLAG_ERROR) !== 0,
-                payload: $payload,
+                isError: $isError,
+                payload: $error === null ? $payload : $error->message,
                 hasNext: ($header['flags'] & self::FRAME_FLAG_HAS_NEXT) !== 0,
                 executionMs: $header['executionMs'],
                 totalExecutionMs: (int) ((microtime(true) - $start) * 1000),
+                isCancelled: ($header['flags'] & self::FRAME_FLAG_CANCELLED) !== 0,
+                error: $error,
             );
         } catch (UnexpectedResponseFormatException $exception) {
             throw $exception;
@@ -250,6 +690,79 @@ class Extension
         }
     }
 
+    /**
+     * Decodes a batch of result frames (see BATCH_COUNT_SIZE), as returned by
+     * waitMany and stopFlow.
+     *
+     * @return list<TaskResultDto>
+     */
+    protected static function parseBatchResponse(string $response, string $errorContext, float $start): array
+    {
+        $header = unpack('Ncount', $response);
+
+        if ($header === false) {
+            throw new UnexpectedResponseFormatException(
+                message: 'Could not unpack result batch header.',
+            );
+        }
+
+        $results = [];
+        $offset  = self::BATCH_COUNT_SIZE;
+
+        for ($index = 0; $index < $header['count']; $index++) {
+            $frameHeader = unpack('NframeLen', $response, $offset);
+
+            if ($frameHeader === false) {
+                throw new UnexpectedResponseFormatException(
+                    message: 'Could not unpack result batch frame length.',
+                );
+            }
+
+            $offset += self::BATCH_FRAME_LENGTH_SIZE;
+
+            $results[] = static::parseWaitResponse(
+                response: substr($response, $offset, $frameHeader['frameLen']),
+                errorContext: $errorContext,
+                start: $start,
+            );
+
+            $offset += $frameHeader['frameLen'];
+        }
+
+        return $results;
+    }
+
+    protected static function decompress(string $payload): string
+    {
+        $decompressed = zstd_uncompress($payload);
+
+        if ($decompressed === false) {
+            throw new UnexpectedResponseFormatException(
+                message: 'Could not decompress the result payload.',
+            );
+        }
+
+        return $decompressed;
+    }
+
+    /**
+     * Decodes the structured error envelope an error result carries (Go:
+     * errs.Details, short MessagePack keys).
+     */
+    protected static function parseTaskError(string $payload): TaskErrorDto
+    {
+        $data = MessagePackTransport::unpack($payload);
+
+        return new TaskErrorDto(
+            code: (string) ($data['cd'] ?? ''),
+            category: (string) ($data['ct'] ?? TaskErrorDto::CATEGORY_INTERNAL),
+            retryable: (bool) ($data['rt'] ?? false),
+            driverCode: (string) ($data['dc'] ?? ''),
+            labels: array_map('strval', (array) ($data['lb'] ?? [])),
+            message: (string) ($data['ms'] ?? ''),
+        );
+    }
+
     protected static function checkCallResponse(string $flowKey, string $response): void
     {
         if (!str_starts_with($response, 'error:')) {
@@ -292,8 +805,25 @@ class Extension
         static::$checked = true;
     }
 
+    private function releaseRuntime(): void
+    {
+        $response = destroyRuntime($this->runtime);
+
+        if ($response !== '') {
+            throw new ExtensionCallException(
+                message: $response,
+            );
+        }
+    }
+
     public function __destruct()
     {
-        $this->destroy();
+        if ($this->runtime === 0) {
+            $this->destroy();
+
+            return;
+        }
+
+        $this->releaseRuntime();
     }
 }
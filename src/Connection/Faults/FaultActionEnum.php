@@ -0,0 +1,21 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Connection\Faults;
+
+/**
+ * What an injected fault does to a task.
+ * Go: sconcur/internal/faults.Action.
+ */
+enum FaultActionEnum: string
+{
+    /** Delays the task before the feature runs; its deadline still applies. */
+    case Latency = 'latency';
+    /** Answers the task with a synthetic error instead of running the feature. */
+    case Error = 'error';
+    /** Runs the feature but discards its result: only the deadline, a cancel or the flow stop answer the task. */
+    case Drop = 'drop';
+    /** Panics in the task goroutine: the task is answered with a panic-class error. */
+    case Panic = 'panic';
+}
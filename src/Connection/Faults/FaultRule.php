@@ -0,0 +1,100 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Connection\Faults;
+
+use JsonSerializable;
+
+/**
+ * One fault-injection rule for Extension::setFaults(). $target is a method code
+ * ("hc") or a method and the command of its payload ("mng:fnd", "my:qry"). The rule
+ * fires on every matching push, on every $every-th one, or with $probability —
+ * set at most one of the two. See docs/fault-injection.md.
+ *
+ * Go: sconcur/internal/faults.Rule.
+ */
+readonly class FaultRule implements JsonSerializable
+{
+    public function __construct(
+        public string $target,
+        public FaultActionEnum $action,
+        public int $latencyMs = 0,
+        public string $category = '',
+        public string $code = '',
+        public string $message = '',
+        public float $probability = 0,
+        public int $every = 0,
+    ) {
+    }
+
+    public static function latency(string $target, int $ms, float $probability = 0, int $every = 0): self
+    {
+        return new self(
+            target: $target,
+            action: FaultActionEnum::Latency,
+            latencyMs: $ms,
+            probability: $probability,
+            every: $every,
+        );
+    }
+
+    /**
+     * $category is one of the TaskErrorDto categories (network, timeout, driver,
+     * validation, cancelled, panic, internal); $code defaults to the code real
+     * failures of that category carry.
+     */
+    public static function error(
+        string $target,
+        string $category,
+        string $code = '',
+        float $probability = 0,
+        int $every = 0,
+    ): self {
+        return new self(
+            target: $target,
+            action: FaultActionEnum::Error,
+            category: $category,
+            code: $code,
+            probability: $probability,
+            every: $every,
+        );
+    }
+
+    public static function drop(string $target, float $probability = 0, int $every = 0): self
+    {
+        return new self(
+            target: $target,
+            action: FaultActionEnum::Drop,
+            probability: $probability,
+            every: $every,
+        );
+    }
+
+    public static function panic(string $target, float $probability = 0, int $every = 0): self
+    {
+        return new self(
+            target: $target,
+            action: FaultActionEnum::Panic,
+            probability: $probability,
+            every: $every,
+        );
+    }
+
+    /**
+     * @return array<string, mixed>
+     */
+    public function jsonSerialize(): array
+    {
+        return [
+            'target'      => $this->target,
+            'action'      => $this->action->value,
+            'latencyMs'   => $this->latencyMs,
+            'category'    => $this->category,
+            'code'        => $this->code,
+            'message'     => $this->message,
+            'probability' => $this->probability,
+            'every'       => $this->every,
+        ];
+    }
+}
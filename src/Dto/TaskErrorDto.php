@@ -0,0 +1,50 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Dto;
+
+/**
+ * Structured error of a failed task, decoded from the error result payload (Go:
+ * errs.Details). $category is the coarse class retry logic branches on: network,
+ * timeout, validation, driver, cancelled, panic or internal. $driverCode is the
+ * driver-native code (MySQL errno, Postgres SQLSTATE, MongoDB error code) and
+ * $labels the MongoDB error labels.
+ */
+readonly class TaskErrorDto
+{
+    public const string CATEGORY_NETWORK    = 'network';
+    public const string CATEGORY_TIMEOUT    = 'timeout';
+    public const string CATEGORY_VALIDATION = 'validation';
+    public const string CATEGORY_DRIVER     = 'driver';
+    public const string CATEGORY_CANCELLED  = 'cancelled';
+    public const string CATEGORY_PANIC      = 'panic';
+    public const string CATEGORY_INTERNAL   = 'internal';
+
+    /**
+     * Codes of a task cancelled by the stop of its flow (category cancelled) when
+     * the stop gave no code of its own, and when the extension was destroyed or
+     * the server stopped. Extension::stopFlow() may set any other code.
+     */
+    public const string CODE_FLOW_STOPPED = 'flow_stopped';
+    public const string CODE_SHUTDOWN     = 'shutdown';
+
+    /**
+     * Code of an HTTP request that failed on its proxy (category network or
+     * timeout) rather than on the target.
+     */
+    public const string CODE_PROXY_FAILURE = 'proxy_failure';
+
+    /**
+     * @param array<string> $labels
+     */
+    public function __construct(
+        public string $code,
+        public string $category,
+        public bool $retryable,
+        public string $driverCode,
+        public array $labels,
+        public string $message,
+    ) {
+    }
+}
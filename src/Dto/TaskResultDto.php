@@ -17,6 +17,8 @@ readonly class TaskResultDto
         public bool $hasNext,
         public int $executionMs,
         public int $totalExecutionMs,
+        public bool $isCancelled = false,
+        public ?TaskErrorDto $error = null,
     ) {
     }
 }
@@ -0,0 +1,16 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Exceptions\Channel;
+
+use RuntimeException;
+
+/**
+ * A channel operation hit a closed channel: a send after close(), or a receive once
+ * the channel is closed and its buffer drained. The consumer catches it (or iterates
+ * the channel with foreach) to learn the producer is done.
+ */
+class ChannelClosedException extends RuntimeException
+{
+}
@@ -0,0 +1,15 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Exceptions\HttpClient;
+
+/**
+ * The request failed on its proxy rather than on the target: the proxy could not
+ * be reached, refused the credentials, or could not open the tunnel. Still a
+ * PSR-18 network error; catch it first to tell a broken proxy from a broken
+ * target.
+ */
+class ProxyException extends NetworkException
+{
+}
@@ -5,7 +5,26 @@ declare(strict_types=1);
 namespace SConcur\Exceptions;
 
 use RuntimeException;
+use SConcur\Dto\TaskErrorDto;
+use Throwable;
 
+/**
+ * A task finished with an error result. The structured error (category, code,
+ * driver-native code) is attached when the failure came from a feature.
+ */
 class TaskErrorException extends RuntimeException
 {
+    public function __construct(
+        string $message = '',
+        int $code = 0,
+        ?Throwable $previous = null,
+        protected ?TaskErrorDto $error = null,
+    ) {
+        parent::__construct($message, $code, $previous);
+    }
+
+    public function getError(): ?TaskErrorDto
+    {
+        return $this->error;
+    }
 }
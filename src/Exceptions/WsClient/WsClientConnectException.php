@@ -9,7 +9,7 @@ use RuntimeException;
 /**
  * Dialing a ws-client connection failed (connection refused, DNS failure, handshake
  * failure or a connect timeout). A runtime failure thrown from WsClient::connect(); the
- * Go side tags the cause with a "net:" marker, preserved in the message.
+ * previous TaskErrorException carries the network-class error.
  */
 class WsClientConnectException extends RuntimeException
 {
@@ -0,0 +1,137 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel;
+
+use Generator;
+use IteratorAggregate;
+use SConcur\Exceptions\Channel\ChannelClosedException;
+use SConcur\Exceptions\TaskErrorException;
+use SConcur\Features\Channel\Payloads\ClosePayload;
+use SConcur\Features\Channel\Payloads\CreatePayload;
+use SConcur\Features\Channel\Payloads\ReceivePayload;
+use SConcur\Features\Channel\Payloads\SendPayload;
+use SConcur\Features\FeatureExecutor;
+use SConcur\Transport\MessagePackTransport;
+
+/**
+ * A named bounded channel kept on the Go side, for producer/consumer pipelines
+ * between coroutines and flows of one worker. send() suspends the coroutine while
+ * the channel is full (backpressure), receive() suspends it until a value arrives;
+ * receivers waiting on different flows are served in arrival order. Values are
+ * passed through serialize(), so anything serializable may be sent.
+ *
+ * Both sides may call create() with the same name and capacity: the first opens
+ * the channel, the others join it. See docs/channel.md.
+ *
+ * @implements IteratorAggregate<int, mixed>
+ */
+readonly class Channel implements IteratorAggregate
+{
+    /** The error code the Go side answers a send on a closed channel with. */
+    private const string CODE_CLOSED = 'channel_closed';
+
+    private function __construct(
+        public string $name,
+    ) {
+    }
+
+    /**
+     * Opens the channel $name, or joins it when it is already open with the same
+     * capacity. $capacity is the number of values buffered before send() waits; 0
+     * makes every send() wait for a receiver. A closed channel of that name is
+     * replaced by a new, empty one.
+     */
+    public static function create(string $name, int $capacity = 0): self
+    {
+        FeatureExecutor::exec(
+            payload: new CreatePayload(
+                name: $name,
+                capacity: $capacity,
+            ),
+        );
+
+        return new self($name);
+    }
+
+    /**
+     * Puts $value into the channel, waiting while it is full. Throws
+     * ChannelClosedException once the channel is closed.
+     */
+    public function send(mixed $value): void
+    {
+        try {
+            FeatureExecutor::exec(
+                payload: new SendPayload(
+                    name: $this->name,
+                    value: serialize($value),
+                ),
+            );
+        } catch (TaskErrorException $exception) {
+            if ($exception->getError()?->code === self::CODE_CLOSED) {
+                throw new ChannelClosedException(
+                    message: "Channel $this->name is closed.",
+                    previous: $exception,
+                );
+            }
+
+            throw $exception;
+        }
+    }
+
+    /**
+     * Takes the next value, waiting until one arrives. After close() the values
+     * still buffered are handed out first; then ChannelClosedException is thrown.
+     */
+    public function receive(): mixed
+    {
+        $result = FeatureExecutor::exec(
+            payload: new ReceivePayload(
+                name: $this->name,
+            ),
+        );
+
+        /** @var array{v?: string, ok?: bool} $received */
+        $received = MessagePackTransport::unpack($result->payload);
+
+        if (!($received['ok'] ?? false)) {
+            throw new ChannelClosedException(
+                message: "Channel $this->name is closed.",
+            );
+        }
+
+        return unserialize($received['v'] ?? '');
+    }
+
+    /**
+     * Closes the channel: waiting and later senders fail, receivers drain what is
+     * buffered and then see the end. Closing a closed channel is a no-op.
+     */
+    public function close(): void
+    {
+        FeatureExecutor::exec(
+            payload: new ClosePayload(
+                name: $this->name,
+            ),
+        );
+    }
+
+    /**
+     * Receives values until the channel is closed and drained.
+     *
+     * @return Generator<int, mixed>
+     */
+    public function getIterator(): Generator
+    {
+        while (true) {
+            try {
+                $value = $this->receive();
+            } catch (ChannelClosedException) {
+                return;
+            }
+
+            yield $value;
+        }
+    }
+}
@@ -0,0 +1,26 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel;
+
+/**
+ * Sub-operations of the channel feature, carried in the payload envelope (the `cm`
+ * field) under the single MethodEnum::Channel — mirrors WsClientCommandEnum.
+ *
+ * Go: types.ChannelCommand (ext/internal/types/channel.go).
+ */
+enum ChannelCommandEnum: string
+{
+    /** Open a named channel, or join it when it is already open. */
+    case Create = 'crt';
+
+    /** Put one value, waiting while the channel is full. */
+    case Send = 'snd';
+
+    /** Take the next value, waiting until one arrives. */
+    case Receive = 'rcv';
+
+    /** Close the channel: senders fail, receivers drain what is left. */
+    case Close = 'cls';
+}
@@ -0,0 +1,39 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads\Base;
+
+use SConcur\Features\Channel\ChannelCommandEnum;
+use SConcur\Features\MethodEnum;
+use SConcur\Transport\PayloadInterface;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * Builds the command envelope (cm/p) every channel payload sends: the sub-operation
+ * command plus its parameters. Mirrors Base\BaseWsClientPayload.
+ *
+ * Go: payloads.Envelope (ext/internal/features/channel/payloads/payloads.go).
+ */
+abstract readonly class BaseChannelPayload implements PayloadInterface
+{
+    abstract protected function getCommand(): ChannelCommandEnum;
+
+    abstract protected function getParameters(): PayloadParametersInterface;
+
+    public function getMethod(): MethodEnum
+    {
+        return MethodEnum::Channel;
+    }
+
+    /**
+     * @return array<string, mixed>
+     */
+    public function getData(): array
+    {
+        return [
+            'cm' => $this->getCommand()->value,
+            'p'  => $this->getParameters()->getData(),
+        ];
+    }
+}
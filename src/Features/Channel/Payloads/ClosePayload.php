@@ -0,0 +1,34 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Features\Channel\ChannelCommandEnum;
+use SConcur\Features\Channel\Payloads\Base\BaseChannelPayload;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * The Close command: close a named channel.
+ *
+ * Go: payloads.NameParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class ClosePayload extends BaseChannelPayload
+{
+    public function __construct(
+        protected string $name,
+    ) {
+    }
+
+    protected function getCommand(): ChannelCommandEnum
+    {
+        return ChannelCommandEnum::Close;
+    }
+
+    protected function getParameters(): PayloadParametersInterface
+    {
+        return new NamePayloadParameters(
+            name: $this->name,
+        );
+    }
+}
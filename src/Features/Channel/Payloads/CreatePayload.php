@@ -0,0 +1,36 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Features\Channel\ChannelCommandEnum;
+use SConcur\Features\Channel\Payloads\Base\BaseChannelPayload;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * The Create command: open a named channel with the given buffer size.
+ *
+ * Go: payloads.CreateParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class CreatePayload extends BaseChannelPayload
+{
+    public function __construct(
+        protected string $name,
+        protected int $capacity,
+    ) {
+    }
+
+    protected function getCommand(): ChannelCommandEnum
+    {
+        return ChannelCommandEnum::Create;
+    }
+
+    protected function getParameters(): PayloadParametersInterface
+    {
+        return new CreatePayloadParameters(
+            name: $this->name,
+            capacity: $this->capacity,
+        );
+    }
+}
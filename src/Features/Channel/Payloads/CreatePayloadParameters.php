@@ -0,0 +1,33 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * Parameters of a Create command: the channel name and its buffer size (0 =
+ * unbuffered: a send waits for a receiver).
+ *
+ * Go: payloads.CreateParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class CreatePayloadParameters implements PayloadParametersInterface
+{
+    public function __construct(
+        protected string $name,
+        protected int $capacity,
+    ) {
+    }
+
+    /**
+     * @return array<string, int|string>
+     */
+    public function getData(): array
+    {
+        return [
+            'n'  => $this->name,
+            'cp' => $this->capacity,
+        ];
+    }
+}
@@ -0,0 +1,30 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * Parameters of the Receive and Close commands: the channel name.
+ *
+ * Go: payloads.NameParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class NamePayloadParameters implements PayloadParametersInterface
+{
+    public function __construct(
+        protected string $name,
+    ) {
+    }
+
+    /**
+     * @return array<string, string>
+     */
+    public function getData(): array
+    {
+        return [
+            'n' => $this->name,
+        ];
+    }
+}
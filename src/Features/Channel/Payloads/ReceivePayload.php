@@ -0,0 +1,35 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Features\Channel\ChannelCommandEnum;
+use SConcur\Features\Channel\Payloads\Base\BaseChannelPayload;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * The Receive command: take the next value of a named channel, waiting until one
+ * arrives.
+ *
+ * Go: payloads.NameParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class ReceivePayload extends BaseChannelPayload
+{
+    public function __construct(
+        protected string $name,
+    ) {
+    }
+
+    protected function getCommand(): ChannelCommandEnum
+    {
+        return ChannelCommandEnum::Receive;
+    }
+
+    protected function getParameters(): PayloadParametersInterface
+    {
+        return new NamePayloadParameters(
+            name: $this->name,
+        );
+    }
+}
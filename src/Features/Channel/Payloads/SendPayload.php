@@ -0,0 +1,36 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Features\Channel\ChannelCommandEnum;
+use SConcur\Features\Channel\Payloads\Base\BaseChannelPayload;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * The Send command: put one serialized value into a named channel.
+ *
+ * Go: payloads.SendParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class SendPayload extends BaseChannelPayload
+{
+    public function __construct(
+        protected string $name,
+        protected string $value,
+    ) {
+    }
+
+    protected function getCommand(): ChannelCommandEnum
+    {
+        return ChannelCommandEnum::Send;
+    }
+
+    protected function getParameters(): PayloadParametersInterface
+    {
+        return new SendPayloadParameters(
+            name: $this->name,
+            value: $this->value,
+        );
+    }
+}
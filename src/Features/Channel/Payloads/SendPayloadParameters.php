@@ -0,0 +1,33 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Channel\Payloads;
+
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * Parameters of a Send command: the channel and the value bytes (binary-safe; the Go
+ * side never looks inside).
+ *
+ * Go: payloads.SendParams (ext/internal/features/channel/payloads/payloads.go).
+ */
+readonly class SendPayloadParameters implements PayloadParametersInterface
+{
+    public function __construct(
+        protected string $name,
+        protected string $value,
+    ) {
+    }
+
+    /**
+     * @return array<string, string>
+     */
+    public function getData(): array
+    {
+        return [
+            'n' => $this->name,
+            'v' => $this->value,
+        ];
+    }
+}
@@ -0,0 +1,32 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Cron;
+
+use SConcur\Features\Cron\Payloads\CronPayload;
+use SConcur\Features\Cron\Results\CronResult;
+
+/**
+ * Cron schedules evaluated on the Go side: a long-lived worker (HttpServer,
+ * SocketServer) runs its maintenance jobs inside its own event loop instead of a
+ * system cron plus a PHP CLI. Fires the consumer was too late for are skipped and
+ * reported in CronFireDto::$missed.
+ */
+class Cron
+{
+    /**
+     * @param string $expression six fields "sec min hour day-of-month month day-of-week" (five fields fire at
+     *                           second 0): `*`, `?`, lists, ranges, steps, JAN-DEC / SUN-SAT names
+     * @param string $timezone   IANA time zone the expression is evaluated in; '' is the process local zone
+     */
+    public static function schedule(string $expression, string $timezone = ''): CronResult
+    {
+        return new CronResult(
+            payload: new CronPayload(
+                expression: $expression,
+                timezone: $timezone,
+            ),
+        );
+    }
+}
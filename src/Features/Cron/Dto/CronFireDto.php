@@ -0,0 +1,23 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Cron\Dto;
+
+/**
+ * One fire of a cron schedule. $number counts the schedule's fire times from 1;
+ * $missed counts the ones skipped since the previous fire because the consumer
+ * fell behind (missed fires are reported, never delivered in a burst).
+ *
+ * Go: payloads.Fire (ext/internal/features/cron/payloads/payloads.go).
+ */
+readonly class CronFireDto
+{
+    public function __construct(
+        public int $number,
+        public int $missed,
+        public int $scheduledAtMs,
+        public int $firedAtMs,
+    ) {
+    }
+}
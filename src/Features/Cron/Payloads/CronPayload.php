@@ -0,0 +1,36 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Cron\Payloads;
+
+use SConcur\Features\MethodEnum;
+use SConcur\Transport\PayloadInterface;
+
+/**
+ * Go: payloads.CronPayload (ext/internal/features/cron/payloads/payloads.go).
+ */
+readonly class CronPayload implements PayloadInterface
+{
+    public function __construct(
+        protected string $expression,
+        protected string $timezone = '',
+    ) {
+    }
+
+    public function getMethod(): MethodEnum
+    {
+        return MethodEnum::Cron;
+    }
+
+    /**
+     * @return array<string, string>
+     */
+    public function getData(): array
+    {
+        return [
+            'ex' => $this->expression,
+            'tz' => $this->timezone,
+        ];
+    }
+}
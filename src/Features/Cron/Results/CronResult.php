@@ -0,0 +1,133 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Cron\Results;
+
+use Iterator;
+use SConcur\Dto\TaskResultDto;
+use SConcur\Features\Cron\Dto\CronFireDto;
+use SConcur\Features\FeatureExecutor;
+use SConcur\State;
+use SConcur\Transport\MessagePackTransport;
+use SConcur\Transport\PayloadInterface;
+
+/**
+ * The fires of a cron schedule: each one is pulled from the Go side on demand
+ * (FeatureExecutor::next), suspending the coroutine until the next fire time.
+ * Keys are the fire numbers. Breaking out of the loop stops the schedule.
+ *
+ * Mirrors Ticker\Results\TickResult.
+ *
+ * @implements Iterator<int, CronFireDto>
+ */
+class CronResult implements Iterator
+{
+    protected ?string $taskKey;
+    protected ?CronFireDto $currentFire;
+    protected bool $isLastFire;
+    protected bool $isFinished;
+
+    public function __construct(
+        protected PayloadInterface $payload,
+    ) {
+        $this->resetProperties();
+    }
+
+    public function current(): ?CronFireDto
+    {
+        return $this->currentFire;
+    }
+
+    public function next(): void
+    {
+        if ($this->isFinished) {
+            return;
+        }
+
+        if ($this->isLastFire) {
+            $this->isFinished  = true;
+            $this->currentFire = null;
+
+            return;
+        }
+
+        $this->setTaskResult(
+            FeatureExecutor::next(
+                taskKey: $this->taskKey,
+            ),
+        );
+    }
+
+    public function key(): ?int
+    {
+        return $this->currentFire?->number;
+    }
+
+    public function valid(): bool
+    {
+        return $this->isFinished === false;
+    }
+
+    public function rewind(): void
+    {
+        $this->releaseTask();
+
+        $this->resetProperties();
+
+        $taskResult = FeatureExecutor::exec(
+            payload: $this->payload,
+        );
+
+        $this->taskKey = $taskResult->key;
+
+        $this->setTaskResult($taskResult);
+    }
+
+    protected function setTaskResult(TaskResultDto $taskResult): void
+    {
+        $this->isLastFire = !$taskResult->hasNext;
+
+        // An empty last result: the expression has no fire time left.
+        if ($taskResult->payload === '') {
+            $this->isFinished  = true;
+            $this->currentFire = null;
+
+            return;
+        }
+
+        /** @var array{n: int, ms: int, sa: int, fa: int} $fire */
+        $fire = MessagePackTransport::unpack($taskResult->payload);
+
+        $this->currentFire = new CronFireDto(
+            number: $fire['n'],
+            missed: $fire['ms'],
+            scheduledAtMs: $fire['sa'],
+            firedAtMs: $fire['fa'],
+        );
+    }
+
+    protected function resetProperties(): void
+    {
+        $this->taskKey     = null;
+        $this->currentFire = null;
+        $this->isLastFire  = false;
+        $this->isFinished  = false;
+    }
+
+    /**
+     * Releases the synchronous flow owning the schedule when the iterator is
+     * abandoned (early break). No-op in async mode and after normal completion.
+     */
+    protected function releaseTask(): void
+    {
+        if ($this->taskKey !== null) {
+            State::releaseSyncTaskFlow($this->taskKey);
+        }
+    }
+
+    public function __destruct()
+    {
+        $this->releaseTask();
+    }
+}
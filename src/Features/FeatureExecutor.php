@@ -157,6 +157,7 @@ readonly class FeatureExecutor
         if ($result->isError) {
             throw new TaskErrorException(
                 message: $result->payload ?: 'Unknown error',
+                error: $result->error,
             );
         }
 
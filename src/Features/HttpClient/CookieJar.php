@@ -0,0 +1,93 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+use SConcur\Features\FeatureExecutor;
+use SConcur\Features\HttpClient\Payloads\JarPayload;
+
+/**
+ * A named cookie session kept on the Go side (net/http/cookiejar, RFC 6265 rules
+ * with a public-suffix policy). A HttpClient bound to it (withCookieJar()) sends
+ * the jar's cookies with every request and every redirect hop, and stores the
+ * Set-Cookie headers of the responses, so a login flow needs no cookie handling
+ * in PHP.
+ *
+ * The jar lives in the worker process until close(); export() and import() move a
+ * session across processes or restarts as an opaque JSON snapshot. A jar id is
+ * shared by every client of the process that uses it.
+ *
+ * Go: sessionJar (ext/internal/features/httpclient/cookiejar.go).
+ */
+readonly class CookieJar
+{
+    private function __construct(
+        public string $id,
+    ) {
+    }
+
+    /**
+     * Opens the jar with the given id, or a jar with a fresh id. Opening an id that
+     * is already open returns the same session.
+     */
+    public static function open(?string $id = null): self
+    {
+        $id ??= uniqid('jar_', more_entropy: true);
+
+        FeatureExecutor::exec(
+            payload: new JarPayload(
+                command: HttpClientCommandEnum::JarOpen,
+                jarId: $id,
+            ),
+        );
+
+        return new self($id);
+    }
+
+    /**
+     * Returns the live cookies of the jar (expired and replaced ones are left
+     * out) as a snapshot for import().
+     */
+    public function export(): string
+    {
+        $result = FeatureExecutor::exec(
+            payload: new JarPayload(
+                command: HttpClientCommandEnum::JarExport,
+                jarId: $this->id,
+            ),
+        );
+
+        return $result->payload;
+    }
+
+    /**
+     * Merges a snapshot made by export() into the jar: a cookie of the snapshot
+     * replaces the same cookie in the jar, cookies that expired meanwhile are
+     * skipped.
+     */
+    public function import(string $snapshot): void
+    {
+        FeatureExecutor::exec(
+            payload: new JarPayload(
+                command: HttpClientCommandEnum::JarImport,
+                jarId: $this->id,
+                snapshot: $snapshot,
+            ),
+        );
+    }
+
+    /**
+     * Drops the jar and its cookies. A request sent with a closed jar fails with a
+     * RequestException.
+     */
+    public function close(): void
+    {
+        FeatureExecutor::exec(
+            payload: new JarPayload(
+                command: HttpClientCommandEnum::JarClose,
+                jarId: $this->id,
+            ),
+        );
+    }
+}
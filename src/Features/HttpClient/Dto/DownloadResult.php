@@ -8,7 +8,8 @@ namespace SConcur\Features\HttpClient\Dto;
  * The result of HttpClient::download(): the response status, the response headers
  * exactly as the server returned them, the number of bytes actually written to the
  * file (the authoritative size — measured by io.Copy on the Go side, independent of
- * any Content-Length header) and how long the download took.
+ * any Content-Length header), how long the download took and how many times the
+ * request was sent (above 1 only with a RetryPolicy).
  */
 readonly class DownloadResult
 {
@@ -20,6 +21,7 @@ readonly class DownloadResult
         public array $headers,
         public int $filesizeBytes,
         public int $executionMs,
+        public int $attempts = 1,
     ) {
     }
 }
@@ -9,10 +9,13 @@ use Psr\Http\Client\ClientInterface;
 use Psr\Http\Message\RequestInterface;
 use Psr\Http\Message\ResponseFactoryInterface;
 use Psr\Http\Message\ResponseInterface;
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Exceptions\HttpClient\DownloadException;
 use SConcur\Exceptions\HttpClient\HttpClientException;
 use SConcur\Exceptions\HttpClient\NetworkException;
+use SConcur\Exceptions\HttpClient\ProxyException;
 use SConcur\Exceptions\HttpClient\RequestException;
+use SConcur\Exceptions\TaskErrorException;
 use SConcur\Dto\TaskResultDto;
 use SConcur\Features\FeatureExecutor;
 use SConcur\Features\HttpClient\Dto\DownloadResult;
@@ -32,13 +35,16 @@ use Throwable;
  * concurrently. Outside a WaitGroup the same call works synchronously.
  *
  * The response body is a streaming ResponseBodyStream — it is never buffered whole
- * in the extension. See docs/http-client.md.
+ * in the extension. Cookies are not kept unless the client is bound to a CookieJar
+ * (withCookieJar()). See docs/http-client.md.
  */
 readonly class HttpClient implements ClientInterface
 {
-    /** Error-class markers the Go side prefixes onto its error payloads. */
-    protected const string NETWORK_MARKER = 'net:';
-    protected const string REQUEST_MARKER = 'req:';
+    /**
+     * Response header carrying how many times the request was sent; set only when
+     * a RetryPolicy is configured.
+     */
+    public const string ATTEMPTS_HEADER = 'X-SConcur-Attempts';
 
     /** Default io.Copy buffer size for download() (64 KiB), tunable per call. */
     protected const int DEFAULT_DOWNLOAD_BUFFER_SIZE_BYTES = 65_536;
@@ -46,9 +52,23 @@ readonly class HttpClient implements ClientInterface
     public function __construct(
         protected ResponseFactoryInterface $responseFactory,
         protected HttpClientOptions $options = new HttpClientOptions(),
+        protected ?CookieJar $cookieJar = null,
     ) {
     }
 
+    /**
+     * Returns a client with the same options whose requests use the cookie session
+     * $cookieJar (null: no cookies are kept).
+     */
+    public function withCookieJar(?CookieJar $cookieJar): self
+    {
+        return new self(
+            responseFactory: $this->responseFactory,
+            options: $this->options,
+            cookieJar: $cookieJar,
+        );
+    }
+
     /**
      * @throws ClientExceptionInterface
      */
@@ -133,6 +153,7 @@ readonly class HttpClient implements ClientInterface
             headers: $this->normalizeHeaders($meta['hd'] ?? []),
             filesizeBytes: (int) ($meta['n'] ?? 0),
             executionMs: $result->executionMs,
+            attempts: max(1, (int) ($meta['at'] ?? 1)),
         );
     }
 
@@ -202,10 +223,20 @@ readonly class HttpClient implements ClientInterface
 
         $response = $this->responseFactory->createResponse((int) ($meta['st'] ?? 200));
 
+        $protocolVersion = $this->protocolVersion((string) ($meta['pr'] ?? ''));
+
+        if ($protocolVersion !== null) {
+            $response = $response->withProtocolVersion($protocolVersion);
+        }
+
         foreach ($this->normalizeHeaders($meta['hd'] ?? []) as $name => $values) {
             $response = $response->withHeader($name, $values);
         }
 
+        if ($this->options->retry !== null) {
+            $response = $response->withHeader(self::ATTEMPTS_HEADER, (string) max(1, (int) ($meta['at'] ?? 1)));
+        }
+
         $contentLength = (int) ($meta['cl'] ?? -1);
 
         $body = new ResponseBodyStream(
@@ -228,6 +259,10 @@ readonly class HttpClient implements ClientInterface
         int $sinkPerm = 0,
         int $downloadBufferSizeBytes = 0,
     ): RequestPayload {
+        $retry = $this->options->retry;
+        $tls   = $this->options->tls;
+        $proxy = $this->options->proxy;
+
         return new RequestPayload(
             new RequestPayloadParameters(
                 method: $request->getMethod(),
@@ -252,10 +287,43 @@ readonly class HttpClient implements ClientInterface
                 sinkMode: $sinkMode,
                 sinkPerm: $sinkPerm,
                 downloadBufferSizeBytes: $downloadBufferSizeBytes,
+                prefetchDepth: $this->options->prefetchDepth,
+                httpVersion: $this->options->httpVersion->value,
+                retryMaxAttempts: $retry?->maxAttempts ?? 0,
+                retryBackoffMs: $retry?->backoffMs ?? 0,
+                retryMaxBackoffMs: $retry?->maxBackoffMs ?? 0,
+                retryStatuses: $retry?->retryStatuses ?? [],
+                retryNonIdempotent: $retry?->retryNonIdempotent ?? false,
+                jarId: $this->cookieJar?->id ?? '',
+                tlsCaFiles: $tls?->caFiles ?? [],
+                tlsCaPem: $tls?->caPem ?? '',
+                tlsCertFile: $tls?->certFile ?? '',
+                tlsKeyFile: $tls?->keyFile ?? '',
+                tlsServerName: $tls?->serverName ?? '',
+                tlsMinVersion: $tls?->minVersion?->value ?? '',
+                tlsPins: $tls?->pinnedPublicKeys ?? [],
+                proxyUrl: $proxy?->url ?? '',
+                proxyUser: $proxy?->username ?? '',
+                proxyPassword: $proxy?->password ?? '',
+                noProxy: $proxy?->noProxy ?? [],
             ),
         );
     }
 
+    /**
+     * Maps the negotiated protocol reported by Go ("HTTP/1.1", "HTTP/2.0") to a
+     * PSR-7 protocol version ("1.1", "2"); null when unknown.
+     */
+    protected function protocolVersion(string $proto): ?string
+    {
+        return match ($proto) {
+            'HTTP/1.0' => '1.0',
+            'HTTP/1.1' => '1.1',
+            'HTTP/2.0' => '2',
+            default    => null,
+        };
+    }
+
     /**
      * An empty header map decodes to stdClass (a MessagePack quirk), and nested
      * values may too; normalize to array<string, array<int, string>>.
@@ -274,17 +342,31 @@ readonly class HttpClient implements ClientInterface
     }
 
     /**
-     * Maps an extension failure to the right PSR-18 exception by the marker the Go
-     * side prefixed onto the error payload (net/req), defaulting to a generic
-     * client error. The marker may sit on a wrapped exception, so the whole chain
-     * is inspected.
+     * Maps an extension failure to the right PSR-18 exception by the category of
+     * the structured task error: network and timeout failures are network errors
+     * (a ProxyException when the proxy failed), an invalid request is a request
+     * error, the rest a generic client error. The
+     * task error may sit on a wrapped exception, so the whole chain is inspected.
      */
     protected function toClientException(Throwable $exception, RequestInterface $request): ClientExceptionInterface
     {
         for ($current = $exception; $current !== null; $current = $current->getPrevious()) {
-            $message = $current->getMessage();
+            if (!$current instanceof TaskErrorException || $current->getError() === null) {
+                continue;
+            }
+
+            $message  = $current->getMessage();
+            $category = $current->getError()->category;
+
+            if ($current->getError()->code === TaskErrorDto::CODE_PROXY_FAILURE) {
+                return new ProxyException(
+                    request: $request,
+                    message: $message,
+                    previous: $exception,
+                );
+            }
 
-            if (str_starts_with($message, self::NETWORK_MARKER)) {
+            if ($category === TaskErrorDto::CATEGORY_NETWORK || $category === TaskErrorDto::CATEGORY_TIMEOUT) {
                 return new NetworkException(
                     request: $request,
                     message: $message,
@@ -292,7 +374,7 @@ readonly class HttpClient implements ClientInterface
                 );
             }
 
-            if (str_starts_with($message, self::REQUEST_MARKER)) {
+            if ($category === TaskErrorDto::CATEGORY_VALIDATION) {
                 return new RequestException(
                     request: $request,
                     message: $message,
@@ -21,4 +21,16 @@ enum HttpClientCommandEnum: string
 
     /** Close a streamed request body: no more chunks. */
     case UploadEnd = 'upe';
+
+    /** Open a named cookie jar (kept if it is already open). */
+    case JarOpen = 'jop';
+
+    /** Export the cookies of a jar as a snapshot. */
+    case JarExport = 'jex';
+
+    /** Merge a snapshot into a jar, opening it if needed. */
+    case JarImport = 'jim';
+
+    /** Close a cookie jar: its cookies are dropped. */
+    case JarClose = 'jcl';
 }
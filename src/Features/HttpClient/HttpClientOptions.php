@@ -35,6 +35,16 @@ readonly class HttpClientOptions
      *                                      __toString must not throw, so when false a read failure is turned into an
      *                                      E_USER_WARNING and an empty string. Defaults to true, mirroring Guzzle's
      *                                      stream behaviour on PHP >= 7.4 (re-throw).
+     * @param int  $prefetchDepth           response-body chunks read ahead in the background while the current one is
+     *                                      consumed; 0 (default) reads each chunk on demand
+     * @param HttpVersion $httpVersion      protocol to speak: HTTP/1.1 only (default), HTTP/2 by ALPN over TLS, or
+     *                                      h2c with prior knowledge; see HttpVersion
+     * @param RetryPolicy|null $retry       resend failed requests with backoff; null (default) sends each request
+     *                                      once. See RetryPolicy
+     * @param TlsOptions|null $tls          private CA, client certificate, SNI, minimum version and pins; null
+     *                                      (default) uses the system roots. See TlsOptions
+     * @param ProxyOptions|null $proxy      explicit proxy with credentials and a bypass list; null (default) uses
+     *                                      the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment. See ProxyOptions
      */
     public function __construct(
         public int $requestTimeoutMs = 30_000,
@@ -51,6 +61,11 @@ readonly class HttpClientOptions
         public int $tlsHandshakeTimeoutMs = 10_000,
         public bool $streamRequestBody = false,
         public bool $throwOnToStringError = true,
+        public int $prefetchDepth = 0,
+        public HttpVersion $httpVersion = HttpVersion::Http1,
+        public ?RetryPolicy $retry = null,
+        public ?TlsOptions $tls = null,
+        public ?ProxyOptions $proxy = null,
     ) {
     }
 }
@@ -0,0 +1,25 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+/**
+ * The HTTP protocol HttpClient speaks (HttpClientOptions::$httpVersion). The
+ * version is part of the Go-side transport identity, so each one keeps its own
+ * connection pool; an HTTP/2 pool multiplexes the requests to a host over one
+ * connection. The negotiated protocol is the response's getProtocolVersion().
+ *
+ * Go: httpVersion* constants (ext/internal/features/httpclient/client.go).
+ */
+enum HttpVersion: string
+{
+    /** HTTP/1.1 only (the default). */
+    case Http1 = '1.1';
+
+    /** HTTP/2 negotiated by ALPN over TLS, HTTP/1.1 otherwise and for http:// URLs. */
+    case Auto = 'auto';
+
+    /** Cleartext HTTP/2 with prior knowledge for http:// URLs, HTTP/2 over TLS for https:// ones. */
+    case H2c = 'h2c';
+}
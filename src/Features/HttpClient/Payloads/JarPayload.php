@@ -0,0 +1,35 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient\Payloads;
+
+use SConcur\Features\HttpClient\HttpClientCommandEnum;
+use SConcur\Features\HttpClient\Payloads\Base\BaseHttpClientPayload;
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * A cookie-jar command (JarOpen/JarExport/JarImport/JarClose) on one jar.
+ */
+readonly class JarPayload extends BaseHttpClientPayload
+{
+    public function __construct(
+        protected HttpClientCommandEnum $command,
+        protected string $jarId,
+        protected string $snapshot = '',
+    ) {
+    }
+
+    protected function getCommand(): HttpClientCommandEnum
+    {
+        return $this->command;
+    }
+
+    protected function getParameters(): PayloadParametersInterface
+    {
+        return new JarPayloadParameters(
+            jarId: $this->jarId,
+            snapshot: $this->snapshot,
+        );
+    }
+}
@@ -0,0 +1,33 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient\Payloads;
+
+use SConcur\Transport\PayloadParametersInterface;
+
+/**
+ * Parameters of a cookie-jar command: the jar and, for an import, the snapshot to
+ * load (empty otherwise).
+ *
+ * Go: payloads.JarParams (ext/internal/features/httpclient/payloads/payloads.go).
+ */
+readonly class JarPayloadParameters implements PayloadParametersInterface
+{
+    public function __construct(
+        protected string $jarId,
+        protected string $snapshot,
+    ) {
+    }
+
+    /**
+     * @return array<string, string>
+     */
+    public function getData(): array
+    {
+        return [
+            'id' => $this->jarId,
+            'sn' => $this->snapshot,
+        ];
+    }
+}
@@ -17,6 +17,10 @@ readonly class RequestPayloadParameters implements PayloadParametersInterface
 {
     /**
      * @param array<string, array<int, string>> $headers
+     * @param array<int>                        $retryStatuses
+     * @param array<int, string>                $tlsCaFiles
+     * @param array<int, string>                $tlsPins
+     * @param array<int, string>                $noProxy
      */
     public function __construct(
         protected string $method,
@@ -41,6 +45,25 @@ readonly class RequestPayloadParameters implements PayloadParametersInterface
         protected string $sinkMode = '',
         protected int $sinkPerm = 0,
         protected int $downloadBufferSizeBytes = 0,
+        protected int $prefetchDepth = 0,
+        protected string $httpVersion = '',
+        protected int $retryMaxAttempts = 0,
+        protected int $retryBackoffMs = 0,
+        protected int $retryMaxBackoffMs = 0,
+        protected array $retryStatuses = [],
+        protected bool $retryNonIdempotent = false,
+        protected string $jarId = '',
+        protected array $tlsCaFiles = [],
+        protected string $tlsCaPem = '',
+        protected string $tlsCertFile = '',
+        protected string $tlsKeyFile = '',
+        protected string $tlsServerName = '',
+        protected string $tlsMinVersion = '',
+        protected array $tlsPins = [],
+        protected string $proxyUrl = '',
+        protected string $proxyUser = '',
+        protected string $proxyPassword = '',
+        protected array $noProxy = [],
     ) {
     }
 
@@ -63,7 +86,25 @@ readonly class RequestPayloadParameters implements PayloadParametersInterface
             'fr'  => $this->followRedirects,
             'mr'  => $this->maxRedirects,
             'cs'  => $this->chunkSize,
+            'pf'  => $this->prefetchDepth,
             'vt'  => $this->verifyTls,
+            'hv'  => $this->httpVersion,
+            'tcf' => $this->tlsCaFiles,
+            'tcp' => $this->tlsCaPem,
+            'tcr' => $this->tlsCertFile,
+            'tky' => $this->tlsKeyFile,
+            'tsn' => $this->tlsServerName,
+            'tmv' => $this->tlsMinVersion,
+            'tpn' => $this->tlsPins,
+            'px'  => $this->proxyUrl,
+            'pxu' => $this->proxyUser,
+            'pxp' => $this->proxyPassword,
+            'npx' => $this->noProxy,
+            'rma' => $this->retryMaxAttempts,
+            'rbo' => $this->retryBackoffMs,
+            'rmb' => $this->retryMaxBackoffMs,
+            'rst' => $this->retryStatuses,
+            'rni' => $this->retryNonIdempotent,
             'mic' => $this->maxIdleConns,
             'mih' => $this->maxIdleConnsPerHost,
             'ict' => $this->idleConnTimeoutMs,
@@ -72,6 +113,7 @@ readonly class RequestPayloadParameters implements PayloadParametersInterface
             'sm'  => $this->sinkMode,
             'spm' => $this->sinkPerm,
             'dbs' => $this->downloadBufferSizeBytes,
+            'jr'  => $this->jarId,
         ];
     }
 }
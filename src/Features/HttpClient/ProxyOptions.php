@@ -0,0 +1,34 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+/**
+ * Explicit proxy of HttpClient (HttpClientOptions::$proxy), replacing the
+ * HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment. It is part of the Go-side
+ * transport identity, so every distinct proxy keeps its own connection pool. A
+ * failure of the proxy hop (unreachable, credentials refused, tunnel refused)
+ * surfaces as a ProxyException, apart from failures of the target.
+ *
+ * Go: proxySettings (ext/internal/features/httpclient/proxy.go).
+ */
+readonly class ProxyOptions
+{
+    /**
+     * @param string             $url      proxy URL: http://, https://, socks5:// (the target is resolved
+     *                                     locally) or socks5h:// (the proxy resolves it); may carry user:pass@
+     * @param string             $username proxy user; overrides the credentials of $url, so a password needs
+     *                                     no URL escaping
+     * @param string             $password proxy password of $username
+     * @param array<int, string> $noProxy  hosts sent direct: "*", an IP, a CIDR range, or a domain matching
+     *                                     itself and its subdomains; any entry may end with ":port"
+     */
+    public function __construct(
+        public string $url,
+        public string $username = '',
+        public string $password = '',
+        public array $noProxy = [],
+    ) {
+    }
+}
@@ -0,0 +1,36 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+/**
+ * When and how HttpClient resends a failed request (HttpClientOptions::$retry).
+ * A request is retried on a network error or timeout or on one of
+ * $retryStatuses, after an exponential backoff with jitter (or the response's
+ * Retry-After), until $maxAttempts is reached. The whole sequence stays within
+ * requestTimeoutMs. A TLS, pin or proxy-credentials failure is not retried.
+ *
+ * Only idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT, DELETE) are retried
+ * unless $retryNonIdempotent is set; a streamed request body is never retried.
+ *
+ * Go: retryPolicy (ext/internal/features/httpclient/retry.go).
+ */
+readonly class RetryPolicy
+{
+    /**
+     * @param int        $maxAttempts        attempts in total, the first one included; 1 disables retries, at most 10
+     * @param int        $backoffMs          delay before the first retry, doubled for each next one
+     * @param int        $maxBackoffMs       cap of a single delay; a longer Retry-After ends the retries
+     * @param array<int> $retryStatuses      response statuses that are retried
+     * @param bool       $retryNonIdempotent retry POST, PATCH and the like too (only when the server dedupes them)
+     */
+    public function __construct(
+        public int $maxAttempts = 3,
+        public int $backoffMs = 100,
+        public int $maxBackoffMs = 5_000,
+        public array $retryStatuses = [429, 502, 503, 504],
+        public bool $retryNonIdempotent = false,
+    ) {
+    }
+}
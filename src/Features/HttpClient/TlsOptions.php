@@ -0,0 +1,40 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+/**
+ * Client TLS settings of HttpClient (HttpClientOptions::$tls): a private CA, a
+ * client certificate for mTLS, an SNI override, a minimum version and public-key
+ * pins. They are part of the Go-side transport identity, so every distinct set
+ * keeps its own connection pool. Files are read once, when that pool is built;
+ * a file that cannot be loaded fails the request with a RequestException.
+ *
+ * Go: tlsSettings (ext/internal/features/httpclient/tls.go).
+ */
+readonly class TlsOptions
+{
+    /**
+     * @param array<int, string> $caFiles          CA bundle files (PEM); with $caPem they replace the system roots
+     * @param string             $caPem            CA certificates inline (PEM)
+     * @param string             $certFile         client certificate file (PEM), presented when the server asks
+     * @param string             $keyFile          private key file (PEM) of $certFile; both or neither
+     * @param string             $serverName       name sent as SNI and verified against the certificate instead
+     *                                             of the URL host; empty uses the host
+     * @param TlsVersion|null    $minVersion       lowest accepted version; null keeps the Go default (TLS 1.2)
+     * @param array<int, string> $pinnedPublicKeys base64 SHA-256 hashes of a certificate's SPKI, optionally
+     *                                             "sha256/"-prefixed; one certificate of the server chain must
+     *                                             match. Enforced even when verifyTls is false
+     */
+    public function __construct(
+        public array $caFiles = [],
+        public string $caPem = '',
+        public string $certFile = '',
+        public string $keyFile = '',
+        public string $serverName = '',
+        public ?TlsVersion $minVersion = null,
+        public array $pinnedPublicKeys = [],
+    ) {
+    }
+}
@@ -0,0 +1,21 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\HttpClient;
+
+/**
+ * The lowest TLS version HttpClient accepts from a server (TlsOptions::$minVersion).
+ *
+ * Go: tlsVersions (ext/internal/features/httpclient/tls.go).
+ */
+enum TlsVersion: string
+{
+    case Tls10 = '1.0';
+
+    case Tls11 = '1.1';
+
+    case Tls12 = '1.2';
+
+    case Tls13 = '1.3';
+}
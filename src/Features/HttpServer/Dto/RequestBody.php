@@ -17,8 +17,10 @@ use SConcur\Features\FeatureExecutor;
  */
 class RequestBody
 {
-    /** Marker the Go side returns when the body exceeds maxRequestBody. */
-    private const string TOO_LARGE_MARKER = 'request body too large';
+    /** Error code the Go side returns when the body exceeds maxRequestBody. */
+    private const string TOO_LARGE_CODE = 'body_too_large';
+
+    private const string TOO_LARGE_MESSAGE = 'request body too large';
 
     /** Bytes already pulled from the source but not yet returned by read(). */
     private string $buffer = '';
@@ -129,9 +131,9 @@ class RequestBody
         try {
             return FeatureExecutor::next(taskKey: $this->bodyKey);
         } catch (TaskErrorException $exception) {
-            if ($exception->getMessage() === self::TOO_LARGE_MARKER) {
+            if ($exception->getError()?->code === self::TOO_LARGE_CODE) {
                 throw new RequestBodyTooLargeException(
-                    message: self::TOO_LARGE_MARKER,
+                    message: self::TOO_LARGE_MESSAGE,
                     previous: $exception,
                 );
             }
@@ -18,4 +18,8 @@ enum MethodEnum: string
     case WsServe   = 'wss';
     case WsRespond = 'wsr';
     case WsClient  = 'wsc';
+    case Ticker    = 'tk';
+    case Cron      = 'cr';
+    case Channel   = 'chn';
+    case Semaphore = 'sem';
 }
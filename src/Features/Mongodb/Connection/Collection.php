@@ -145,13 +145,14 @@ readonly class Collection
      *
      * @return Iterator<int, array<int|string|float|bool|null, mixed>>
      */
-    public function aggregate(array $pipeline, int $batchSize = 50): Iterator
+    public function aggregate(array $pipeline, int $batchSize = 50, int $prefetchDepth = 0): Iterator
     {
         return new IteratorResult(
             payload: new AggregatePayload(
                 connection: $this->connection,
                 pipeline: $pipeline,
                 batchSize: $batchSize,
+                prefetchDepth: $prefetchDepth,
             ),
         );
     }
@@ -265,6 +266,7 @@ readonly class Collection
         int $batchSize = 50,
         array|string|null $hint = null,
         ?array $collation = null,
+        int $prefetchDepth = 0,
     ): Iterator {
         return new IteratorResult(
             payload: new FindPayload(
@@ -277,6 +279,7 @@ readonly class Collection
                 batchSize: $batchSize,
                 hint: $hint,
                 collation: $collation,
+                prefetchDepth: $prefetchDepth,
             ),
         );
     }
@@ -21,6 +21,7 @@ readonly class AggregatePayload extends BaseMongodbPayload
         public Connection $connection,
         public array $pipeline,
         public int $batchSize,
+        public int $prefetchDepth = 0,
     ) {
     }
 
@@ -40,6 +41,7 @@ readonly class AggregatePayload extends BaseMongodbPayload
             payload: new AggregatePayloadParameters(
                 pipeline: $this->pipeline,
                 batchSize: $this->batchSize,
+                prefetchDepth: $this->prefetchDepth,
             ),
             isObject: true,
         );
@@ -14,6 +14,7 @@ readonly class AggregatePayloadParameters implements PayloadParametersInterface
     public function __construct(
         private array $pipeline,
         private int $batchSize,
+        private int $prefetchDepth = 0,
     ) {
     }
 
@@ -22,6 +23,7 @@ readonly class AggregatePayloadParameters implements PayloadParametersInterface
         return [
             'p'  => $this->pipeline,
             'bs' => $this->batchSize,
+            'pf' => $this->prefetchDepth,
         ];
     }
 }
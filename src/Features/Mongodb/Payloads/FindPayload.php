@@ -31,6 +31,7 @@ readonly class FindPayload extends BaseMongodbPayload
         public int $batchSize = 50,
         public array|string|null $hint = null,
         public ?array $collation = null,
+        public int $prefetchDepth = 0,
     ) {
     }
 
@@ -56,6 +57,7 @@ readonly class FindPayload extends BaseMongodbPayload
                 batchSize: $this->batchSize,
                 hint: $this->hint,
                 collation: $this->collation,
+                prefetchDepth: $this->prefetchDepth,
             ),
             isObject: true,
         );
@@ -24,6 +24,7 @@ readonly class FindPayloadParameters implements PayloadParametersInterface
         private int $batchSize = 50,
         private array|string|null $hint = null,
         private ?array $collation = null,
+        private int $prefetchDepth = 0,
     ) {
     }
 
@@ -34,6 +35,7 @@ readonly class FindPayloadParameters implements PayloadParametersInterface
             'l'  => $this->limit,
             'sk' => $this->skip,
             'bs' => $this->batchSize,
+            'pf' => $this->prefetchDepth,
         ];
 
         if ($this->projection !== null) {
@@ -0,0 +1,49 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Semaphore;
+
+use Closure;
+
+/**
+ * A named mutex: a Semaphore with a single permit. lock() suspends the coroutine
+ * until the mutex is free; the returned Permit unlocks it.
+ */
+readonly class Mutex
+{
+    protected Semaphore $semaphore;
+
+    public function __construct(
+        public string $name,
+    ) {
+        $this->semaphore = new Semaphore(
+            name: $name,
+            permits: 1,
+        );
+    }
+
+    public static function create(string $name): self
+    {
+        return new self($name);
+    }
+
+    public function lock(): Permit
+    {
+        return $this->semaphore->acquire();
+    }
+
+    /**
+     * Runs $callback holding the lock, unlocked even when the callback throws.
+     *
+     * @template T
+     *
+     * @param Closure(): T $callback
+     *
+     * @return T
+     */
+    public function synchronized(Closure $callback): mixed
+    {
+        return $this->semaphore->withPermit($callback);
+    }
+}
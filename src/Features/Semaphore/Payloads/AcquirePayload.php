@@ -0,0 +1,36 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Semaphore\Payloads;
+
+use SConcur\Features\MethodEnum;
+use SConcur\Transport\PayloadInterface;
+
+/**
+ * Go: payloads.AcquirePayload (ext/internal/features/semaphore/payloads/payloads.go).
+ */
+readonly class AcquirePayload implements PayloadInterface
+{
+    public function __construct(
+        protected string $name,
+        protected int $permits,
+    ) {
+    }
+
+    public function getMethod(): MethodEnum
+    {
+        return MethodEnum::Semaphore;
+    }
+
+    /**
+     * @return array<string, int|string>
+     */
+    public function getData(): array
+    {
+        return [
+            'n'  => $this->name,
+            'pm' => $this->permits,
+        ];
+    }
+}
@@ -0,0 +1,60 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Semaphore;
+
+use SConcur\Features\FeatureExecutor;
+use SConcur\State;
+
+/**
+ * One permit of a Semaphore (or the lock of a Mutex), held on the Go side under the
+ * acquire task key until release(). If the holder never releases it, the permit
+ * returns to the semaphore when its flow is stopped (the WaitGroup finishes or is
+ * stopped); on the synchronous path the destructor does that.
+ */
+class Permit
+{
+    protected bool $released = false;
+
+    public function __construct(
+        public readonly string $semaphoreName,
+        protected readonly string $taskKey,
+    ) {
+    }
+
+    /**
+     * Returns the permit, waking the longest waiting acquire. Idempotent.
+     */
+    public function release(): void
+    {
+        if ($this->released) {
+            return;
+        }
+
+        $this->released = true;
+
+        FeatureExecutor::next(taskKey: $this->taskKey);
+    }
+
+    public function isReleased(): bool
+    {
+        return $this->released;
+    }
+
+    /**
+     * Dropped without release(): stop the held acquire flow on the synchronous path
+     * so the Go side returns the permit. No-op in async mode, where the permit goes
+     * back when the coroutine's flow stops.
+     */
+    public function __destruct()
+    {
+        if ($this->released) {
+            return;
+        }
+
+        $this->released = true;
+
+        State::releaseSyncTaskFlow($this->taskKey);
+    }
+}
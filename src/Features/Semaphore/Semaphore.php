@@ -0,0 +1,71 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Semaphore;
+
+use Closure;
+use SConcur\Features\FeatureExecutor;
+use SConcur\Features\Semaphore\Payloads\AcquirePayload;
+
+/**
+ * A named counting semaphore kept on the Go side and shared by every coroutine and
+ * flow of the worker: at most $permits holders at a time, e.g. "at most 8
+ * concurrent calls to the payment API". acquire() suspends the coroutine until a
+ * permit is free; waiters are served in arrival order. The task deadline and
+ * cancellation bound the wait. See docs/semaphore.md.
+ */
+readonly class Semaphore
+{
+    public function __construct(
+        public string $name,
+        public int $permits,
+    ) {
+    }
+
+    public static function create(string $name, int $permits): self
+    {
+        return new self(
+            name: $name,
+            permits: $permits,
+        );
+    }
+
+    /**
+     * Waits for a free permit and returns it; the caller must release() it.
+     */
+    public function acquire(): Permit
+    {
+        $result = FeatureExecutor::exec(
+            payload: new AcquirePayload(
+                name: $this->name,
+                permits: $this->permits,
+            ),
+        );
+
+        return new Permit(
+            semaphoreName: $this->name,
+            taskKey: $result->key,
+        );
+    }
+
+    /**
+     * Runs $callback holding a permit, released even when the callback throws.
+     *
+     * @template T
+     *
+     * @param Closure(): T $callback
+     *
+     * @return T
+     */
+    public function withPermit(Closure $callback): mixed
+    {
+        $permit = $this->acquire();
+
+        try {
+            return $callback();
+        } finally {
+            $permit->release();
+        }
+    }
+}
@@ -54,10 +54,12 @@ abstract readonly class Connection
     /**
      * Streams a SELECT result row by row (batched). Each row is an associative
      * array keyed by column name.
+     * A positive $prefetchDepth reads that many batches ahead in the background,
+     * so fetching the next batch overlaps with processing the current one.
      *
      * @param list<mixed> $bindings
      */
-    public function query(string $sql, array $bindings = [], int $batchSize = 50): RowsResult
+    public function query(string $sql, array $bindings = [], int $batchSize = 50, int $prefetchDepth = 0): RowsResult
     {
         return new RowsResult(
             payload: new QueryPayload(
@@ -66,6 +68,7 @@ abstract readonly class Connection
                 sql: $sql,
                 bindings: $bindings,
                 batchSize: $batchSize,
+                prefetchDepth: $prefetchDepth,
             ),
         );
     }
@@ -28,6 +28,7 @@ readonly class QueryPayload extends BaseSqlPayload
         protected array $bindings,
         protected string $transactionId = '',
         protected int $batchSize = 50,
+        protected int $prefetchDepth = 0,
     ) {
         parent::__construct(
             method: $method,
@@ -47,6 +48,7 @@ readonly class QueryPayload extends BaseSqlPayload
             'b'  => $this->bindings,
             'tx' => $this->transactionId,
             'bs' => $this->batchSize,
+            'pf' => $this->prefetchDepth,
         ];
     }
 }
@@ -40,7 +40,7 @@ class Transaction
     /**
      * @param list<mixed> $bindings
      */
-    public function query(string $sql, array $bindings = [], int $batchSize = 50): RowsResult
+    public function query(string $sql, array $bindings = [], int $batchSize = 50, int $prefetchDepth = 0): RowsResult
     {
         return new RowsResult(
             payload: new QueryPayload(
@@ -50,6 +50,7 @@ class Transaction
                 bindings: $bindings,
                 transactionId: $this->transactionId,
                 batchSize: $batchSize,
+                prefetchDepth: $prefetchDepth,
             ),
         );
     }
@@ -0,0 +1,23 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Ticker\Dto;
+
+/**
+ * One tick of a Ticker. $number is the 1-based schedule slot it fired for; $missed
+ * counts the slots skipped since the previous tick because the consumer fell
+ * behind (missed ticks are reported, never delivered in a burst).
+ *
+ * Go: payloads.Tick (ext/internal/features/ticker/payloads/payloads.go).
+ */
+readonly class TickDto
+{
+    public function __construct(
+        public int $number,
+        public int $missed,
+        public int $scheduledAtMs,
+        public int $firedAtMs,
+    ) {
+    }
+}
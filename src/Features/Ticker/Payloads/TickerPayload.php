@@ -0,0 +1,38 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Ticker\Payloads;
+
+use SConcur\Features\MethodEnum;
+use SConcur\Transport\PayloadInterface;
+
+/**
+ * Go: payloads.TickerPayload (ext/internal/features/ticker/payloads/payloads.go).
+ */
+readonly class TickerPayload implements PayloadInterface
+{
+    public function __construct(
+        protected int $periodMs,
+        protected ?int $initialDelayMs = null,
+        protected int $maxTicks = 0,
+    ) {
+    }
+
+    public function getMethod(): MethodEnum
+    {
+        return MethodEnum::Ticker;
+    }
+
+    /**
+     * @return array<string, int|null>
+     */
+    public function getData(): array
+    {
+        return [
+            'pm' => $this->periodMs,
+            'dm' => $this->initialDelayMs,
+            'mt' => $this->maxTicks,
+        ];
+    }
+}
@@ -0,0 +1,123 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Ticker\Results;
+
+use Iterator;
+use SConcur\Dto\TaskResultDto;
+use SConcur\Features\FeatureExecutor;
+use SConcur\Features\Ticker\Dto\TickDto;
+use SConcur\State;
+use SConcur\Transport\MessagePackTransport;
+use SConcur\Transport\PayloadInterface;
+
+/**
+ * The ticks of a Ticker: each one is pulled from the Go side on demand
+ * (FeatureExecutor::next), suspending the coroutine until the next schedule slot.
+ * Keys are the tick numbers. Breaking out of the loop stops the ticker.
+ *
+ * @implements Iterator<int, TickDto>
+ */
+class TickResult implements Iterator
+{
+    protected ?string $taskKey;
+    protected ?TickDto $currentTick;
+    protected bool $isLastTick;
+    protected bool $isFinished;
+
+    public function __construct(
+        protected PayloadInterface $payload,
+    ) {
+        $this->resetProperties();
+    }
+
+    public function current(): ?TickDto
+    {
+        return $this->currentTick;
+    }
+
+    public function next(): void
+    {
+        if ($this->isFinished) {
+            return;
+        }
+
+        if ($this->isLastTick) {
+            $this->isFinished  = true;
+            $this->currentTick = null;
+
+            return;
+        }
+
+        $this->setTaskResult(
+            FeatureExecutor::next(
+                taskKey: $this->taskKey,
+            ),
+        );
+    }
+
+    public function key(): ?int
+    {
+        return $this->currentTick?->number;
+    }
+
+    public function valid(): bool
+    {
+        return $this->isFinished === false;
+    }
+
+    public function rewind(): void
+    {
+        $this->releaseTask();
+
+        $this->resetProperties();
+
+        $taskResult = FeatureExecutor::exec(
+            payload: $this->payload,
+        );
+
+        $this->taskKey = $taskResult->key;
+
+        $this->setTaskResult($taskResult);
+    }
+
+    protected function setTaskResult(TaskResultDto $taskResult): void
+    {
+        /** @var array{n: int, ms: int, sa: int, fa: int} $tick */
+        $tick = MessagePackTransport::unpack($taskResult->payload);
+
+        $this->isLastTick  = !$taskResult->hasNext;
+        $this->currentTick = new TickDto(
+            number: $tick['n'],
+            missed: $tick['ms'],
+            scheduledAtMs: $tick['sa'],
+            firedAtMs: $tick['fa'],
+        );
+    }
+
+    protected function resetProperties(): void
+    {
+        $this->taskKey     = null;
+        $this->currentTick = null;
+        $this->isLastTick  = false;
+        $this->isFinished  = false;
+    }
+
+    /**
+     * Releases the synchronous flow owning the ticker when the iterator is
+     * abandoned before its last tick (early break). No-op in async mode and after
+     * normal completion.
+     */
+    protected function releaseTask(): void
+    {
+        if ($this->taskKey !== null) {
+            State::releaseSyncTaskFlow($this->taskKey);
+        }
+    }
+
+    public function __destruct()
+    {
+        $this->releaseTask();
+    }
+}
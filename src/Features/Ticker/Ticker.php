@@ -0,0 +1,33 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Features\Ticker;
+
+use SConcur\Features\Ticker\Payloads\TickerPayload;
+use SConcur\Features\Ticker\Results\TickResult;
+
+/**
+ * Interval timers kept on the Go side: the schedule is anchored to the first tick,
+ * so a periodic job (cache refresh, heartbeat) does not drift by the time its own
+ * work takes, unlike a loop of Sleeper::usleep(). Ticks the consumer was too late
+ * for are skipped and reported in TickDto::$missed.
+ */
+class Ticker
+{
+    /**
+     * @param int      $periodMs       interval between ticks
+     * @param int|null $initialDelayMs delay before the first tick; 0 ticks at once, null waits one period
+     * @param int      $maxTicks       ticks in total (missed ones included); 0 ticks until the loop is left
+     */
+    public static function every(int $periodMs, ?int $initialDelayMs = null, int $maxTicks = 0): TickResult
+    {
+        return new TickResult(
+            payload: new TickerPayload(
+                periodMs: $periodMs,
+                initialDelayMs: $initialDelayMs,
+                maxTicks: $maxTicks,
+            ),
+        );
+    }
+}
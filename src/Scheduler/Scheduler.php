@@ -9,6 +9,7 @@ use Fiber;
 use SConcur\Connection\Extension;
 use SConcur\Dto\PendingNextDto;
 use SConcur\Dto\PendingPushDto;
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Dto\TaskResultDto;
 use SConcur\Exceptions\CallbackExecutionException;
 use SConcur\Exceptions\FiberStateException;
@@ -355,6 +356,7 @@ class Scheduler
                         if ($result->isError) {
                             throw new TaskErrorException(
                                 message: "http server stopped with error: {$result->payload}",
+                                error: $result->error,
                             );
                         }
 
@@ -394,7 +396,11 @@ class Scheduler
             }
         } finally {
             // Stop the listener and abort any connections not yet answered.
-            Extension::get()->stopFlow($serverFlowKey);
+            Extension::get()->stopFlow(
+                flowKey: $serverFlowKey,
+                reasonCode: TaskErrorDto::CODE_SHUTDOWN,
+                reasonMessage: 'server stopped',
+            );
 
             $onShutdownStep('stopped');
         }
@@ -445,6 +451,26 @@ class Scheduler
         }
     }
 
+    /**
+     * Non-blocking scheduler step for an external event loop: resumes the
+     * coroutines of every result ready right now (up to $max) and returns how many
+     * it resumed. Call it when Extension::readinessStream() turns readable;
+     * coroutines are started with spawn().
+     */
+    public function pump(int $max = 64): int
+    {
+        $results = Extension::get()->waitMany(
+            max: $max,
+            timeoutMs: -1,
+        );
+
+        foreach ($results as $result) {
+            $this->resumeByResult($result);
+        }
+
+        return count($results);
+    }
+
     /**
      * One scheduler step: take the first ready result of any flow and resume the
      * coroutine it belongs to.
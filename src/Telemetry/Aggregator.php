@@ -6,6 +6,7 @@ namespace SConcur\Telemetry;
 
 use SConcur\Telemetry\Dto\Aggregate;
 use SConcur\Telemetry\Dto\Connections;
+use SConcur\Telemetry\Dto\Crashes;
 use SConcur\Telemetry\Dto\MasterInfo;
 use SConcur\Telemetry\Dto\Memory;
 use SConcur\Telemetry\Dto\Requests;
@@ -47,6 +48,11 @@ class Aggregator
         $cpuPercent        = 0.0;
         $goroutines        = 0;
 
+        $crashesTotal    = 0;
+        $crashesTask     = 0;
+        $crashesServer   = 0;
+        $crashesLastAtMs = 0;
+
         $hasRequests     = false;
         $completed       = 0;
         $weightedAvgMs   = 0.0;
@@ -74,6 +80,11 @@ class Aggregator
             $cpuPercent += $snapshot->cpuPercent;
             $goroutines += $snapshot->goroutines;
 
+            $crashesTotal += $snapshot->crashes->total;
+            $crashesTask += $snapshot->crashes->task;
+            $crashesServer += $snapshot->crashes->server;
+            $crashesLastAtMs = max($crashesLastAtMs, $snapshot->crashes->lastAtMs);
+
             if ($snapshot->requests !== null) {
                 $hasRequests = true;
                 $completed += $snapshot->requests->completed;
@@ -101,6 +112,7 @@ class Aggregator
                 goroutines: $snapshot->goroutines,
                 requests: $snapshot->requests,
                 connections: $snapshot->connections,
+                crashes: $snapshot->crashes,
             );
         }
 
@@ -131,6 +143,12 @@ class Aggregator
             goroutines: $goroutines,
             requests: $totalsRequests,
             connections: $totalsConnections,
+            crashes: new Crashes(
+                total: $crashesTotal,
+                task: $crashesTask,
+                server: $crashesServer,
+                lastAtMs: $crashesLastAtMs,
+            ),
         );
 
         return new Aggregate(
@@ -0,0 +1,48 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Telemetry\Dto;
+
+/**
+ * Lifetime panic counters of one worker: task panics (a feature goroutine,
+ * answered with an error result) and server panics (a request or connection
+ * aborted). lastAtMs is the epoch-ms time of the latest one, 0 when none happened.
+ * Field names mirror the Go schema (ext/internal/crashes/crashes.go).
+ */
+readonly class Crashes
+{
+    public function __construct(
+        public int $total,
+        public int $task,
+        public int $server,
+        public int $lastAtMs,
+    ) {
+    }
+
+    /**
+     * @param array<string, mixed> $data
+     */
+    public static function fromArray(array $data): self
+    {
+        return new self(
+            total: (int) ($data['total'] ?? 0),
+            task: (int) ($data['task'] ?? 0),
+            server: (int) ($data['server'] ?? 0),
+            lastAtMs: (int) ($data['lastAtMs'] ?? 0),
+        );
+    }
+
+    /**
+     * @return array<string, int>
+     */
+    public function toArray(): array
+    {
+        return [
+            'total'    => $this->total,
+            'task'     => $this->task,
+            'server'   => $this->server,
+            'lastAtMs' => $this->lastAtMs,
+        ];
+    }
+}
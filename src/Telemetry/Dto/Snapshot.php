@@ -7,7 +7,8 @@ namespace SConcur\Telemetry\Dto;
 /**
  * One worker's statistics as pushed over the telemetry socket (the "s" field of a
  * snapshot frame). Exactly one workload section is set: requests (HTTP) or
- * connections (socket). Field names mirror the Go schema
+ * connections (socket). crashes counts the panics the worker recovered; a
+ * worker that predates it reports zeros. Field names mirror the Go schema
  * (ext/internal/stats/snapshot.go).
  */
 readonly class Snapshot
@@ -23,6 +24,7 @@ readonly class Snapshot
         public int $goroutines,
         public ?Requests $requests,
         public ?Connections $connections,
+        public Crashes $crashes,
     ) {
     }
 
@@ -57,6 +59,7 @@ readonly class Snapshot
             goroutines: (int) ($data['goroutines'] ?? 0),
             requests: is_array($data['requests'] ?? null) ? Requests::fromArray($data['requests']) : null,
             connections: is_array($data['connections'] ?? null) ? Connections::fromArray($data['connections']) : null,
+            crashes: Crashes::fromArray(is_array($data['crashes'] ?? null) ? $data['crashes'] : []),
         );
     }
 }
@@ -6,7 +6,8 @@ namespace SConcur\Telemetry\Dto;
 
 /**
  * Pool-wide sum. cpuPercent is the sum of per-process percentages (so it may exceed
- * 100%); requests->avgMs is weighted by each worker's completed count. Only the
+ * 100%); requests->avgMs is weighted by each worker's completed count; crashes
+ * sums the counters, with the latest lastAtMs of the pool. Only the
  * workload section present in the pool's snapshots is filled.
  */
 readonly class Totals
@@ -17,6 +18,7 @@ readonly class Totals
         public int $goroutines,
         public ?Requests $requests,
         public ?Connections $connections,
+        public Crashes $crashes,
     ) {
     }
 
@@ -29,6 +31,7 @@ readonly class Totals
             'memory'     => $this->memory->toArray(),
             'cpuPercent' => $this->cpuPercent,
             'goroutines' => $this->goroutines,
+            'crashes'    => $this->crashes->toArray(),
         ];
 
         if ($this->requests !== null) {
@@ -21,6 +21,7 @@ readonly class WorkerEntry
         public int $goroutines,
         public ?Requests $requests,
         public ?Connections $connections,
+        public Crashes $crashes,
     ) {
     }
 
@@ -38,6 +39,7 @@ readonly class WorkerEntry
             'memory'        => $this->memory->toArray(),
             'cpuPercent'    => $this->cpuPercent,
             'goroutines'    => $this->goroutines,
+            'crashes'       => $this->crashes->toArray(),
         ];
 
         if ($this->requests !== null) {
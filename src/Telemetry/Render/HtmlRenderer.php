@@ -81,7 +81,7 @@ class HtmlRenderer
 <table>
 <caption>Totals</caption>
 <tr>
-<th>RSS, MiB</th><th>Go runtime, MiB</th><th>non-ext, MiB</th><th>CPU %</th><th>goroutines</th>
+<th>RSS, MiB</th><th>Go runtime, MiB</th><th>non-ext, MiB</th><th>CPU %</th><th>goroutines</th><th>panics</th>
 ' . $workloadTotalsHead . '
 </tr>
 <tr>
@@ -90,6 +90,7 @@ class HtmlRenderer
 <td>' . $this->mib($totals->memory->nonExtensionBytes) . '</td>
 <td>' . $this->f1($totals->cpuPercent) . '</td>
 <td>' . $totals->goroutines . '</td>
+<td>' . $totals->crashes->total . '</td>
 ' . $workloadTotalsRow . '
 </tr>
 </table>';
@@ -112,7 +113,7 @@ class HtmlRenderer
 <table>
 <caption>Workers</caption>
 <tr>
-<th>pid</th><th>started (UTC)</th><th>uptime s</th><th>snap age ms</th><th>CPU %</th><th>RSS, MiB</th><th>goroutines</th>
+<th>pid</th><th>started (UTC)</th><th>uptime s</th><th>snap age ms</th><th>CPU %</th><th>RSS, MiB</th><th>goroutines</th><th>panics</th>
 ' . $workloadWorkersHead . '
 </tr>' . $rows . '
 </table>
@@ -170,6 +171,7 @@ class HtmlRenderer
 <td>' . $this->f1($worker->cpuPercent) . '</td>
 <td>' . $this->mib($worker->memory->rssBytes) . '</td>
 <td>' . $worker->goroutines . '</td>
+<td>' . $worker->crashes->total . '</td>
 ' . $workload . '
 </tr>';
     }
@@ -37,6 +37,7 @@ class PrometheusRenderer
         $output .= $this->family('sconcur_pool_memory_non_extension_bytes', 'Pool memory outside the extension (PHP interpreter).', 'gauge', $poolLabels, (string) $totals->memory->nonExtensionBytes);
         $output .= $this->family('sconcur_pool_cpu_percent', 'Pool CPU usage (sum of per-process percentages).', 'gauge', $poolLabels, $this->float($totals->cpuPercent));
         $output .= $this->family('sconcur_pool_goroutines', 'Pool goroutine count.', 'gauge', $poolLabels, (string) $totals->goroutines);
+        $output .= $this->family('sconcur_pool_panics_total', 'Panics recovered across the pool (task and server).', 'counter', $poolLabels, (string) $totals->crashes->total);
 
         $master = $aggregate->master;
 
@@ -85,6 +86,7 @@ class PrometheusRenderer
             ['sconcur_worker_memory_rss_bytes', 'Worker resident set size (with the extension).', fn(WorkerEntry $worker): string => (string) $worker->memory->rssBytes],
             ['sconcur_worker_memory_go_runtime_bytes', 'Worker Go-runtime memory footprint.', fn(WorkerEntry $worker): string => (string) $worker->memory->goRuntimeBytes],
             ['sconcur_worker_memory_non_extension_bytes', 'Worker memory outside the extension (PHP interpreter).', fn(WorkerEntry $worker): string => (string) $worker->memory->nonExtensionBytes],
+            ['sconcur_worker_last_panic_time_seconds', 'Latest panic recovered by the worker (unix seconds, 0 if none).', fn(WorkerEntry $worker): string => (string) intdiv($worker->crashes->lastAtMs, 1000)],
         ];
 
         foreach ($processMetrics as [$metricName, $help, $value]) {
@@ -95,6 +97,21 @@ class PrometheusRenderer
             }
         }
 
+        /** @var array<int, array{0: string, 1: string, 2: callable(WorkerEntry): string}> $panicMetrics */
+        $panicMetrics = [
+            ['sconcur_worker_panics_total', 'Panics recovered by the worker (task and server).', fn(WorkerEntry $worker): string => (string) $worker->crashes->total],
+            ['sconcur_worker_task_panics_total', 'Panics recovered in feature goroutines (answered as an error result).', fn(WorkerEntry $worker): string => (string) $worker->crashes->task],
+            ['sconcur_worker_server_panics_total', 'Panics recovered in server goroutines (request or connection aborted).', fn(WorkerEntry $worker): string => (string) $worker->crashes->server],
+        ];
+
+        foreach ($panicMetrics as [$metricName, $help, $value]) {
+            $output .= $this->header($metricName, $help, 'counter');
+
+            foreach ($aggregate->workers as $worker) {
+                $output .= $metricName . $this->workerLabels($name, $worker->pid) . ' ' . $value($worker) . "\n";
+            }
+        }
+
         if ($aggregate->totals->requests !== null) {
             $output .= $this->workerRequests($aggregate, $name);
         }
@@ -6,7 +6,9 @@ namespace SConcur\Tests\Feature;
 
 use PHPUnit\Framework\TestCase;
 use SConcur\Connection\Extension;
+use SConcur\Exceptions\TaskErrorException;
 use SConcur\Tests\Impl\TestApplication;
+use Throwable;
 
 abstract class BaseTestCase extends TestCase
 {
@@ -50,4 +52,21 @@ abstract class BaseTestCase extends TestCase
             $this->extension->count(),
         );
     }
+
+    /**
+     * Asserts the structured task error somewhere in the exception chain has the
+     * given category (TaskErrorDto::CATEGORY_*).
+     */
+    protected static function assertTaskErrorCategory(string $category, Throwable $exception): void
+    {
+        for ($current = $exception; $current !== null; $current = $current->getPrevious()) {
+            if ($current instanceof TaskErrorException && $current->getError() !== null) {
+                self::assertSame($category, $current->getError()->category, $current->getMessage());
+
+                return;
+            }
+        }
+
+        self::fail('Expected a task error in the chain, got: ' . $exception->getMessage());
+    }
 }
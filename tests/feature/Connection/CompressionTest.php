@@ -0,0 +1,90 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Dto\RunningTaskDto;
+use SConcur\Exceptions\ExtensionCallException;
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class CompressionTest extends BaseTestCase
+{
+    protected function tearDown(): void
+    {
+        if (extension_loaded('zstd')) {
+            $this->extension->setCompression(0);
+        }
+
+        parent::tearDown();
+    }
+
+    public function testCompressedPushIsDecodedOnTheGoSide(): void
+    {
+        if (!extension_loaded('zstd')) {
+            self::markTestSkipped('The "zstd" extension is not loaded.');
+        }
+
+        $this->extension->setCompression(1);
+
+        $flowKey = uniqid();
+
+        $task = $this->extension->push(
+            flowKey: $flowKey,
+            payload: new SleeperPayload(microseconds: 1_000),
+        );
+
+        $result = $this->extension->wait($flowKey);
+
+        $this->extension->stopFlow($flowKey);
+
+        self::assertSame($task->key, $result->key);
+        self::assertFalse($result->isError);
+    }
+
+    public function testCompressedPushManyIsDecodedOnTheGoSide(): void
+    {
+        if (!extension_loaded('zstd')) {
+            self::markTestSkipped('The "zstd" extension is not loaded.');
+        }
+
+        $this->extension->setCompression(1);
+
+        $flowKey = uniqid();
+
+        $outcomes = $this->extension->pushMany(
+            flowKey: $flowKey,
+            payloads: [
+                new SleeperPayload(microseconds: 1_000),
+                new SleeperPayload(microseconds: 1_000),
+            ],
+        );
+
+        $results = [];
+
+        while (count($results) < count($outcomes)) {
+            foreach ($this->extension->waitMany(max: 10, timeoutMs: 1_000) as $result) {
+                $results[$result->key] = $result;
+            }
+        }
+
+        $this->extension->stopFlow($flowKey);
+
+        foreach ($outcomes as $outcome) {
+            self::assertInstanceOf(RunningTaskDto::class, $outcome);
+            self::assertFalse($results[$outcome->key]->isError);
+        }
+    }
+
+    public function testSetCompressionWithoutZstdThrows(): void
+    {
+        if (extension_loaded('zstd')) {
+            self::markTestSkipped('The "zstd" extension is loaded.');
+        }
+
+        $this->expectException(ExtensionCallException::class);
+
+        $this->extension->setCompression(1024);
+    }
+}
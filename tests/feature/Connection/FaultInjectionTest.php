@@ -0,0 +1,100 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Connection\Faults\FaultRule;
+use SConcur\Dto\TaskErrorDto;
+use SConcur\Dto\TaskResultDto;
+use SConcur\Exceptions\ExtensionCallException;
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class FaultInjectionTest extends BaseTestCase
+{
+    protected function tearDown(): void
+    {
+        $this->extension->setFaults([]);
+
+        parent::tearDown();
+    }
+
+    public function testErrorRuleFailsEveryNthPushWithTheChosenCategory(): void
+    {
+        $this->extension->setFaults([
+            FaultRule::error(target: 'sl', category: TaskErrorDto::CATEGORY_NETWORK, every: 2),
+        ]);
+
+        $first  = $this->sleep();
+        $second = $this->sleep();
+
+        self::assertFalse($first->isError);
+        self::assertTrue($second->isError);
+        self::assertSame(TaskErrorDto::CATEGORY_NETWORK, $second->error?->category);
+        self::assertTrue($second->error->retryable);
+    }
+
+    public function testDroppedResultIsAnsweredByTheTaskDeadline(): void
+    {
+        $this->extension->setFaults([
+            FaultRule::drop(target: 'sl'),
+        ]);
+
+        $result = $this->sleep(timeoutMs: 50);
+
+        self::assertTrue($result->isError);
+        self::assertSame(TaskErrorDto::CATEGORY_TIMEOUT, $result->error?->category);
+    }
+
+    public function testPanicRuleIsAnsweredWithAPanicError(): void
+    {
+        $this->extension->setFaults([
+            FaultRule::panic(target: 'sl'),
+        ]);
+
+        $result = $this->sleep();
+
+        self::assertTrue($result->isError);
+        self::assertSame(TaskErrorDto::CATEGORY_PANIC, $result->error?->category);
+    }
+
+    public function testLatencyRuleDelaysThePush(): void
+    {
+        $this->extension->setFaults([
+            FaultRule::latency(target: 'sl', ms: 50),
+        ]);
+
+        $start  = microtime(true);
+        $result = $this->sleep();
+
+        self::assertFalse($result->isError);
+        self::assertGreaterThanOrEqual(0.05, microtime(true) - $start);
+    }
+
+    public function testInvalidRuleIsRejected(): void
+    {
+        $this->expectException(ExtensionCallException::class);
+
+        $this->extension->setFaults([
+            FaultRule::error(target: 'sl', category: 'cosmic'),
+        ]);
+    }
+
+    private function sleep(int $timeoutMs = 0): TaskResultDto
+    {
+        $flowKey = uniqid();
+
+        $this->extension->push(
+            flowKey: $flowKey,
+            payload: new SleeperPayload(microseconds: 1_000),
+            timeoutMs: $timeoutMs,
+        );
+
+        $result = $this->extension->wait($flowKey);
+
+        $this->extension->stopFlow($flowKey);
+
+        return $result;
+    }
+}
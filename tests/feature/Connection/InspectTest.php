@@ -0,0 +1,41 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Features\MethodEnum;
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class InspectTest extends BaseTestCase
+{
+    public function testListsLiveFlowWithItsUndeliveredTask(): void
+    {
+        $flowKey = uniqid();
+
+        $runningTask = $this->extension->push(
+            flowKey: $flowKey,
+            payload: new SleeperPayload(microseconds: 200_000),
+        );
+
+        $inspection = $this->extension->inspect();
+
+        $flows = array_values(
+            array_filter(
+                $inspection['flows'],
+                static fn(array $flow): bool => $flow['key'] === $flowKey,
+            )
+        );
+
+        self::assertCount(1, $flows);
+        self::assertSame(1, $flows[0]['tasksCount']);
+        self::assertSame($runningTask->key, $flows[0]['tasks'][0]['taskKey']);
+        self::assertSame(MethodEnum::Sleep->value, $flows[0]['tasks'][0]['method']);
+        self::assertFalse($flows[0]['tasks'][0]['isNext']);
+        self::assertSame(1024, $inspection['resultsCapacity']);
+
+        $this->extension->waitAny();
+        $this->extension->stopFlow($flowKey);
+    }
+}
@@ -0,0 +1,79 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Dto\RunningTaskDto;
+use SConcur\Features\MethodEnum;
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+use SConcur\Transport\MessagePackTransport;
+use function SConcur\Extension\pushMany;
+
+class PushManyTest extends BaseTestCase
+{
+    public function testSubmitsEveryTaskInOneCall(): void
+    {
+        $flowKey = uniqid();
+
+        $outcomes = $this->extension->pushMany(
+            flowKey: $flowKey,
+            payloads: [
+                new SleeperPayload(microseconds: 1_000),
+                new SleeperPayload(microseconds: 1_000),
+                new SleeperPayload(microseconds: 1_000),
+            ],
+        );
+
+        self::assertCount(3, $outcomes);
+        self::assertContainsOnlyInstancesOf(RunningTaskDto::class, $outcomes);
+
+        $results = [];
+
+        while (count($results) < 3) {
+            foreach ($this->extension->waitMany(max: 10, timeoutMs: 1_000) as $result) {
+                $results[$result->key] = $result;
+            }
+        }
+
+        foreach ($outcomes as $outcome) {
+            self::assertFalse($results[$outcome->key]->isError);
+        }
+
+        $this->extension->stopFlow($flowKey);
+    }
+
+    public function testRejectedMessageDoesNotStopTheRestOfTheBatch(): void
+    {
+        $flowKey = uniqid();
+        $payload = MessagePackTransport::pack(new SleeperPayload(microseconds: 1_000));
+
+        $batch = pack('N', 2);
+
+        foreach (['nope' => 'task-1', MethodEnum::Sleep->value => 'task-2'] as $method => $taskKey) {
+            $batch .= pack('CnNNC', strlen($method), strlen($taskKey), 0, strlen($payload), 0)
+                . $method
+                . $taskKey
+                . $payload;
+        }
+
+        $response = pushMany($flowKey, $batch);
+
+        $first = unpack('nlen', $response);
+
+        self::assertNotFalse($first);
+        self::assertStringContainsString('unknown method', substr($response, 2, $first['len']));
+
+        $second = unpack('nlen', $response, 2 + $first['len']);
+
+        self::assertNotFalse($second);
+        self::assertSame(0, $second['len'], 'the valid message must be accepted');
+
+        $result = $this->extension->waitAny();
+
+        self::assertSame('task-2', $result->key);
+
+        $this->extension->stopFlow($flowKey);
+    }
+}
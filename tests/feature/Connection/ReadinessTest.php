@@ -0,0 +1,77 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Features\Sleeper\Sleeper;
+use SConcur\Scheduler\Scheduler;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class ReadinessTest extends BaseTestCase
+{
+    public function testStreamIsReadableOnlyWhileAResultIsReady(): void
+    {
+        $stream = $this->extension->readinessStream();
+
+        self::assertSame($this->extension->readinessFd(), $this->extension->readinessFd());
+        self::assertSame([], $this->extension->waitMany(max: 10, timeoutMs: -1));
+        self::assertFalse($this->selectReadable($stream, timeoutUs: 0));
+
+        $flowKey = uniqid();
+
+        $this->extension->push(
+            flowKey: $flowKey,
+            payload: new SleeperPayload(microseconds: 1_000),
+        );
+
+        self::assertTrue($this->selectReadable($stream, timeoutUs: 1_000_000));
+
+        $results = $this->extension->waitMany(max: 10, timeoutMs: -1);
+
+        self::assertCount(1, $results);
+        self::assertSame($flowKey, $results[0]->flowKey);
+        self::assertFalse($this->selectReadable($stream, timeoutUs: 0));
+
+        $this->extension->stopFlow($flowKey);
+    }
+
+    public function testPumpDrivesSpawnedCoroutinesFromAnExternalLoop(): void
+    {
+        $stream = $this->extension->readinessStream();
+        $steps  = [];
+
+        Scheduler::get()->spawn(static function () use (&$steps) {
+            Sleeper::usleep(1_000);
+
+            $steps[] = 'first';
+
+            Sleeper::usleep(1_000);
+
+            $steps[] = 'second';
+        });
+
+        $deadline = microtime(true) + 2;
+
+        while (count($steps) < 2 && microtime(true) < $deadline) {
+            if ($this->selectReadable($stream, timeoutUs: 100_000)) {
+                Scheduler::get()->pump();
+            }
+        }
+
+        self::assertSame(['first', 'second'], $steps);
+    }
+
+    /**
+     * @param resource $stream
+     */
+    private function selectReadable($stream, int $timeoutUs): bool
+    {
+        $read   = [$stream];
+        $write  = null;
+        $except = null;
+
+        return stream_select($read, $write, $except, 0, $timeoutUs) > 0;
+    }
+}
@@ -0,0 +1,113 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Exceptions\ExtensionCallException;
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+use SConcur\Transport\MessagePackTransport;
+
+class RecordingTest extends BaseTestCase
+{
+    public function testRecordsPushesResultsAndStops(): void
+    {
+        $path = tempnam(sys_get_temp_dir(), 'sconcur-rec');
+
+        $this->extension->startRecording($path);
+
+        $flowKey = uniqid();
+
+        try {
+            $task = $this->extension->push(
+                flowKey: $flowKey,
+                payload: new SleeperPayload(microseconds: 1_000),
+            );
+
+            $result = $this->extension->wait($flowKey);
+
+            $this->extension->stopFlow($flowKey);
+        } finally {
+            $this->extension->stopRecording();
+        }
+
+        $entries = $this->readEntries($path);
+
+        unlink($path);
+
+        self::assertSame(['push', 'result', 'stop'], array_column($entries, 'k'));
+        self::assertSame([$flowKey, $flowKey, $flowKey], array_column($entries, 'fk'));
+        self::assertSame($task->key, $entries[0]['tk']);
+        self::assertSame($result->key, $entries[1]['tk']);
+    }
+
+    public function testRecordsTheStopReason(): void
+    {
+        $path = tempnam(sys_get_temp_dir(), 'sconcur-rec');
+
+        $this->extension->startRecording($path);
+
+        $flowKey = uniqid();
+
+        try {
+            $this->extension->push(
+                flowKey: $flowKey,
+                payload: new SleeperPayload(microseconds: 1_000_000),
+            );
+
+            $stopped = $this->extension->stopFlow(
+                flowKey: $flowKey,
+                reasonCode: 'user_abort',
+                reasonMessage: 'aborted by the user',
+            );
+        } finally {
+            $this->extension->stopRecording();
+        }
+
+        // The sleeping task's cancelled answer comes back from stopFlow itself.
+        self::assertCount(1, $stopped);
+        self::assertTrue($stopped[0]->isCancelled);
+        self::assertSame('user_abort', $stopped[0]->error?->code);
+        self::assertSame(0, $this->extension->count());
+
+        $entries = $this->readEntries($path);
+
+        unlink($path);
+
+        self::assertSame('stop', $entries[1]['k']);
+        self::assertSame('user_abort', $entries[1]['sc']);
+        self::assertSame('aborted by the user', $entries[1]['sm']);
+    }
+
+    public function testStartRecordingIntoAMissingDirectoryThrows(): void
+    {
+        $this->expectException(ExtensionCallException::class);
+
+        $this->extension->startRecording('/nonexistent-' . uniqid() . '/session.rec');
+    }
+
+    /**
+     * Decodes the frames of a recording: a big-endian uint32 length, then one
+     * MessagePack entry.
+     *
+     * @return array<int, array<string, mixed>>
+     */
+    private function readEntries(string $path): array
+    {
+        $data    = (string) file_get_contents($path);
+        $offset  = 0;
+        $entries = [];
+
+        while ($offset < strlen($data)) {
+            /** @var array{1: int} $header */
+            $header = unpack('N', $data, $offset);
+
+            $entries[] = MessagePackTransport::unpack(substr($data, $offset + 4, $header[1]));
+
+            $offset += 4 + $header[1];
+        }
+
+        return $entries;
+    }
+}
@@ -0,0 +1,62 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+use SConcur\Transport\MessagePackTransport;
+use function SConcur\Extension\createRuntime;
+use function SConcur\Extension\destroyRuntime;
+use function SConcur\Extension\push;
+use function SConcur\Extension\stopFlow;
+use function SConcur\Extension\tasksCount;
+use function SConcur\Extension\waitAnyTimeout;
+
+class RuntimeTest extends BaseTestCase
+{
+    public function testRuntimeDoesNotSeeAnotherRuntimeResults(): void
+    {
+        $first  = createRuntime();
+        $second = createRuntime();
+
+        try {
+            $payload = new SleeperPayload(microseconds: 1_000);
+
+            $response = push(
+                uniqid(),
+                $payload->getMethod()->value,
+                uniqid(),
+                MessagePackTransport::pack($payload),
+                0,
+                $first,
+            );
+
+            self::assertSame('', $response);
+            self::assertSame(1, tasksCount($first));
+            self::assertSame(0, tasksCount($second));
+
+            self::assertSame('timeout', waitAnyTimeout(100, $second));
+            self::assertNotSame('timeout', waitAnyTimeout(1_000, $first));
+        } finally {
+            self::assertSame('', destroyRuntime($first));
+            self::assertSame('', destroyRuntime($second));
+        }
+    }
+
+    public function testDestroyedRuntimeIsUnknown(): void
+    {
+        $runtime = createRuntime();
+
+        self::assertSame('', destroyRuntime($runtime));
+        self::assertStringStartsWith('error: destroyRuntime: unknown runtime', destroyRuntime($runtime));
+        self::assertStringStartsWith('error: waitAnyTimeout: unknown runtime', waitAnyTimeout(1, $runtime));
+        self::assertStringStartsWith('error: stopFlow: unknown runtime', stopFlow('flow', '', '', $runtime));
+    }
+
+    public function testDefaultRuntimeCannotBeDestroyedAlone(): void
+    {
+        self::assertStringStartsWith('error: destroyRuntime:', destroyRuntime(0));
+    }
+}
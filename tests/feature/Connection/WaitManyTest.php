@@ -0,0 +1,44 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Connection;
+
+use SConcur\Features\Sleeper\Payloads\SleeperPayload;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class WaitManyTest extends BaseTestCase
+{
+    public function testReturnsEmptyListWhenNothingIsReadyWithinTheTimeout(): void
+    {
+        self::assertSame([], $this->extension->waitMany(max: 10, timeoutMs: 50));
+    }
+
+    public function testDrainsReadyResultsUpToMaxInOneCall(): void
+    {
+        $flowKey = uniqid();
+
+        for ($index = 0; $index < 5; $index++) {
+            $this->extension->push(
+                flowKey: $flowKey,
+                payload: new SleeperPayload(microseconds: 1_000),
+            );
+        }
+
+        // Let every task publish so the batch sees them all ready.
+        usleep(50_000);
+
+        $first = $this->extension->waitMany(max: 3, timeoutMs: 1_000);
+        $rest  = $this->extension->waitMany(max: 10, timeoutMs: 1_000);
+
+        self::assertCount(3, $first);
+        self::assertCount(2, $rest);
+
+        foreach ([...$first, ...$rest] as $result) {
+            self::assertSame($flowKey, $result->flowKey);
+            self::assertFalse($result->isError);
+        }
+
+        $this->extension->stopFlow($flowKey);
+    }
+}
@@ -0,0 +1,73 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Channel;
+
+use SConcur\Dto\TaskErrorDto;
+use SConcur\Exceptions\Channel\ChannelClosedException;
+use SConcur\Exceptions\TaskErrorException;
+use SConcur\Features\Channel\Channel;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class ChannelCloseTest extends BaseTestCase
+{
+    public function testReceiveDrainsTheBufferAfterClose(): void
+    {
+        $channel = Channel::create('channel-close-drain', capacity: 2);
+
+        $channel->send(1);
+        $channel->send(2);
+        $channel->close();
+
+        self::assertSame([1, 2], iterator_to_array($channel, false));
+
+        $this->expectException(ChannelClosedException::class);
+
+        $channel->receive();
+    }
+
+    public function testSendOnClosedChannelThrows(): void
+    {
+        $channel = Channel::create('channel-close-send', capacity: 1);
+
+        $channel->close();
+
+        try {
+            $channel->send('late');
+
+            self::fail('send on a closed channel must throw');
+        } catch (ChannelClosedException $exception) {
+            self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_VALIDATION, $exception);
+        }
+    }
+
+    public function testCreateAfterCloseOpensAFreshChannel(): void
+    {
+        $channel = Channel::create('channel-close-reopen', capacity: 1);
+
+        $channel->send('stale');
+        $channel->close();
+
+        $reopened = Channel::create('channel-close-reopen', capacity: 3);
+
+        $reopened->send('fresh');
+
+        self::assertSame('fresh', $reopened->receive());
+
+        $reopened->close();
+    }
+
+    public function testCreateRejectsADifferentCapacity(): void
+    {
+        $channel = Channel::create('channel-close-capacity', capacity: 1);
+
+        try {
+            $this->expectException(TaskErrorException::class);
+
+            Channel::create('channel-close-capacity', capacity: 2);
+        } finally {
+            $channel->close();
+        }
+    }
+}
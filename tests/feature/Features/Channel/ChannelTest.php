@@ -0,0 +1,73 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Channel;
+
+use SConcur\Features\Channel\Channel;
+use SConcur\Tests\Feature\BaseAsyncTestCase;
+use Throwable;
+
+class ChannelTest extends BaseAsyncTestCase
+{
+    private const string NAME = 'channel-test-pipe';
+
+    /** @var array<int, mixed> */
+    private array $received = [];
+
+    protected function on_1_start(): void
+    {
+        $channel = Channel::create(self::NAME, capacity: 1);
+
+        // The second send waits for the consumer flow: the buffer holds one value.
+        $channel->send('a');
+        $channel->send('b');
+        $channel->send('c');
+    }
+
+    protected function on_1_middle(): void
+    {
+        $channel = Channel::create(self::NAME, capacity: 1);
+
+        $channel->send(['d' => 1]);
+        $channel->close();
+    }
+
+    protected function on_2_start(): void
+    {
+        $channel = Channel::create(self::NAME, capacity: 1);
+
+        for ($index = 0; $index < 3; $index++) {
+            $this->received[] = $channel->receive();
+        }
+    }
+
+    protected function on_2_middle(): void
+    {
+        foreach (Channel::create(self::NAME, capacity: 1) as $value) {
+            $this->received[] = $value;
+        }
+    }
+
+    protected function on_iterate(): void
+    {
+        //
+    }
+
+    protected function on_exception(): void
+    {
+        Channel::create('');
+    }
+
+    protected function assertException(Throwable $exception): void
+    {
+        self::assertTrue(str_contains($exception->getMessage(), 'channel:'));
+    }
+
+    protected function assertResult(array $results): void
+    {
+        self::assertSame(['a', 'b', 'c', ['d' => 1]], $this->received);
+
+        $this->received = [];
+    }
+}
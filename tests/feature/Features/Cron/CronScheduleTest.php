@@ -0,0 +1,53 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Cron;
+
+use SConcur\Dto\TaskErrorDto;
+use SConcur\Exceptions\TaskErrorException;
+use SConcur\Features\Cron\Cron;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class CronScheduleTest extends BaseTestCase
+{
+    public function testFiresAreNumberedOnTheSchedule(): void
+    {
+        $fires = [];
+
+        foreach (Cron::schedule(expression: '* * * * * *') as $number => $fire) {
+            $fires[$number] = $fire;
+
+            if (count($fires) === 2) {
+                break;
+            }
+        }
+
+        self::assertSame([1, 2], array_keys($fires));
+        self::assertSame(1000, $fires[2]->scheduledAtMs - $fires[1]->scheduledAtMs);
+        // tearDown's assertNoTasksCount verifies the abandoned schedule was released.
+    }
+
+    public function testUnknownTimeZoneIsAValidationError(): void
+    {
+        try {
+            foreach (Cron::schedule(expression: '0 * * * * *', timezone: 'Mars/Olympus') as $fire) {
+                //
+            }
+
+            self::fail('An unknown time zone must be rejected');
+        } catch (TaskErrorException $exception) {
+            self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_VALIDATION, $exception);
+        }
+    }
+
+    public function testExpressionThatNeverFiresIsRejected(): void
+    {
+        $this->expectException(TaskErrorException::class);
+        $this->expectExceptionMessage('never fires');
+
+        foreach (Cron::schedule(expression: '0 0 0 30 2 *') as $fire) {
+            //
+        }
+    }
+}
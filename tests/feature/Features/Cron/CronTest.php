@@ -0,0 +1,77 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Cron;
+
+use SConcur\Features\Cron\Cron;
+use SConcur\Tests\Feature\BaseAsyncTestCase;
+use Throwable;
+
+class CronTest extends BaseAsyncTestCase
+{
+    private float $startTime = 0;
+    private float $endTime   = 0;
+
+    protected function on_1_start(): void
+    {
+        $this->startTime = microtime(true);
+
+        $this->waitFire();
+    }
+
+    protected function on_1_middle(): void
+    {
+        $this->waitFire();
+    }
+
+    protected function on_2_start(): void
+    {
+        $this->waitFire();
+    }
+
+    protected function on_2_middle(): void
+    {
+        $this->waitFire();
+    }
+
+    protected function on_iterate(): void
+    {
+        $this->endTime = microtime(true);
+    }
+
+    protected function on_exception(): void
+    {
+        foreach (Cron::schedule(expression: '* * *') as $fire) {
+            //
+        }
+    }
+
+    protected function assertException(Throwable $exception): void
+    {
+        self::assertTrue(str_contains($exception->getMessage(), 'cron:'));
+    }
+
+    protected function assertResult(array $results): void
+    {
+        // Every second fires: each task waits for two consecutive second
+        // boundaries, so concurrent execution ends within 2s, while sequential
+        // execution would need four boundaries (> 3s).
+        $totalTimeMs = ($this->endTime - $this->startTime) * 1000;
+
+        self::assertTrue(
+            $totalTimeMs < 3000,
+            "Total time is not less than 3000ms but $totalTimeMs",
+        );
+    }
+
+    private function waitFire(): void
+    {
+        foreach (Cron::schedule(expression: '* * * * * *', timezone: 'UTC') as $number => $fire) {
+            self::assertSame(1, $number);
+            self::assertSame(0, $fire->scheduledAtMs % 1000);
+
+            break;
+        }
+    }
+}
@@ -0,0 +1,76 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\HttpClient;
+
+use Psr\Http\Client\RequestExceptionInterface;
+use SConcur\Features\HttpClient\CookieJar;
+
+/**
+ * Cookie sessions: a client bound to a CookieJar keeps the Set-Cookie of one
+ * response for the next requests, and a jar moves to another one through an
+ * export()/import() snapshot.
+ */
+class CookieJarTest extends BaseHttpClientTestCase
+{
+    public function testJarKeepsCookiesBetweenRequests(): void
+    {
+        $jar    = CookieJar::open();
+        $client = $this->client()->withCookieJar($jar);
+
+        try {
+            $client->sendRequest($this->request(method: 'GET', path: '/cookies'));
+
+            $response = $client->sendRequest($this->request(method: 'GET', path: '/echo-cookie'));
+
+            self::assertSame('a=1; b=2', (string) $response->getBody());
+
+            // A client without the jar sends nothing.
+            $response = $this->client()->sendRequest($this->request(method: 'GET', path: '/echo-cookie'));
+
+            self::assertSame('', (string) $response->getBody());
+        } finally {
+            $jar->close();
+        }
+    }
+
+    public function testSnapshotRestoresSession(): void
+    {
+        $jar = CookieJar::open();
+
+        $this->client()->withCookieJar($jar)->sendRequest($this->request(method: 'GET', path: '/cookies'));
+
+        $snapshot = $jar->export();
+
+        $jar->close();
+
+        $restored = CookieJar::open('restored-session');
+
+        try {
+            $restored->import($snapshot);
+
+            $response = $this->client()->withCookieJar($restored)->sendRequest(
+                $this->request(
+                    method: 'GET',
+                    path: '/echo-cookie',
+                ),
+            );
+
+            self::assertSame('a=1; b=2', (string) $response->getBody());
+            self::assertSame($snapshot, $restored->export());
+        } finally {
+            $restored->close();
+        }
+    }
+
+    public function testClosedJarIsRejected(): void
+    {
+        $jar = CookieJar::open();
+        $jar->close();
+
+        $this->expectException(RequestExceptionInterface::class);
+
+        $this->client()->withCookieJar($jar)->sendRequest($this->request(method: 'GET', path: '/'));
+    }
+}
@@ -5,6 +5,7 @@ declare(strict_types=1);
 namespace SConcur\Tests\Feature\Features\HttpClient;
 
 use Nyholm\Psr7\Factory\Psr17Factory;
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Features\HttpClient\HttpClient;
 use SConcur\Features\HttpClient\HttpClientOptions;
 use SConcur\Tests\Feature\BaseAsyncTestCase;
@@ -98,12 +99,8 @@ class HttpClientConcurrencyTest extends BaseAsyncTestCase
 
     protected function assertException(Throwable $exception): void
     {
-        // The Go side tags network failures with a "net:" marker, preserved through
-        // the wrapping exceptions.
-        self::assertTrue(
-            str_contains($exception->getMessage(), 'net:'),
-            'Expected a network-marked message, got: ' . $exception->getMessage(),
-        );
+        // The network-class task error is preserved through the wrapping exceptions.
+        self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_NETWORK, $exception);
     }
 
     protected function assertResult(array $results): void
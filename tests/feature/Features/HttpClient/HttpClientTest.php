@@ -9,7 +9,15 @@ use Psr\Http\Client\NetworkExceptionInterface;
 use Psr\Http\Client\RequestExceptionInterface;
 use ReflectionMethod;
 use RuntimeException;
+use SConcur\Dto\TaskErrorDto;
+use SConcur\Exceptions\HttpClient\ProxyException;
+use SConcur\Exceptions\TaskErrorException;
+use SConcur\Features\HttpClient\HttpClient;
 use SConcur\Features\HttpClient\HttpClientOptions;
+use SConcur\Features\HttpClient\HttpVersion;
+use SConcur\Features\HttpClient\ProxyOptions;
+use SConcur\Features\HttpClient\RetryPolicy;
+use SConcur\Features\HttpClient\TlsOptions;
 use SConcur\WaitGroup;
 
 /**
@@ -35,6 +43,28 @@ class HttpClientTest extends BaseHttpClientTestCase
         self::assertSame(2, $response->getBody()->getSize());
     }
 
+    public function testResponseReportsNegotiatedProtocol(): void
+    {
+        $response = $this->client()->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/',
+            ),
+        );
+
+        self::assertSame('1.1', $response->getProtocolVersion());
+
+        // Without TLS there is no ALPN: auto stays on HTTP/1.1 (h2c must be asked for).
+        $response = $this->client(new HttpClientOptions(httpVersion: HttpVersion::Auto))->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/',
+            ),
+        );
+
+        self::assertSame('1.1', $response->getProtocolVersion());
+    }
+
     public function testRequestMethodReachesServer(): void
     {
         $response = $this->client()->sendRequest(
@@ -115,6 +145,60 @@ class HttpClientTest extends BaseHttpClientTestCase
         }
     }
 
+    public function testRetryPolicyResendsRetryableStatuses(): void
+    {
+        $client = $this->client(
+            new HttpClientOptions(
+                retry: new RetryPolicy(
+                    maxAttempts: 3,
+                    backoffMs: 1,
+                ),
+            ),
+        );
+
+        $response = $client->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/status/503',
+            ),
+        );
+
+        // Every attempt got 503: the last answer is returned after all of them.
+        self::assertSame(503, $response->getStatusCode());
+        self::assertSame(['3'], $response->getHeader(HttpClient::ATTEMPTS_HEADER));
+
+        // A status outside retryStatuses is not retried.
+        $response = $client->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/status/500',
+            ),
+        );
+
+        self::assertSame(['1'], $response->getHeader(HttpClient::ATTEMPTS_HEADER));
+
+        // Neither is a non-idempotent method by default.
+        $response = $client->sendRequest(
+            $this->request(
+                method: 'POST',
+                path: '/status/503',
+                body: 'x',
+            ),
+        );
+
+        self::assertSame(['1'], $response->getHeader(HttpClient::ATTEMPTS_HEADER));
+
+        // Without a policy the header is not added.
+        $response = $this->client()->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/status/503',
+            ),
+        );
+
+        self::assertFalse($response->hasHeader(HttpClient::ATTEMPTS_HEADER));
+    }
+
     public function testLargeBodyIsStreamedInChunks(): void
     {
         $size = 200_000; // > 64 KiB transport chunk, so the body really streams.
@@ -202,6 +286,54 @@ class HttpClientTest extends BaseHttpClientTestCase
         }
     }
 
+    public function testUnreachableProxyThrowsProxyException(): void
+    {
+        $client = $this->client(
+            options: new HttpClientOptions(
+                requestTimeoutMs: 2_000,
+                connectTimeoutMs: 1_000,
+                proxy: new ProxyOptions(
+                    url: 'http://127.0.0.1:1',
+                ),
+            ),
+        );
+        $request = $this->request(
+            method: 'GET',
+            path: '/',
+        );
+
+        try {
+            $client->sendRequest($request);
+
+            self::fail('Expected a ProxyException.');
+        } catch (ProxyException $exception) {
+            // Still a PSR-18 network error, for callers that do not care which hop failed.
+            self::assertInstanceOf(NetworkExceptionInterface::class, $exception);
+            self::assertSame($request, $exception->getRequest());
+        }
+    }
+
+    public function testNoProxyHostsBypassTheProxy(): void
+    {
+        $client = $this->client(
+            options: new HttpClientOptions(
+                proxy: new ProxyOptions(
+                    url: 'http://127.0.0.1:1',
+                    noProxy: ['127.0.0.1', 'localhost'],
+                ),
+            ),
+        );
+
+        $response = $client->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/',
+            ),
+        );
+
+        self::assertSame(200, $response->getStatusCode());
+    }
+
     public function testRequestTimeoutThrowsNetworkException(): void
     {
         $client = $this->client(
@@ -540,12 +672,12 @@ class HttpClientTest extends BaseHttpClientTestCase
         self::assertTrue($slept);
     }
 
-    public function testRequestErrorMarkerMapsToRequestException(): void
+    public function testValidationErrorMapsToRequestException(): void
     {
-        // A "req:"-marked extension error (a request that could not be built/sent)
+        // A validation-class task error (a request that could not be built/sent)
         // must surface as a PSR-18 RequestException carrying the original request.
-        // The Go side produces this marker for invalid method/URL; here the mapping
-        // branch is exercised directly (the network branch is covered end-to-end).
+        // The Go side reports invalid method/URL this way; here the mapping branch
+        // is exercised directly (the network branch is covered end-to-end).
         $client  = $this->client();
         $request = $this->request(
             method: 'GET',
@@ -556,7 +688,17 @@ class HttpClientTest extends BaseHttpClientTestCase
 
         $exception = $toClientException->invoke(
             $client,
-            new RuntimeException('req: invalid request'),
+            new TaskErrorException(
+                message: 'httpClient: build request: invalid method',
+                error: new TaskErrorDto(
+                    code: 'invalid',
+                    category: TaskErrorDto::CATEGORY_VALIDATION,
+                    retryable: false,
+                    driverCode: '',
+                    labels: [],
+                    message: 'httpClient: build request: invalid method',
+                ),
+            ),
             $request,
         );
 
@@ -564,6 +706,27 @@ class HttpClientTest extends BaseHttpClientTestCase
         self::assertSame($request, $exception->getRequest());
     }
 
+    public function testUnreadableTlsFilesAreRequestErrors(): void
+    {
+        $client = $this->client(
+            new HttpClientOptions(
+                tls: new TlsOptions(
+                    caFiles: [sys_get_temp_dir() . '/sconcur-missing-ca.pem'],
+                ),
+            ),
+        );
+
+        // The transport is never built, so the request fails before connecting.
+        $this->expectException(RequestExceptionInterface::class);
+
+        $client->sendRequest(
+            $this->request(
+                method: 'GET',
+                path: '/',
+            ),
+        );
+    }
+
     public function testResponseHeadersAreCaseInsensitive(): void
     {
         $response = $this->client()->sendRequest(
@@ -123,6 +123,46 @@ class MysqlQueryTest extends BaseTestCase
         // tearDown's assertNoTasksCount verifies the abandoned cursor was released.
     }
 
+    public function testPrefetchKeepsRowOrderAndReleasesOnBreak(): void
+    {
+        for ($index = 1; $index <= 10; ++$index) {
+            $this->connection->exec(
+                sql: "INSERT INTO {$this->table} (name, amount) VALUES (?, ?)",
+                bindings: ["name-$index", $index],
+            );
+        }
+
+        $amounts = [];
+
+        $rows = $this->connection->query(
+            sql: "SELECT amount FROM {$this->table} ORDER BY id",
+            batchSize: 3,
+            prefetchDepth: 2,
+        );
+
+        foreach ($rows as $row) {
+            $amounts[] = (int) $row['amount'];
+        }
+
+        self::assertSame(range(1, 10), $amounts);
+
+        $seen = 0;
+
+        $rows = $this->connection->query(
+            sql: "SELECT id FROM {$this->table} ORDER BY id",
+            batchSize: 2,
+            prefetchDepth: 3,
+        );
+
+        foreach ($rows as $row) {
+            if (++$seen === 3) {
+                break;
+            }
+        }
+
+        self::assertSame(3, $seen);
+    }
+
     protected function seed(): void
     {
         $this->connection->exec(
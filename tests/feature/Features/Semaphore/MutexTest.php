@@ -0,0 +1,78 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Semaphore;
+
+use SConcur\Features\Semaphore\Mutex;
+use SConcur\Features\Semaphore\Semaphore;
+use SConcur\Features\Sleeper\Sleeper;
+use SConcur\Tests\Feature\BaseAsyncTestCase;
+use Throwable;
+
+class MutexTest extends BaseAsyncTestCase
+{
+    private const string NAME = 'mutex-test';
+
+    /** @var string[] */
+    private array $events = [];
+
+    protected function on_1_start(): void
+    {
+        $this->critical('1');
+    }
+
+    protected function on_1_middle(): void
+    {
+        $this->critical('1');
+    }
+
+    protected function on_2_start(): void
+    {
+        $this->critical('2');
+    }
+
+    protected function on_2_middle(): void
+    {
+        $this->critical('2');
+    }
+
+    protected function on_iterate(): void
+    {
+        //
+    }
+
+    protected function on_exception(): void
+    {
+        Semaphore::create('', permits: 1)->acquire();
+    }
+
+    protected function assertException(Throwable $exception): void
+    {
+        self::assertTrue(str_contains($exception->getMessage(), 'semaphore:'));
+    }
+
+    protected function assertResult(array $results): void
+    {
+        self::assertCount(8, $this->events);
+
+        // Every critical section is entered and left before the next one begins.
+        foreach (array_chunk($this->events, 2) as [$enter, $leave]) {
+            self::assertSame('enter', explode(':', $enter)[1]);
+            self::assertSame(explode(':', $enter)[0] . ':leave', $leave);
+        }
+
+        $this->events = [];
+    }
+
+    private function critical(string $flow): void
+    {
+        Mutex::create(self::NAME)->synchronized(function () use ($flow) {
+            $this->events[] = "$flow:enter";
+
+            Sleeper::usleep(10_000);
+
+            $this->events[] = "$flow:leave";
+        });
+    }
+}
@@ -0,0 +1,101 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Semaphore;
+
+use RuntimeException;
+use SConcur\Exceptions\TaskErrorException;
+use SConcur\Features\Semaphore\Mutex;
+use SConcur\Features\Semaphore\Semaphore;
+use SConcur\Features\Sleeper\Sleeper;
+use SConcur\Tests\Feature\BaseTestCase;
+use SConcur\WaitGroup;
+
+class SemaphoreTest extends BaseTestCase
+{
+    public function testPermitsBoundConcurrentHolders(): void
+    {
+        $semaphore = Semaphore::create('semaphore-test-bound', permits: 2);
+
+        $active    = 0;
+        $maxActive = 0;
+
+        $waitGroup = WaitGroup::create();
+
+        for ($index = 0; $index < 6; $index++) {
+            $waitGroup->add(function () use ($semaphore, &$active, &$maxActive) {
+                $semaphore->withPermit(function () use (&$active, &$maxActive) {
+                    $maxActive = max($maxActive, ++$active);
+
+                    Sleeper::usleep(10_000);
+
+                    --$active;
+                });
+            });
+        }
+
+        $waitGroup->waitAll();
+
+        self::assertSame(2, $maxActive);
+    }
+
+    public function testPermitIsReleasedWhenTheCallbackThrows(): void
+    {
+        $mutex = Mutex::create('semaphore-test-throw');
+
+        try {
+            $mutex->synchronized(static function () {
+                throw new RuntimeException('boom');
+            });
+        } catch (RuntimeException) {
+            //
+        }
+
+        self::assertSame('free', $mutex->synchronized(static fn() => 'free'));
+    }
+
+    public function testStoppedWaitGroupReturnsItsPermits(): void
+    {
+        $mutex = Mutex::create('semaphore-test-stop');
+
+        $holding = WaitGroup::create();
+
+        $holding->add(function () use ($mutex) {
+            $mutex->lock();
+
+            // Keeps the lock without releasing it until the group is stopped.
+            Sleeper::sleep(10);
+        });
+
+        $holding->add(static function () {
+            Sleeper::usleep(10_000);
+        });
+
+        // The short member finishes first, while the other one holds the lock.
+        foreach ($holding->iterate() as $ignored) {
+            break;
+        }
+
+        $holding->stop();
+
+        $permit = $mutex->lock();
+
+        self::assertFalse($permit->isReleased());
+
+        $permit->release();
+    }
+
+    public function testDifferentPermitCountIsRejected(): void
+    {
+        $permit = Semaphore::create('semaphore-test-mismatch', permits: 3)->acquire();
+
+        try {
+            $this->expectException(TaskErrorException::class);
+
+            Semaphore::create('semaphore-test-mismatch', permits: 1)->acquire();
+        } finally {
+            $permit->release();
+        }
+    }
+}
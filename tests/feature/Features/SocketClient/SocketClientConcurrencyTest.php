@@ -4,6 +4,7 @@ declare(strict_types=1);
 
 namespace SConcur\Tests\Feature\Features\SocketClient;
 
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Features\SocketClient\Dto\Connection;
 use SConcur\Features\SocketClient\SocketClient;
 use SConcur\Features\SocketClient\SocketClientOptions;
@@ -102,13 +103,9 @@ class SocketClientConcurrencyTest extends BaseAsyncTestCase
 
     protected function assertException(Throwable $exception): void
     {
-        // The Go side tags network failures with a "net:" marker, preserved through the
-        // wrapping exceptions (SocketClientConnectException on the sync path, wrapped
+        // The network-class task error is preserved through the wrapping exceptions (SocketClientConnectException on the sync path, wrapped
         // again in CallbackExecutionException on the async path).
-        self::assertStringContainsString(
-            'net:',
-            $exception->getMessage(),
-        );
+        self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_NETWORK, $exception);
     }
 
     protected function assertResult(array $results): void
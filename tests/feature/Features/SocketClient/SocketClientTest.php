@@ -4,6 +4,7 @@ declare(strict_types=1);
 
 namespace SConcur\Tests\Feature\Features\SocketClient;
 
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Exceptions\SocketClient\SocketClientConnectException;
 use SConcur\Exceptions\SocketClient\SocketClientConnectionClosedException;
 use SConcur\Features\SocketClient\Dto\Connection;
@@ -223,16 +224,15 @@ class SocketClientTest extends BaseSocketClientTestCase
         )->connect('127.0.0.1:1');
     }
 
-    public function testConnectRefusedCarriesNetworkMarker(): void
+    public function testConnectRefusedCarriesNetworkError(): void
     {
         try {
             $this->client()->connect('127.0.0.1:1');
 
             self::fail('Expected a connect exception.');
         } catch (SocketClientConnectException $exception) {
-            // The Go side tags network failures with a "net:" marker, preserved through
-            // the wrapping exception.
-            self::assertStringContainsString('net:', $exception->getMessage());
+            // The network-class task error is preserved through the wrapping exception.
+            self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_NETWORK, $exception);
         }
     }
 
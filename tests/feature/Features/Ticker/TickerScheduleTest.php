@@ -0,0 +1,66 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Ticker;
+
+use SConcur\Features\Ticker\Ticker;
+use SConcur\Tests\Feature\BaseTestCase;
+
+class TickerScheduleTest extends BaseTestCase
+{
+    public function testLateConsumerGetsMissedTicksReported(): void
+    {
+        $ticks = [];
+
+        foreach (Ticker::every(periodMs: 10, maxTicks: 8) as $tick) {
+            $ticks[] = $tick;
+
+            if ($tick->number === 1) {
+                // Blocking the whole thread: the ticker keeps its schedule meanwhile.
+                usleep(45_000);
+            }
+        }
+
+        self::assertSame(1, $ticks[0]->number);
+        self::assertGreaterThanOrEqual(2, $ticks[1]->missed);
+        self::assertSame($ticks[1]->number, 2 + $ticks[1]->missed);
+        self::assertSame(8, $ticks[count($ticks) - 1]->number);
+    }
+
+    public function testInitialDelayDefersTheFirstTick(): void
+    {
+        $startedAt = microtime(true);
+
+        foreach (Ticker::every(periodMs: 5, initialDelayMs: 30, maxTicks: 1) as $tick) {
+            self::assertSame(1, $tick->number);
+        }
+
+        self::assertGreaterThanOrEqual(25, (microtime(true) - $startedAt) * 1000);
+    }
+
+    public function testZeroInitialDelayTicksAtOnce(): void
+    {
+        $startedAt = microtime(true);
+
+        foreach (Ticker::every(periodMs: 10_000, initialDelayMs: 0, maxTicks: 1) as $tick) {
+            self::assertSame(1, $tick->number);
+        }
+
+        self::assertLessThan(1_000, (microtime(true) - $startedAt) * 1000);
+    }
+
+    public function testEarlyBreakStopsTheTicker(): void
+    {
+        $seen = 0;
+
+        foreach (Ticker::every(periodMs: 5) as $tick) {
+            if (++$seen === 3) {
+                break;
+            }
+        }
+
+        self::assertSame(3, $seen);
+        // tearDown's assertNoTasksCount verifies the abandoned ticker was released.
+    }
+}
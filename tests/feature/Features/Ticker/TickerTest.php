@@ -0,0 +1,82 @@
+<?php
+
+declare(strict_types=1);
+
+namespace SConcur\Tests\Feature\Features\Ticker;
+
+use SConcur\Features\Ticker\Ticker;
+use SConcur\Tests\Feature\BaseAsyncTestCase;
+use Throwable;
+
+class TickerTest extends BaseAsyncTestCase
+{
+    private float $startTime = 0;
+    private float $endTime   = 0;
+
+    protected function on_1_start(): void
+    {
+        $this->startTime = microtime(true);
+
+        $this->tick(2);
+    }
+
+    protected function on_1_middle(): void
+    {
+        $this->tick(2);
+    }
+
+    protected function on_2_start(): void
+    {
+        $this->tick(2);
+    }
+
+    protected function on_2_middle(): void
+    {
+        $this->tick(2);
+    }
+
+    protected function on_iterate(): void
+    {
+        $this->endTime = microtime(true);
+    }
+
+    protected function on_exception(): void
+    {
+        foreach (Ticker::every(periodMs: 0) as $tick) {
+            //
+        }
+    }
+
+    protected function assertException(Throwable $exception): void
+    {
+        self::assertTrue(str_contains($exception->getMessage(), 'ticker:'));
+    }
+
+    protected function assertResult(array $results): void
+    {
+        // Each task waits 2 ticks of 10ms twice, so concurrent execution takes
+        // >= 40ms, while sequential execution would take >= 80ms.
+        $totalTimeMs = ($this->endTime - $this->startTime) * 1000;
+
+        self::assertTrue(
+            $totalTimeMs >= 40,
+            "Total time is less than 40ms but $totalTimeMs",
+        );
+
+        self::assertTrue(
+            $totalTimeMs < 80,
+            "Total time is not less than 80ms but $totalTimeMs",
+        );
+    }
+
+    private function tick(int $count): void
+    {
+        $numbers = [];
+
+        foreach (Ticker::every(periodMs: 10, maxTicks: $count) as $number => $tick) {
+            $numbers[] = $number;
+        }
+
+        self::assertSame(range(1, $count), $numbers);
+    }
+}
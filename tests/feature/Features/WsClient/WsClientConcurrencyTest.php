@@ -4,6 +4,7 @@ declare(strict_types=1);
 
 namespace SConcur\Tests\Feature\Features\WsClient;
 
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Features\WsClient\Dto\Connection;
 use SConcur\Features\WsClient\WsClient;
 use SConcur\Features\WsClient\WsClientOptions;
@@ -102,13 +103,9 @@ class WsClientConcurrencyTest extends BaseAsyncTestCase
 
     protected function assertException(Throwable $exception): void
     {
-        // The Go side tags network failures with a "net:" marker, preserved through the
-        // wrapping exceptions (WsClientConnectException on the sync path, wrapped again in
-        // CallbackExecutionException on the async path).
-        self::assertStringContainsString(
-            'net:',
-            $exception->getMessage(),
-        );
+        // The network-class task error is preserved through the wrapping exceptions (WsClientConnectException on the sync path, wrapped
+        // again in CallbackExecutionException on the async path).
+        self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_NETWORK, $exception);
     }
 
     protected function assertResult(array $results): void
@@ -4,6 +4,7 @@ declare(strict_types=1);
 
 namespace SConcur\Tests\Feature\Features\WsClient;
 
+use SConcur\Dto\TaskErrorDto;
 use SConcur\Exceptions\WsClient\WsClientConnectException;
 use SConcur\Exceptions\WsClient\WsClientConnectionClosedException;
 use SConcur\Features\WsClient\Dto\Connection;
@@ -245,16 +246,15 @@ class WsClientTest extends BaseWsClientTestCase
         )->connect('ws://127.0.0.1:1/');
     }
 
-    public function testConnectRefusedCarriesNetworkMarker(): void
+    public function testConnectRefusedCarriesNetworkError(): void
     {
         try {
             $this->client()->connect('ws://127.0.0.1:1/');
 
             self::fail('Expected a connect exception.');
         } catch (WsClientConnectException $exception) {
-            // The Go side tags network failures with a "net:" marker, preserved through
-            // the wrapping exception.
-            self::assertStringContainsString('net:', $exception->getMessage());
+            // The network-class task error is preserved through the wrapping exception.
+            self::assertTaskErrorCategory(TaskErrorDto::CATEGORY_NETWORK, $exception);
         }
     }
 
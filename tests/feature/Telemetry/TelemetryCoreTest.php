@@ -86,6 +86,46 @@ class TelemetryCoreTest extends TestCase
         self::assertNotNull($aggregate->workers[1]->connections);
     }
 
+    public function testAggregatorSumsPanicCounters(): void
+    {
+        $now = 1_750_000_000_000;
+
+        $panicking = Snapshot::fromDecoded([
+            'name'    => 'srv',
+            'pid'     => 21,
+            'crashes' => ['total' => 3, 'task' => 2, 'server' => 1, 'lastAtMs' => $now - 1_000],
+        ]);
+
+        self::assertNotNull($panicking);
+
+        // A worker that predates the crash counters reports zeros.
+        $older = $this->requestsSnapshot(pid: 22, updatedAtMs: $now, completed: 1, avgMs: 1.0);
+
+        self::assertSame(0, $older->crashes->total);
+
+        $aggregate = $this->aggregateOf(
+            [$this->stored($panicking, $now), $this->stored($older, $now)],
+            'srv',
+            $now,
+        );
+
+        self::assertSame(3, $aggregate->totals->crashes->total);
+        self::assertSame(1, $aggregate->totals->crashes->server);
+        self::assertSame($now - 1_000, $aggregate->totals->crashes->lastAtMs);
+
+        /** @var array<string, mixed> $json */
+        $json = json_decode((new JsonRenderer())->render($aggregate), true);
+
+        self::assertSame(2, $json['workers'][0]['crashes']['task']);
+
+        $metrics = (new PrometheusRenderer())->render($aggregate);
+
+        self::assertStringContainsString('sconcur_pool_panics_total{name="srv"} 3', $metrics);
+        self::assertStringContainsString('sconcur_worker_panics_total{name="srv",pid="21"} 3', $metrics);
+        self::assertStringContainsString('sconcur_worker_server_panics_total{name="srv",pid="21"} 1', $metrics);
+        self::assertStringContainsString('sconcur_worker_panics_total{name="srv",pid="22"} 0', $metrics);
+    }
+
     public function testAggregatorFlagsHungBySnapshotAge(): void
     {
         $now = 1_750_000_000_000;
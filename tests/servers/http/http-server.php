@@ -32,6 +32,7 @@ use SConcur\WaitGroup;
  *   GET  /image?name=        -> serves an image from tests/storage/images inline (default sample.png)
  *   *    /query             -> 200, body = the raw query string
  *   *    /echo-header       -> 200, body = the "X-Echo" request header (joined)
+ *   *    /echo-cookie       -> 200, body = the Cookie request header (cookie-jar tests)
  *   *    /meta              -> 200, body = "<proto> <host>" (connection metadata)
  *   GET  /empty             -> 200 with an empty body
  *   GET  /cookies           -> 200 with two Set-Cookie headers (multi-value demo)
@@ -113,6 +114,10 @@ $server->serve(static function (ServerRequestInterface $request) use ($psr17Fact
         return text($psr17Factory, implode(',', $request->getHeader('X-Echo')));
     }
 
+    if ($path === '/echo-cookie') {
+        return text($psr17Factory, $request->getHeaderLine('Cookie'));
+    }
+
     if ($path === '/meta') {
         return text($psr17Factory, 'HTTP/' . $request->getProtocolVersion() . ' ' . $request->getHeaderLine('Host'));
     }